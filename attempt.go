@@ -0,0 +1,54 @@
+package retryablehttp
+
+import (
+	"context"
+	"net/http"
+)
+
+// attemptKey is the context key of attempt numbers.
+type attemptKey struct{}
+
+// WithAttemptContext configures client to send every attempt with its own child context of request's context, carrying attempt's number,
+// so that transports, tracers and hooks reading request's context can tell attempts apart, see AttemptFromContext. The context of an attempt is
+// cancelled when its response body is closed, or before the next attempt starts when it fails, so that a hung attempt's resources are released.
+// Attempts share request's context by default, since deriving contexts allocates.
+func WithAttemptContext() Option {
+	return named("WithAttemptContext", func(c *Client) error {
+		c.attemptContexts = true
+
+		return nil
+	})
+}
+
+// AttemptFromContext returns the attempt number, starting from one, carried by the context of an attempt sent by a client configured with WithAttemptContext.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	attempt, ok := ctx.Value(attemptKey{}).(int)
+
+	return attempt, ok
+}
+
+// attemptContext returns a copy of provided request with a child context of call's current attempt when client derives attempt contexts,
+// the context times out after call's share of the remaining deadline when deadline is split. The function cancelling the context is recorded in call state.
+func (c *Client) attemptContext(cl *call, req *http.Request) *http.Request {
+	attemptReq, cancel := c.splitDeadline(cl, req)
+	cl.cancel = cancel
+	if !c.attemptContexts {
+		return attemptReq
+	}
+
+	ctx := attemptReq.Context()
+	if cancel == nil {
+		ctx, cl.cancel = context.WithCancel(ctx)
+	}
+
+	return attemptReq.WithContext(context.WithValue(ctx, attemptKey{}, cl.attempt))
+}
+
+// endAttempt releases resources of call's current attempt, discarding its response and cancelling its context when it has one.
+func (cl *call) endAttempt() {
+	discard(cl.res)
+	if cl.cancel != nil {
+		cl.cancel()
+		cl.cancel = nil
+	}
+}
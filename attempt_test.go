@@ -0,0 +1,69 @@
+package retryablehttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Do method of a client with attempt contexts should send every attempt with its own context, cancelling failed attempts' contexts
+// before the next attempt starts and the last attempt's context when its response body is closed.
+func TestAttemptContext(t *testing.T) {
+	var contexts []context.Context
+	var attempts []int
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithAttemptContext(),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			for _, previous := range contexts {
+				if previous.Err() == nil {
+					t.Errorf("context of a failed attempt is not cancelled")
+				}
+			}
+			contexts = append(contexts, r.Context())
+			attempt, _ := AttemptFromContext(r.Context())
+			attempts = append(attempts, attempt)
+
+			status := http.StatusServiceUnavailable
+			if len(contexts) == 3 {
+				status = http.StatusOK
+			}
+
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("body"))}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("sending request failed, %s", err.Error())
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+		t.Errorf("unexpected attempts, %v", attempts)
+	}
+	if contexts[2].Err() != nil {
+		t.Errorf("context of the last attempt is cancelled before its body is closed")
+	}
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "body" {
+		t.Errorf("unexpected body, %s", body)
+	}
+	res.Body.Close()
+	if contexts[2].Err() == nil {
+		t.Errorf("context of the last attempt is not cancelled after its body is closed")
+	}
+
+	if _, ok := AttemptFromContext(req.Context()); ok {
+		t.Errorf("request context carries an attempt number")
+	}
+}
@@ -0,0 +1,101 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// audit errors
+var (
+	ErrNilAuditSink = errors.New("audit sink is nil")
+)
+
+// Outcome represents outcome of an attempt.
+type Outcome string
+
+// outcomes
+const (
+	OutcomeSuccess    Outcome = "success"
+	OutcomeRetry      Outcome = "retry"
+	OutcomeFailure    Outcome = "failure"
+	OutcomeRedirect   Outcome = "redirect"
+	OutcomeURLRefresh Outcome = "url_refresh"
+)
+
+// AuditRecord represents a structured record of a single attempt, url and error message are redacted by client's redaction policy.
+// StatusCode is zero when no response is received. BytesSent and BytesReceived are declared content lengths, -1 represents unknown length.
+// Labels are the labels of the request, see ContextWithLabels.
+type AuditRecord struct {
+	Time          time.Time
+	Method        string
+	URL           string
+	StatusCode    int
+	Latency       time.Duration
+	Attempt       int
+	Outcome       Outcome
+	Error         string
+	BytesSent     int64
+	BytesReceived int64
+	Labels        map[string]string
+}
+
+// AuditSink receives audit records of attempts, it must be safe for concurrent use.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+// AuditSinkFunc is an adapter to use ordinary functions as audit sinks.
+type AuditSinkFunc func(record AuditRecord)
+
+// Audit calls f(record).
+func (f AuditSinkFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// WithAuditSink configures client's audit sink which receives a record of every attempt.
+// Audit sink is not configured by default.
+func WithAuditSink(sink AuditSink) Option {
+	return named("WithAuditSink", func(c *Client) error {
+		if sink == nil {
+			return ErrNilAuditSink
+		}
+
+		c.auditSink = sink
+
+		return nil
+	})
+}
+
+// audit sends audit record of call's current attempt to client's audit sink, attempts which did not send a request are not audited.
+func (c *Client) audit(cl *call, err error, outcome Outcome) {
+	if c.auditSink == nil || cl.req == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:          cl.start,
+		Method:        cl.req.Method,
+		URL:           c.redactor.url(cl.req.URL).String(),
+		Latency:       cl.latency,
+		Attempt:       cl.attempt,
+		Outcome:       outcome,
+		BytesSent:     cl.req.ContentLength,
+		BytesReceived: -1,
+		Labels:        cl.labels,
+	}
+	if cl.req.Body == nil || cl.req.Body == http.NoBody {
+		record.BytesSent = 0
+	}
+	if cl.res != nil {
+		record.StatusCode = cl.res.StatusCode
+		record.BytesReceived = cl.res.ContentLength
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	c.hook(func() {
+		c.auditSink.Audit(record)
+	})
+}
@@ -0,0 +1,70 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// NewClient function should return ErrNilAuditSink when nil audit sink is provided.
+func TestNilAuditSinkOption(t *testing.T) {
+	_, err := NewClient(
+		WithAuditSink(nil),
+	)
+	if !errors.Is(err, ErrNilAuditSink) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with audit sink should send a redacted record of every attempt.
+func TestAuditSink(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	var records []AuditRecord
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithAuditSink(AuditSinkFunc(func(record AuditRecord) {
+			records = append(records, record)
+		})),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"?api_key=secret", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("unexpected record count, %d", len(records))
+	}
+
+	first, second := records[0], records[1]
+	if first.Attempt != 1 || first.Outcome != OutcomeRetry || first.StatusCode != http.StatusServiceUnavailable || first.Error == "" {
+		t.Errorf("unexpected first record, %+v", first)
+	}
+	if second.Attempt != 2 || second.Outcome != OutcomeSuccess || second.StatusCode != http.StatusOK || second.BytesReceived != 2 {
+		t.Errorf("unexpected second record, %+v", second)
+	}
+	if strings.Contains(second.URL, "secret") || second.Method != http.MethodGet || second.Time.IsZero() {
+		t.Errorf("unexpected second record, %+v", second)
+	}
+}
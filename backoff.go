@@ -0,0 +1,108 @@
+package retryablehttp
+
+import (
+	"errors"
+	"time"
+)
+
+// backoff errors
+var (
+	ErrNilBackoffPolicy  = errors.New("backoff policy is nil")
+	ErrInvalidMaxBackoff = errors.New("maximum backoff must be greater than zero")
+)
+
+// BackoffPolicy represents a policy which returns backoff duration to wait after provided failed attempt, attempts start from 1.
+// A negative duration stops retrying. Composable policies are provided by github.com/ermanimer/retryablehttp/backoff package.
+type BackoffPolicy interface {
+	Backoff(attempt int) time.Duration
+}
+
+// ElapsedBackoffPolicy represents a backoff policy which is also given the total backoff a request waited before provided failed attempt,
+// client calls BackoffElapsed instead of Backoff for such policies. Policies of github.com/ermanimer/retryablehttp/backoff package implement it.
+type ElapsedBackoffPolicy interface {
+	BackoffPolicy
+	BackoffElapsed(attempt int, elapsed time.Duration) time.Duration
+}
+
+// backoffAfter returns backoff duration of provided policy after provided attempt of a request which waited provided total backoff.
+func backoffAfter(policy BackoffPolicy, attempt int, elapsed time.Duration) time.Duration {
+	if p, ok := policy.(ElapsedBackoffPolicy); ok {
+		return p.BackoffElapsed(attempt, elapsed)
+	}
+
+	return policy.Backoff(attempt)
+}
+
+// ConstantBackoff is a backoff policy which waits the same duration after every attempt.
+type ConstantBackoff time.Duration
+
+// Backoff returns constant backoff duration.
+func (b ConstantBackoff) Backoff(attempt int) time.Duration {
+	return time.Duration(b)
+}
+
+// WithBackoffPolicy configures client's backoff policy, jitter is applied on durations returned by the policy.
+// Default backoff policy is a constant backoff configured by WithBackoff.
+func WithBackoffPolicy(policy BackoffPolicy) Option {
+	return named("WithBackoffPolicy", func(c *Client) error {
+		if policy == nil {
+			return ErrNilBackoffPolicy
+		}
+
+		c.backoff = policy
+
+		return nil
+	})
+}
+
+// WithMaxBackoff configures client to clamp every backoff duration, after jitter, to provided maximum.
+// Backoff durations are not clamped by default.
+func WithMaxBackoff(maxBackoff time.Duration) Option {
+	return named("WithMaxBackoff", func(c *Client) error {
+		if maxBackoff <= 0 {
+			return ErrInvalidMaxBackoff
+		}
+
+		c.maxBackoff = maxBackoff
+
+		return nil
+	})
+}
+
+// WithMaxTotalBackoff configures client to limit the sum of backoff durations of a request to provided maximum.
+// The last backoff duration is shortened to fit the limit and the client gives up when the limit is exhausted. Total backoff is not limited by default.
+func WithMaxTotalBackoff(maxTotalBackoff time.Duration) Option {
+	return named("WithMaxTotalBackoff", func(c *Client) error {
+		if maxTotalBackoff <= 0 {
+			return ErrInvalidMaxBackoff
+		}
+
+		c.maxTotalBackoff = maxTotalBackoff
+
+		return nil
+	})
+}
+
+// capBackoff clamps provided backoff duration to client's maximum backoff and to the rest of maximum total backoff after provided total,
+// it returns false when backoff policy stops retrying or maximum total backoff is exhausted.
+func (c *Client) capBackoff(backoff, total time.Duration) (time.Duration, bool) {
+	if backoff < 0 {
+		return 0, false
+	}
+
+	if c.maxBackoff > 0 && backoff > c.maxBackoff {
+		backoff = c.maxBackoff
+	}
+
+	if c.maxTotalBackoff > 0 && backoff > 0 {
+		rest := c.maxTotalBackoff - total
+		if rest <= 0 {
+			return 0, false
+		}
+		if backoff > rest {
+			backoff = rest
+		}
+	}
+
+	return backoff, true
+}
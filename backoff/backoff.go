@@ -0,0 +1,169 @@
+// Package backoff provides composable backoff strategies for retryablehttp clients.
+//
+// Every strategy satisfies retryablehttp.BackoffPolicy and can be configured with retryablehttp.WithBackoffPolicy:
+//
+//	policy := backoff.WithJitter(backoff.WithCap(backoff.Exponential(100*time.Millisecond, 2), 5*time.Second), 0.2, nil)
+//	c, err := retryablehttp.NewClient(retryablehttp.WithBackoffPolicy(policy))
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Stop is returned by strategies which give up retrying, such as strategies wrapped by WithMaxElapsed.
+const Stop time.Duration = -1
+
+// Backoff represents a backoff strategy which returns duration to wait after provided failed attempt, attempts start from 1.
+type Backoff interface {
+	Backoff(attempt int) time.Duration
+}
+
+// ElapsedBackoff represents a backoff strategy which is also given the total duration a request waited before provided failed attempt,
+// so that strategies such as WithMaxElapsed do not recompute durations of previous attempts. Clients of retryablehttp call BackoffElapsed of
+// such strategies, strategies of this package implement it and pass waited duration to strategies they wrap.
+type ElapsedBackoff interface {
+	Backoff
+	BackoffElapsed(attempt int, elapsed time.Duration) time.Duration
+}
+
+// Func is a backoff strategy implemented by a function.
+type Func func(attempt int) time.Duration
+
+// Backoff calls the function.
+func (f Func) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// elapsedFunc is a backoff strategy implemented by a function which is given the total duration waited before an attempt,
+// a negative elapsed duration means it is unknown.
+type elapsedFunc func(attempt int, elapsed time.Duration) time.Duration
+
+// Backoff calls the function with unknown elapsed duration.
+func (f elapsedFunc) Backoff(attempt int) time.Duration {
+	return f(attempt, -1)
+}
+
+// BackoffElapsed calls the function.
+func (f elapsedFunc) BackoffElapsed(attempt int, elapsed time.Duration) time.Duration {
+	return f(attempt, elapsed)
+}
+
+// backoffElapsed returns duration of provided strategy after provided attempt, passing provided elapsed duration when the strategy takes it.
+func backoffElapsed(b Backoff, attempt int, elapsed time.Duration) time.Duration {
+	if e, ok := b.(ElapsedBackoff); ok {
+		return e.BackoffElapsed(attempt, elapsed)
+	}
+
+	return b.Backoff(attempt)
+}
+
+// Constant returns a strategy which waits provided duration after every attempt.
+func Constant(d time.Duration) Backoff {
+	return Func(func(attempt int) time.Duration {
+		return d
+	})
+}
+
+// Linear returns a strategy which waits initial duration after first attempt and increases it by increment after each attempt.
+func Linear(initial, increment time.Duration) Backoff {
+	return Func(func(attempt int) time.Duration {
+		return saturate(float64(initial) + float64(increment)*float64(attempt-1))
+	})
+}
+
+// Exponential returns a strategy which waits initial duration after first attempt and multiplies it by multiplier after each attempt.
+func Exponential(initial time.Duration, multiplier float64) Backoff {
+	return Func(func(attempt int) time.Duration {
+		return saturate(float64(initial) * math.Pow(multiplier, float64(attempt-1)))
+	})
+}
+
+// Fibonacci returns a strategy which waits unit multiplied by Fibonacci numbers 1, 1, 2, 3, 5 and so on.
+func Fibonacci(unit time.Duration) Backoff {
+	return Func(func(attempt int) time.Duration {
+		a, b := 0.0, 1.0
+		for i := 0; i < attempt; i++ {
+			a, b = b, a+b
+		}
+
+		return saturate(float64(unit) * a)
+	})
+}
+
+// WithCap returns a strategy which clamps durations of provided strategy to provided maximum.
+func WithCap(b Backoff, max time.Duration) Backoff {
+	return elapsedFunc(func(attempt int, elapsed time.Duration) time.Duration {
+		d := backoffElapsed(b, attempt, elapsed)
+		if d > max {
+			return max
+		}
+
+		return d
+	})
+}
+
+// WithMaxElapsed returns a strategy which returns Stop once the total duration waited by a request, including the duration after an attempt,
+// would exceed provided maximum. Callers which do not pass waited duration through BackoffElapsed are assumed to have waited the sum of
+// durations of provided strategy up to the attempt, which is computed again for every attempt.
+func WithMaxElapsed(b Backoff, maxElapsed time.Duration) Backoff {
+	return elapsedFunc(func(attempt int, elapsed time.Duration) time.Duration {
+		if elapsed < 0 {
+			elapsed = 0
+			for i := 1; i < attempt; i++ {
+				d := backoffElapsed(b, i, elapsed)
+				if d < 0 {
+					return Stop
+				}
+				elapsed += d
+			}
+		}
+
+		d := backoffElapsed(b, attempt, elapsed)
+		if d < 0 || d > maxElapsed-elapsed {
+			return Stop
+		}
+
+		return d
+	})
+}
+
+// WithJitter returns a strategy which randomizes durations of provided strategy within provided fraction of them, a jitter of 0.5 waits between 50% and 150%.
+// Random numbers are taken from provided source, which does not need to be safe for concurrent use. A source seeded with current time is used when source is nil.
+func WithJitter(b Backoff, jitter float64, src rand.Source) Backoff {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+
+	var mu sync.Mutex
+	r := rand.New(src)
+
+	return elapsedFunc(func(attempt int, elapsed time.Duration) time.Duration {
+		d := backoffElapsed(b, attempt, elapsed)
+		if d <= 0 || jitter <= 0 {
+			return d
+		}
+
+		mu.Lock()
+		f := r.Float64()
+		mu.Unlock()
+
+		delta := jitter * float64(d)
+
+		return saturate(float64(d) - delta + f*2*delta)
+	})
+}
+
+// saturate converts provided duration in nanoseconds to time.Duration without overflowing.
+func saturate(d float64) time.Duration {
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	if d < 0 {
+		return 0
+	}
+
+	return time.Duration(d)
+}
@@ -0,0 +1,92 @@
+package backoff
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// schedule returns durations of provided strategy after attempts 1 to n.
+func schedule(b Backoff, n int) []time.Duration {
+	durations := make([]time.Duration, n)
+	for i := range durations {
+		durations[i] = b.Backoff(i + 1)
+	}
+
+	return durations
+}
+
+// Strategies should return expected durations.
+func TestStrategies(t *testing.T) {
+	s := time.Second
+	tests := []struct {
+		name     string
+		b        Backoff
+		expected []time.Duration
+	}{
+		{"constant", Constant(s), []time.Duration{s, s, s, s, s}},
+		{"linear", Linear(s, 2*s), []time.Duration{s, 3 * s, 5 * s, 7 * s, 9 * s}},
+		{"exponential", Exponential(s, 2), []time.Duration{s, 2 * s, 4 * s, 8 * s, 16 * s}},
+		{"fibonacci", Fibonacci(s), []time.Duration{s, s, 2 * s, 3 * s, 5 * s}},
+		{"cap", WithCap(Exponential(s, 2), 5*s), []time.Duration{s, 2 * s, 4 * s, 5 * s, 5 * s}},
+		{"max elapsed", WithMaxElapsed(Linear(s, s), 6*s), []time.Duration{s, 2 * s, 3 * s, Stop, Stop}},
+	}
+
+	for _, test := range tests {
+		if durations := schedule(test.b, 5); !reflect.DeepEqual(durations, test.expected) {
+			t.Errorf("unexpected %s durations, %v", test.name, durations)
+		}
+	}
+
+	if d := Exponential(s, 10).Backoff(100); d != time.Duration(1<<63-1) {
+		t.Errorf("unexpected saturated duration, %s", d)
+	}
+}
+
+// WithJitter function should return reproducible durations within jitter for seeded sources.
+func TestWithJitter(t *testing.T) {
+	first := schedule(WithJitter(Constant(time.Second), 0.5, rand.NewSource(42)), 10)
+	second := schedule(WithJitter(Constant(time.Second), 0.5, rand.NewSource(42)), 10)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("jittered durations are not reproducible, %v, %v", first, second)
+	}
+
+	for _, d := range first {
+		if d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Errorf("unexpected jittered duration, %s", d)
+		}
+	}
+
+	if d := WithJitter(WithMaxElapsed(Constant(time.Second), 0), 0.5, nil).Backoff(1); d != Stop {
+		t.Errorf("unexpected jittered stop, %s", d)
+	}
+}
+
+// BackoffElapsed method of WithMaxElapsed strategies should compare waited duration without computing durations of previous attempts.
+func TestWithMaxElapsedWaited(t *testing.T) {
+	calls := 0
+	counted := Func(func(attempt int) time.Duration {
+		calls++
+		return time.Second
+	})
+	b := WithMaxElapsed(WithJitter(counted, 0.5, rand.NewSource(42)), 4*time.Second).(ElapsedBackoff)
+
+	var elapsed time.Duration
+	attempts := 0
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := b.BackoffElapsed(attempt, elapsed)
+		if d == Stop {
+			break
+		}
+		elapsed += d
+		attempts++
+	}
+
+	if elapsed > 4*time.Second || elapsed < 2500*time.Millisecond {
+		t.Errorf("unexpected elapsed duration, %s", elapsed)
+	}
+	if calls != attempts+1 {
+		t.Errorf("unexpected call count of wrapped strategy, %d for %d attempts", calls, attempts)
+	}
+}
@@ -0,0 +1,191 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// backoffFunc is a backoff policy implemented by a function.
+type backoffFunc func(attempt int) time.Duration
+
+// Backoff calls the function.
+func (f backoffFunc) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// NewClient function should return ErrNilBackoffPolicy when nil backoff policy is provided.
+func TestNilBackoffPolicyOption(t *testing.T) {
+	_, err := NewClient(
+		WithBackoffPolicy(nil),
+	)
+	if !errors.Is(err, ErrNilBackoffPolicy) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// DoWithReport method of a client with backoff policy should wait durations returned by the policy for each failed attempt.
+func TestBackoffPolicy(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoffPolicy(backoffFunc(func(attempt int) time.Duration {
+			return time.Duration(attempt) * time.Second
+		})),
+		WithClock(clock),
+		WithSleeper(clock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, report, _ := c.DoWithReport(req)
+	if len(report.Attempts) != 3 || report.Attempts[1].Timing.Backoff != time.Second || report.Attempts[2].Timing.Backoff != 2*time.Second {
+		t.Errorf("unexpected backoff durations, total %s", report.TotalBackoff)
+	}
+}
+
+// NewClient function should return ErrInvalidMaxBackoff when non-positive maximum backoff is provided.
+func TestInvalidMaxBackoffOptions(t *testing.T) {
+	for _, opt := range []Option{WithMaxBackoff(0), WithMaxTotalBackoff(-time.Second)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidMaxBackoff) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// DoWithReport method of a client with maximum backoffs should clamp backoff durations and give up when total backoff is exhausted.
+func TestMaxBackoff(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithMaxReqCount(10),
+		WithBackoffPolicy(backoffFunc(func(attempt int) time.Duration {
+			return time.Duration(attempt) * time.Second
+		})),
+		WithMaxBackoff(2*time.Second),
+		WithMaxTotalBackoff(5*time.Second),
+		WithClock(clock),
+		WithSleeper(clock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, report, err := c.DoWithReport(req)
+	if !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	var backoffs []time.Duration
+	for _, attempt := range report.Attempts[1:] {
+		backoffs = append(backoffs, attempt.Timing.Backoff)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 2 * time.Second}
+	if !reflect.DeepEqual(backoffs, expected) || report.TotalBackoff != 5*time.Second {
+		t.Errorf("unexpected backoff durations, %v", backoffs)
+	}
+}
+
+// Do method of a client should give up when backoff policy returns a negative duration.
+func TestStoppingBackoffPolicy(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(5),
+		WithBackoffPolicy(backoffFunc(func(attempt int) time.Duration {
+			if attempt >= 2 {
+				return -1
+			}
+
+			return 0
+		})),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err == nil {
+		t.Error("expected error")
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// elapsedBackoffFunc is an elapsed backoff policy implemented by a function.
+type elapsedBackoffFunc func(attempt int, elapsed time.Duration) time.Duration
+
+// Backoff calls the function with zero elapsed duration.
+func (f elapsedBackoffFunc) Backoff(attempt int) time.Duration {
+	return f(attempt, 0)
+}
+
+// BackoffElapsed calls the function.
+func (f elapsedBackoffFunc) BackoffElapsed(attempt int, elapsed time.Duration) time.Duration {
+	return f(attempt, elapsed)
+}
+
+// Do method of a client with an elapsed backoff policy should pass total backoff waited by the request, after jitter and limits.
+func TestElapsedBackoffPolicy(t *testing.T) {
+	var elapsed []time.Duration
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		})}),
+		WithMaxReqCount(4),
+		WithBackoffPolicy(elapsedBackoffFunc(func(attempt int, e time.Duration) time.Duration {
+			elapsed = append(elapsed, e)
+			return 2 * time.Second
+		})),
+		WithMaxBackoff(time.Second),
+		WithClock(clock),
+		WithSleeper(clock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	c.Do(req)
+
+	if expected := []time.Duration{0, time.Second, 2 * time.Second}; !reflect.DeepEqual(elapsed, expected) {
+		t.Errorf("unexpected elapsed durations, %v", elapsed)
+	}
+}
@@ -0,0 +1,333 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// body errors
+var (
+	ErrContentLengthMismatch = errors.New("content length mismatch")
+	ErrBodyTooLarge          = errors.New("response body is too large")
+	ErrInvalidBodyLimit      = errors.New("body limits must not be negative and one of them must be set")
+	ErrInvalidMaxResBytes    = errors.New("maximum response bytes must be greater than zero")
+)
+
+// WithContentLengthCheck configures whether bodies buffered by the client are compared against declared Content-Length header.
+// Mismatches are treated as retryable truncations and counted in client's statistics. Content length is not checked by default.
+func WithContentLengthCheck(check bool) Option {
+	return named("WithContentLengthCheck", func(c *Client) error {
+		c.contentLengthCheck = check
+
+		return nil
+	})
+}
+
+// WithBodyMemoryLimit configures client to limit memory of response bodies buffered by validators, typed helpers and GraphQL helpers.
+// A buffered body may hold at most perRequest bytes, and bodies buffered concurrently by client and its clones may hold at most total bytes
+// until they are closed. Bodies exceeding the limits fail permanently with ErrBodyTooLarge. Zero values disable the respective limit.
+func WithBodyMemoryLimit(perRequest, total int64) Option {
+	return named("WithBodyMemoryLimit", func(c *Client) error {
+		if perRequest < 0 || total < 0 || (perRequest == 0 && total == 0) {
+			return ErrInvalidBodyLimit
+		}
+
+		c.maxBufferedBody = perRequest
+		c.bodyBudget = nil
+		if total > 0 {
+			c.bodyBudget = &memoryBudget{limit: total}
+		}
+
+		return nil
+	})
+}
+
+// WithMaxResponseBytes configures client to limit response bodies to provided byte count. Attempts whose responses declare a larger
+// Content-Length fail with ErrBodyTooLarge, and reading more bytes from a body returns ErrBodyTooLarge. Failures are retried when retry is true,
+// otherwise they are permanent. Response sizes are not limited by default.
+func WithMaxResponseBytes(n int64, retry bool) Option {
+	return named("WithMaxResponseBytes", func(c *Client) error {
+		if n <= 0 {
+			return ErrInvalidMaxResBytes
+		}
+
+		c.maxResBytes = n
+		c.retryLargeRes = retry
+
+		return nil
+	})
+}
+
+// limitResponse wraps provided response's body to fail with ErrBodyTooLarge after client's maximum response bytes,
+// it returns ErrBodyTooLarge when response declares a larger content length.
+func (c *Client) limitResponse(res *http.Response) error {
+	if res == nil || res.Body == nil {
+		return nil
+	}
+
+	res.Body = &limitedBody{ReadCloser: res.Body, n: c.maxResBytes, err: c.resTooLarge()}
+	if res.ContentLength > c.maxResBytes {
+		return c.resTooLarge()
+	}
+
+	return nil
+}
+
+// resTooLarge returns the error of responses exceeding client's maximum response bytes.
+func (c *Client) resTooLarge() error {
+	err := fmt.Errorf("%w, exceeds limit of %d bytes", ErrBodyTooLarge, c.maxResBytes)
+	if !c.retryLargeRes {
+		err = Permanent(err)
+	}
+
+	return err
+}
+
+// limitedBody is a response body which returns an error after reading a limited number of bytes.
+type limitedBody struct {
+	io.ReadCloser
+	n   int64
+	err error
+}
+
+// Read reads at most the remaining number of bytes, it returns body's error when body has more bytes than the limit.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n < 0 {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.n+1 {
+		p = p[:b.n+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.n -= int64(n)
+	if b.n < 0 {
+		return n + int(b.n), b.err
+	}
+
+	return n, err
+}
+
+// memoryBudget limits memory held by buffered bodies, used bytes are updated atomically.
+type memoryBudget struct {
+	limit int64
+	used  int64
+}
+
+// reserve reserves provided byte count, it reports whether the budget has room for it.
+func (b *memoryBudget) reserve(n int64) bool {
+	for {
+		used := atomic.LoadInt64(&b.used)
+		if used+n > b.limit {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&b.used, used, used+n) {
+			return true
+		}
+	}
+}
+
+// reserveUpTo reserves at most provided byte count as the budget has room for, it returns reserved byte count.
+func (b *memoryBudget) reserveUpTo(n int64) int64 {
+	for {
+		used := atomic.LoadInt64(&b.used)
+		if room := b.limit - used; room < n {
+			n = room
+		}
+		if n <= 0 {
+			return 0
+		}
+		if atomic.CompareAndSwapInt64(&b.used, used, used+n) {
+			return n
+		}
+	}
+}
+
+// release releases provided byte count.
+func (b *memoryBudget) release(n int64) {
+	atomic.AddInt64(&b.used, -n)
+}
+
+// budgetChunk is the maximum byte count reserved from a memory budget for a single read of a body whose length is not known.
+const budgetChunk = 32 << 10
+
+// reservingReader is a reader which reserves memory budget for bytes before reading them.
+type reservingReader struct {
+	io.Reader
+	budget   *memoryBudget
+	reserved int64
+	read     int64
+	exceeded bool
+}
+
+// Read reserves budget for at most budgetChunk bytes of provided buffer and reads as many bytes as the budget has room for.
+// It returns ErrBodyTooLarge when the budget has no room for the rest of the body.
+func (r *reservingReader) Read(p []byte) (int, error) {
+	if len(p) > budgetChunk {
+		p = p[:budgetChunk]
+	}
+	if avail := r.reserved - r.read; avail < int64(len(p)) {
+		r.reserved += r.budget.reserveUpTo(int64(len(p)) - avail)
+		avail = r.reserved - r.read
+		if avail == 0 {
+			// budget has no room, a single byte is read to tell whether the body ends here
+			var b [1]byte
+			n, err := r.Reader.Read(b[:])
+			if n > 0 {
+				r.exceeded = true
+				return 0, ErrBodyTooLarge
+			}
+
+			return 0, err
+		}
+		p = p[:avail]
+	}
+
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+
+	return n, err
+}
+
+// reserve reserves provided byte count from reader's budget, it reports whether the budget has room for it.
+func (r *reservingReader) reserve(n int64) bool {
+	if !r.budget.reserve(n) {
+		return false
+	}
+	r.reserved += n
+
+	return true
+}
+
+// release releases reserved bytes exceeding provided byte count which stays reserved.
+func (r *reservingReader) release(keep int64) {
+	r.budget.release(r.reserved - keep)
+	r.reserved = keep
+}
+
+// budgetBody is a buffered body which releases its reservation of memory budget when it is closed.
+type budgetBody struct {
+	io.Reader
+	budget *memoryBudget
+	size   int64
+	once   sync.Once
+}
+
+// Close releases body's reservation.
+func (b *budgetBody) Close() error {
+	b.once.Do(func() {
+		b.budget.release(b.size)
+	})
+
+	return nil
+}
+
+// ReadAll reads and closes body of provided response, returning ErrBodyTooLarge when it exceeds provided byte count.
+// Body is closed even when reading fails, zero or negative byte count means body size is not limited.
+func ReadAll(res *http.Response, maxBytes int64) ([]byte, error) {
+	if res == nil {
+		return nil, ErrNilRes
+	}
+	defer res.Body.Close()
+
+	if maxBytes > 0 && res.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w, %d bytes exceeds limit of %d bytes", ErrBodyTooLarge, res.ContentLength, maxBytes)
+	}
+
+	var r io.Reader = res.Body
+	if maxBytes > 0 {
+		r = io.LimitReader(res.Body, maxBytes+1)
+	}
+
+	body, err := readAll(r, res.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("%w, exceeds limit of %d bytes", ErrBodyTooLarge, maxBytes)
+	}
+
+	return body, nil
+}
+
+// PeekBody reads at most provided number of bytes of provided response's body and restores the body, so that it can be read again from the start
+// by the caller, handlers and hooks running later. Bytes read before a read error are restored too. It returns nil when response has no body.
+func PeekBody(res *http.Response, n int64) ([]byte, error) {
+	if res == nil || res.Body == nil || res.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, n))
+	res.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+
+	return body, err
+}
+
+// prefixedBody is a body whose bytes read in advance are read again before the rest of the body.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bufferBody reads and closes provided response's body, and replaces it with a buffered copy.
+// When content length check is enabled, it returns ErrContentLengthMismatch if read byte count differs from declared content length.
+// It returns ErrBodyTooLarge when body exceeds client's body memory limits.
+func (c *Client) bufferBody(res *http.Response) ([]byte, error) {
+	if c.maxBufferedBody > 0 && res.ContentLength > c.maxBufferedBody {
+		return nil, Permanent(fmt.Errorf("%w, %d bytes exceeds limit of %d bytes", ErrBodyTooLarge, res.ContentLength, c.maxBufferedBody))
+	}
+
+	var r io.Reader = res.Body
+	if c.maxBufferedBody > 0 {
+		r = io.LimitReader(res.Body, c.maxBufferedBody+1)
+	}
+
+	// memory budget is reserved before bytes are buffered, up front for declared content length and chunk by chunk beyond it
+	var reserving *reservingReader
+	if c.bodyBudget != nil {
+		reserving = &reservingReader{Reader: r, budget: c.bodyBudget}
+		if res.ContentLength > 0 && !reserving.reserve(res.ContentLength) {
+			return nil, Permanent(fmt.Errorf("%w, %d bytes exceeds memory budget of %d bytes", ErrBodyTooLarge, res.ContentLength, c.bodyBudget.limit))
+		}
+		r = reserving
+	}
+
+	body, err := readAll(r, res.ContentLength)
+	res.Body.Close()
+	res.Body = http.NoBody
+
+	size := int64(len(body))
+	if reserving != nil && reserving.exceeded {
+		reserving.release(0)
+		return nil, Permanent(fmt.Errorf("%w, exceeds memory budget of %d bytes", ErrBodyTooLarge, c.bodyBudget.limit))
+	}
+	if c.maxBufferedBody > 0 && size > c.maxBufferedBody {
+		if reserving != nil {
+			reserving.release(0)
+		}
+		return nil, Permanent(fmt.Errorf("%w, exceeds limit of %d bytes", ErrBodyTooLarge, c.maxBufferedBody))
+	}
+	if reserving != nil {
+		reserving.release(size)
+		res.Body = &budgetBody{Reader: bytes.NewReader(body), budget: c.bodyBudget, size: size}
+	} else {
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if c.contentLengthCheck && res.ContentLength >= 0 && int64(len(body)) != res.ContentLength {
+		c.stats.truncations.add(1)
+
+		return nil, fmt.Errorf("%w, read %d of %d bytes", ErrContentLengthMismatch, len(body), res.ContentLength)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
@@ -0,0 +1,278 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// GetJSON method of a client with content length check should retry truncated responses and count truncations.
+func TestContentLengthCheck(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijacking connection failed, %s", err.Error())
+
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 17\r\n\r\n")
+		rw.WriteString(`{"name":`)
+		rw.Flush()
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithContentLengthCheck(true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out map[string]string
+	err = c.GetJSON(context.Background(), s.URL, &out)
+	if !errors.Is(err, ErrContentLengthMismatch) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+	if truncations := c.Stats().Truncations; truncations != 2 {
+		t.Errorf("unexpected truncation count, %d", truncations)
+	}
+}
+
+// NewClient function should return ErrInvalidBodyLimit when invalid body memory limits are provided.
+func TestInvalidBodyMemoryLimitOptions(t *testing.T) {
+	for _, opt := range []Option{WithBodyMemoryLimit(0, 0), WithBodyMemoryLimit(-1, 0), WithBodyMemoryLimit(0, -1)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidBodyLimit) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// GetJSON method of a client with body memory limit should fail permanently with ErrBodyTooLarge when a body exceeds per request limit.
+func TestBodyMemoryLimit(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(`{"name":"a long enough name"}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithBodyMemoryLimit(16, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for _, url := range []string{s.URL, s.URL + "?chunked=true"} {
+		var out map[string]string
+		if err := c.GetJSON(context.Background(), url, &out); !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// bufferBody method of a client with body memory budget should reserve budget until buffered bodies are closed.
+func TestBodyMemoryBudget(t *testing.T) {
+	c, err := NewClient(
+		WithBodyMemoryLimit(0, 10),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	newRes := func() *http.Response {
+		return &http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader("123456"))}
+	}
+
+	first := newRes()
+	if _, err := c.bufferBody(first); err != nil {
+		t.Errorf("unexpected error, %s", err.Error())
+	}
+	if _, err := c.bufferBody(newRes()); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	first.Body.Close()
+	first.Body.Close()
+	if _, err := c.bufferBody(newRes()); err != nil {
+		t.Errorf("unexpected error after release, %s", err.Error())
+	}
+	if used := c.bodyBudget.used; used != 6 {
+		t.Errorf("unexpected used budget, %d", used)
+	}
+}
+
+// countingReader is a reader which counts bytes read from it.
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+// Read reads from underlying reader and counts read bytes.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+
+	return n, err
+}
+
+// bufferBody method of a client with body memory budget should reserve budget before reading bodies and stop reading when it has no room.
+func TestBodyMemoryBudgetReserve(t *testing.T) {
+	c, err := NewClient(
+		WithBodyMemoryLimit(0, 1000),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for _, contentLength := range []int64{1 << 20, -1} {
+		body := &countingReader{Reader: strings.NewReader(strings.Repeat("a", 1<<20))}
+		res := &http.Response{ContentLength: contentLength, Body: io.NopCloser(body)}
+		if _, err := c.bufferBody(res); !errors.Is(err, ErrBodyTooLarge) || !IsPermanent(err) {
+			t.Errorf("unexpected error, %v", err)
+		}
+		if body.n > 1001 {
+			t.Errorf("unexpected read byte count with content length %d, %d", contentLength, body.n)
+		}
+		if used := c.bodyBudget.used; used != 0 {
+			t.Errorf("unexpected used budget, %d", used)
+		}
+	}
+}
+
+// NewClient function should return ErrInvalidMaxResBytes when non-positive maximum response bytes is provided.
+func TestInvalidMaxResponseBytesOption(t *testing.T) {
+	_, err := NewClient(
+		WithMaxResponseBytes(0, false),
+	)
+	if !errors.Is(err, ErrInvalidMaxResBytes) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with maximum response bytes should fail responses declaring larger content lengths, retrying them when configured.
+func TestMaxResponseBytes(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte("0123456789"))
+	}))
+	defer s.Close()
+
+	for retry, expected := range map[bool]int{true: 3, false: 1} {
+		reqCount = 0
+		c, err := NewClient(
+			WithMaxReqCount(3),
+			WithBackoff(0),
+			WithMaxResponseBytes(8, retry),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		res, err := c.Do(req)
+		if !errors.Is(err, ErrBodyTooLarge) || reqCount != expected {
+			t.Errorf("unexpected error %v or request count %d", err, reqCount)
+		}
+		res.Body.Close()
+
+		req, err = http.NewRequest(http.MethodGet, s.URL+"?chunked=true", http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		res, err = c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err.Error())
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if !errors.Is(err, ErrBodyTooLarge) || string(body) != "01234567" {
+			t.Errorf("unexpected body %q or error %v", body, err)
+		}
+	}
+}
+
+// ReadAll function should read and close response bodies, returning ErrBodyTooLarge for bodies exceeding the limit.
+func TestReadAllResponse(t *testing.T) {
+	for _, tc := range []struct {
+		maxBytes      int64
+		contentLength int64
+		err           error
+	}{
+		{maxBytes: 0, contentLength: -1},
+		{maxBytes: 4, contentLength: 4},
+		{maxBytes: 3, contentLength: -1, err: ErrBodyTooLarge},
+		{maxBytes: 3, contentLength: 4, err: ErrBodyTooLarge},
+	} {
+		body := &closeRecorder{ReadCloser: io.NopCloser(strings.NewReader("body"))}
+		data, err := ReadAll(&http.Response{Body: body, ContentLength: tc.contentLength}, tc.maxBytes)
+		if !errors.Is(err, tc.err) || (err == nil && string(data) != "body") {
+			t.Errorf("unexpected result, %q, %v", data, err)
+		}
+		if !body.closed {
+			t.Errorf("body is not closed")
+		}
+	}
+
+	if _, err := ReadAll(nil, 0); err != ErrNilRes {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// PeekBody function should read a prefix of response body and restore the body so it can be read again from the start.
+func TestPeekBody(t *testing.T) {
+	body := &closeRecorder{ReadCloser: io.NopCloser(strings.NewReader("body"))}
+	res := &http.Response{Body: body}
+
+	prefix, err := PeekBody(res, 2)
+	if err != nil || string(prefix) != "bo" {
+		t.Errorf("unexpected prefix, %q, %v", prefix, err)
+	}
+	prefix, err = PeekBody(res, 8)
+	if err != nil || string(prefix) != "body" {
+		t.Errorf("unexpected prefix, %q, %v", prefix, err)
+	}
+
+	data, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(data) != "body" {
+		t.Errorf("unexpected body, %q", data)
+	}
+	if !body.closed {
+		t.Errorf("body is not closed")
+	}
+
+	if prefix, err := PeekBody(&http.Response{Body: http.NoBody}, 8); prefix != nil || err != nil {
+		t.Errorf("unexpected prefix, %q, %v", prefix, err)
+	}
+}
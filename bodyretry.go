@@ -0,0 +1,61 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// body retry errors
+var (
+	ErrNilBodyRetryPredicate = errors.New("body retry predicate is nil")
+	ErrInvalidBodyRetryBytes = errors.New("body retry bytes must not be negative")
+	ErrRetryableBody         = errors.New("response body is retryable")
+)
+
+// BodyRetryPredicate reports whether provided response should be retried, provided body is a prefix of response's body.
+type BodyRetryPredicate func(res *http.Response, body []byte) bool
+
+// WithBodyRetryPredicate configures client to decide whether responses are retried with provided predicate reading at most provided number of bytes
+// of their bodies, zero means 64 KiB. Responses accepted by response handler are retried with ErrRetryableBody when the predicate reports true and
+// responses rejected by response handler are not retried when it reports false, permanent errors are never retried.
+// The body stays fully readable, so that the final response delivered to the caller can be read from the start. Bodies are not inspected by default.
+func WithBodyRetryPredicate(maxBytes int64, predicate BodyRetryPredicate) Option {
+	return named("WithBodyRetryPredicate", func(c *Client) error {
+		if predicate == nil {
+			return ErrNilBodyRetryPredicate
+		}
+		if maxBytes < 0 {
+			return ErrInvalidBodyRetryBytes
+		}
+		if maxBytes == 0 {
+			maxBytes = maxErrorBodyBytes
+		}
+
+		c.bodyRetry = predicate
+		c.bodyRetryBytes = maxBytes
+
+		return nil
+	})
+}
+
+// inspectBody returns the error of provided response handled with provided error of response handler, decided by client's body retry predicate.
+func (c *Client) inspectBody(res *http.Response, err error) error {
+	if res == nil || IsPermanent(err) {
+		return err
+	}
+
+	body, readErr := PeekBody(res, c.bodyRetryBytes)
+	if readErr != nil {
+		return err
+	}
+
+	retry := c.bodyRetry(res, body)
+	if err == nil && retry {
+		return ErrRetryableBody
+	}
+	if err != nil && !retry {
+		return Permanent(err)
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrNilBodyRetryPredicate and ErrInvalidBodyRetryBytes when invalid body retry settings are provided.
+func TestInvalidBodyRetryPredicateOptions(t *testing.T) {
+	if _, err := NewClient(WithBodyRetryPredicate(0, nil)); !errors.Is(err, ErrNilBodyRetryPredicate) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	predicate := func(res *http.Response, body []byte) bool {
+		return false
+	}
+	if _, err := NewClient(WithBodyRetryPredicate(-1, predicate)); !errors.Is(err, ErrInvalidBodyRetryBytes) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with a body retry predicate should retry responses by their body prefixes and deliver fully readable bodies.
+func TestBodyRetryPredicate(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		switch reqCount {
+		case 1:
+			w.Write([]byte(`{"status":"pending"}`))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"busy"}`))
+		default:
+			w.Write([]byte(`{"status":"done","items":[1,2,3]}`))
+		}
+	}))
+	defer s.Close()
+
+	var prefixes []string
+	c, err := NewClient(
+		WithMaxReqCount(5),
+		WithBackoff(0),
+		WithBodyRetryPredicate(16, func(res *http.Response, body []byte) bool {
+			prefixes = append(prefixes, string(body))
+
+			return !bytes.HasPrefix(body, []byte(`{"status":"done"`))
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Fatalf("sending request failed, %s", err.Error())
+	}
+	if reqCount != 3 || len(prefixes) != 3 || prefixes[0] != `{"status":"pendi` {
+		t.Errorf("unexpected request count or prefixes, %d, %v", reqCount, prefixes)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != `{"status":"done","items":[1,2,3]}` {
+		t.Errorf("unexpected body, %s", body)
+	}
+}
+
+// Do method of a client with a body retry predicate should not retry failed responses whose bodies are not retryable.
+func TestBodyRetryPredicatePermanent(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"invalid account"}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithBodyRetryPredicate(0, func(res *http.Response, body []byte) bool {
+			return !bytes.Contains(body, []byte("invalid account"))
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if !IsPermanent(err) || errors.Is(err, ErrRetryableBody) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+	if res != nil {
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != `{"error":"invalid account"}` {
+			t.Errorf("unexpected body, %s", body)
+		}
+	}
+}
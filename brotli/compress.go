@@ -0,0 +1,31 @@
+// Package brotli provides brotli content coding for retryablehttp's request compression and response decompression.
+package brotli
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// content encodings
+const (
+	ContentEncoding = "br"
+)
+
+// Compressor compresses request bodies and decompresses response bodies with brotli.
+type Compressor struct{}
+
+// ContentEncoding returns br.
+func (Compressor) ContentEncoding() string {
+	return ContentEncoding
+}
+
+// NewWriter returns a brotli writer compressing into provided writer with default compression level.
+func (Compressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriter(w), nil
+}
+
+// NewReader returns a brotli reader decompressing provided reader.
+func (Compressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
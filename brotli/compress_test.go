@@ -0,0 +1,73 @@
+package brotli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/ermanimer/retryablehttp"
+)
+
+// Do method of a client with brotli response decompression should advertise br encoding and decompress responses.
+func TestResponseDecompression(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != ContentEncoding {
+			t.Errorf("unexpected accept encoding, %s", r.Header.Get("Accept-Encoding"))
+		}
+
+		w.Header().Set("Content-Encoding", ContentEncoding)
+		bw := brotli.NewWriter(w)
+		bw.Write([]byte("body"))
+		bw.Close()
+	}))
+	defer s.Close()
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithResponseDecompression(Compressor{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil || string(body) != "body" {
+		t.Errorf("unexpected body, %q, %v", body, err)
+	}
+}
+
+// Body of a response decompressed with brotli should return ErrCorruptBody when compressed stream is corrupt.
+func TestCorruptResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", ContentEncoding)
+		w.Write([]byte("not brotli"))
+	}))
+	defer s.Close()
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithResponseDecompression(Compressor{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	if _, err := io.ReadAll(res.Body); !errors.Is(err, retryablehttp.ErrCorruptBody) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
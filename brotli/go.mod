@@ -0,0 +1,10 @@
+module github.com/ermanimer/retryablehttp/brotli
+
+go 1.20
+
+require (
+	github.com/andybalholm/brotli v1.1.0
+	github.com/ermanimer/retryablehttp v0.0.0
+)
+
+replace github.com/ermanimer/retryablehttp => ../
@@ -0,0 +1,63 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+)
+
+// retry budget errors
+var (
+	ErrInvalidRetryBudgets = errors.New("retry budgets must not be negative")
+)
+
+// WithRetryBudgets configures separate numbers of retries of a request after connection failures, attempts failed without a response such as
+// dial, TLS and reset failures, and after response failures, responses rejected by response handler. A request gives up when the budget of
+// its latest failure is exhausted, maximum request count still limits the total number of attempts. Failures of both kinds are counted in Stats
+// regardless of budgets. Retries are limited only by maximum request count by default.
+func WithRetryBudgets(connectionRetries, responseRetries int) Option {
+	return named("WithRetryBudgets", func(c *Client) error {
+		if connectionRetries < 0 || responseRetries < 0 {
+			return ErrInvalidRetryBudgets
+		}
+
+		c.retryBudgets = true
+		c.connectionRetries = connectionRetries
+		c.responseRetries = responseRetries
+
+		return nil
+	})
+}
+
+// spendRetryBudget counts provided failure of call's current attempt in Stats and in the retry budget of its kind, it reports whether the budget
+// allows a retry. Failures which are neither connection nor response failures, such as ErrOverloaded and cancelled contexts, are not counted.
+func (c *Client) spendRetryBudget(cl *call, err error) bool {
+	if cl.res == nil {
+		if !connectionFailure(err) {
+			return true
+		}
+
+		c.stats.connectionFailures.add(1)
+		cl.connectionRetries++
+		return !c.retryBudgets || cl.connectionRetries <= c.connectionRetries
+	}
+
+	c.stats.responseFailures.add(1)
+	cl.responseRetries++
+	return !c.retryBudgets || cl.responseRetries <= c.responseRetries
+}
+
+// connectionFailure reports whether provided error of an attempt without a response is a failure of the transport, such as a dial, TLS or reset
+// failure, rather than a failure before the request is sent or a done context.
+func connectionFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var transportErr *TransportError
+
+	return errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &transportErr)
+}
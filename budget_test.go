@@ -0,0 +1,86 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidRetryBudgets when negative retry budgets are provided.
+func TestInvalidRetryBudgetsOption(t *testing.T) {
+	if _, err := NewClient(WithRetryBudgets(-1, 0)); !errors.Is(err, ErrInvalidRetryBudgets) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with retry budgets should limit retries after connection failures and response failures separately.
+func TestRetryBudgets(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(10),
+		WithRetryBudgets(3, 1),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL, nil)
+	res, _, err := c.DoWithReport(req)
+	discard(res)
+	if err == nil || reqCount != 2 {
+		t.Errorf("unexpected error or request count, %v, %d", err, reqCount)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, closed.URL, nil)
+	_, report, err := c.DoWithReport(req)
+	if err == nil || len(report.Attempts) != 4 {
+		t.Errorf("unexpected error or attempt count, %v, %d", err, len(report.Attempts))
+	}
+
+	if stats := c.Stats(); stats.ConnectionFailures != 4 || stats.ResponseFailures != 2 {
+		t.Errorf("unexpected stats, %d, %d", stats.ConnectionFailures, stats.ResponseFailures)
+	}
+}
+
+// Do method of a client with retry budgets should not spend connection retry budget on failures before requests are sent.
+func TestRetryBudgetsMutatorFailures(t *testing.T) {
+	mutations := 0
+	c, err := NewClient(
+		WithMaxReqCount(4),
+		WithRetryBudgets(1, 0),
+		WithReqMutators(func(req *http.Request) error {
+			mutations++
+			if mutations < 4 {
+				return errors.New("signing failed")
+			}
+
+			return nil
+		}),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	res, err := c.Do(req)
+	discard(res)
+	if err != nil || mutations != 4 {
+		t.Errorf("unexpected error or mutation count, %v, %d", err, mutations)
+	}
+	if stats := c.Stats(); stats.ConnectionFailures != 0 {
+		t.Errorf("unexpected connection failure count, %d", stats.ConnectionFailures)
+	}
+}
@@ -0,0 +1,135 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// canary errors
+var (
+	ErrInvalidCanary = errors.New("canary settings are not valid")
+)
+
+// defaultCanaryCooldown is the duration a canary is ejected for when it is not configured.
+const defaultCanaryCooldown = 30 * time.Second
+
+// Canary represents settings of canary routing, zero values take default values.
+// Fraction of logical requests sent to Primary base url are routed to Canary base url, paths below primary's path are kept below canary's path.
+// When Fallback is set, an attempt failed on the canary is retried on the primary immediately, without counting toward maximum request count.
+// Canary's error rate is tracked independently, as an exponentially weighted moving average of its attempts, and the canary receives no requests
+// for Cooldown (30 seconds) after its error rate exceeds MaxErrorRate. Zero MaxErrorRate never ejects the canary.
+type Canary struct {
+	Primary      string
+	Canary       string
+	Fraction     float64
+	Fallback     bool
+	MaxErrorRate float64
+	Cooldown     time.Duration
+}
+
+// WithCanary configures client to route a fraction of requests sent with Do and methods built on it to a canary deployment.
+// Attempts sent to the canary are recorded in Stats under canary's host. Requests are not routed to a canary by default.
+func WithCanary(cfg Canary) Option {
+	return named("WithCanary", func(c *Client) error {
+		if cfg.Cooldown == 0 {
+			cfg.Cooldown = defaultCanaryCooldown
+		}
+
+		primary, err := url.Parse(cfg.Primary)
+		if err != nil || primary.Scheme == "" || primary.Host == "" {
+			return ErrInvalidCanary
+		}
+		target, err := url.Parse(cfg.Canary)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return ErrInvalidCanary
+		}
+		if cfg.Fraction <= 0 || cfg.Fraction > 1 || cfg.MaxErrorRate < 0 || cfg.MaxErrorRate >= 1 || cfg.Cooldown < 0 {
+			return ErrInvalidCanary
+		}
+
+		c.canary = &canary{
+			cfg:     cfg,
+			primary: primary,
+			target:  target,
+		}
+
+		return nil
+	})
+}
+
+// canary holds canary routing settings and canary's health.
+type canary struct {
+	cfg     Canary
+	primary *url.URL
+	target  *url.URL
+
+	mu           sync.Mutex
+	errorRate    float64
+	ejectedUntil time.Time
+}
+
+// routeCanary reports whether provided request is routed to client's canary.
+func (c *Client) routeCanary(req *http.Request) bool {
+	k := c.canary
+	if !strings.EqualFold(req.URL.Scheme, k.primary.Scheme) || !strings.EqualFold(req.URL.Host, k.primary.Host) ||
+		!withinPath(req.URL.Path, strings.TrimSuffix(k.primary.Path, "/")) {
+		return false
+	}
+
+	k.mu.Lock()
+	ejected := c.clock.Now().Before(k.ejectedUntil)
+	k.mu.Unlock()
+	if ejected {
+		return false
+	}
+
+	return k.cfg.Fraction >= 1 || c.rand.Float64() < k.cfg.Fraction
+}
+
+// withinPath reports whether provided path is provided prefix or lies below it, matching whole path segments so that "/apiv2" is not within "/api".
+func withinPath(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// rewrite returns a copy of provided request sent to the canary.
+func (k *canary) rewrite(req *http.Request) *http.Request {
+	u := *req.URL
+	u.Scheme = k.target.Scheme
+	u.Host = k.target.Host
+	u.Path = strings.TrimSuffix(k.target.Path, "/") + strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(k.primary.Path, "/"))
+	u.RawPath = ""
+
+	routed := req.WithContext(req.Context())
+	routed.URL = &u
+	routed.Host = ""
+
+	return routed
+}
+
+// recordCanary updates canary's error rate with provided error of call's attempt sent to it, ejecting the canary when the rate exceeds its maximum.
+func (c *Client) recordCanary(cl *call, err error) {
+	k := c.canary
+
+	var failure float64
+	if err != nil {
+		failure = 1
+	}
+
+	k.mu.Lock()
+	k.errorRate += ewmaWeight * (failure - k.errorRate)
+	ejected := k.cfg.MaxErrorRate > 0 && k.errorRate > k.cfg.MaxErrorRate
+	if ejected {
+		k.ejectedUntil = c.clock.Now().Add(k.cfg.Cooldown)
+		k.errorRate = 0
+	}
+	until := k.ejectedUntil
+	k.mu.Unlock()
+
+	if ejected && c.events != nil {
+		c.emitCircuitOpened(cl, k.target, until, err)
+	}
+}
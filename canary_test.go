@@ -0,0 +1,68 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidCanary when invalid canary settings are provided.
+func TestInvalidCanaryOption(t *testing.T) {
+	if _, err := NewClient(WithCanary(Canary{Primary: "http://primary", Canary: "/canary", Fraction: 0.1})); !errors.Is(err, ErrInvalidCanary) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithCanary(Canary{Primary: "http://primary", Canary: "http://canary", Fraction: 2})); !errors.Is(err, ErrInvalidCanary) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with a canary should route requests to the canary, fall back to the primary on canary failures and eject an unhealthy canary.
+func TestCanary(t *testing.T) {
+	primaryCount := 0
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCount++
+	}))
+	defer primary.Close()
+
+	var canaryPaths []string
+	canary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canaryPaths = append(canaryPaths, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer canary.Close()
+
+	c, err := NewClient(
+		WithCanary(Canary{
+			Primary:      primary.URL + "/api",
+			Canary:       canary.URL + "/v2",
+			Fraction:     1,
+			Fallback:     true,
+			MaxErrorRate: 0.3,
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(primary.URL + "/apiv2/users")
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+	if len(canaryPaths) != 0 || primaryCount != 1 {
+		t.Errorf("request outside primary path is routed to canary, %v, %d", canaryPaths, primaryCount)
+	}
+
+	for i := 0; i < 3; i++ {
+		res, err := c.Get(primary.URL + "/api/users")
+		if err != nil {
+			t.Errorf("request %d failed, %v", i, err)
+		}
+		discard(res)
+	}
+
+	if len(canaryPaths) != 2 || canaryPaths[0] != "/v2/users" || primaryCount != 4 {
+		t.Errorf("unexpected routing, %v, %d", canaryPaths, primaryCount)
+	}
+}
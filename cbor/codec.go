@@ -0,0 +1,29 @@
+// Package cbor provides a CBOR codec for retryablehttp's typed request helpers.
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+)
+
+// content types
+const (
+	ContentType = "application/cbor"
+)
+
+// Codec encodes and decodes CBOR bodies.
+type Codec struct{}
+
+// ContentType returns application/cbor.
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+// Marshal encodes provided value as CBOR.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+// Unmarshal decodes CBOR data into provided value.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
@@ -1,8 +1,15 @@
 package retryablehttp
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,12 +23,73 @@ var (
 	ErrUnsuccessfulStatusCode = errors.New("unsuccessful status code")
 )
 
+// PermanentError represents an error which must not be retried.
+type PermanentError struct {
+	Err error
+}
+
+// Error returns error message of wrapped error.
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns wrapped error.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps provided error as permanent error, client stops retrying when a permanent error is returned from response handler.
+// Permanent returns nil if provided error is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether any error in provided error's chain is a permanent error.
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// GiveUpError represents the error returned when client gives up retrying, it wraps the last error so that errors.Is and errors.As match
+// package errors and errors of the http client, such as *url.Error, at any depth. Method and URL are those of the final attempt, URL is redacted.
+// Curl is a curl command reproducing the final attempt, it is set only when curl reproduction is enabled.
+// RequestIDs are the distinct request ids sent by the attempts, they are set only when request ids are enabled.
+type GiveUpError struct {
+	Method     string
+	URL        string
+	Attempts   int
+	Err        error
+	Curl       string
+	RequestIDs []string
+}
+
+// Error returns error message including method, url, attempt count and last error.
+func (e *GiveUpError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("giving up after %d attempt(s), %s", e.Attempts, e.Err)
+	}
+
+	return fmt.Sprintf("giving up on %s %s after %d attempt(s), %s", e.Method, e.URL, e.Attempts, e.Err)
+}
+
+// Unwrap returns last error.
+func (e *GiveUpError) Unwrap() error {
+	return e.Err
+}
+
 // default options
 const (
 	defaultMaxReqCount = 1
 	defaultBackoff     = 0
 )
 
+// maxDiscardBytes is the maximum number of bytes read from a discarded response body before closing it.
+const maxDiscardBytes = 4096
+
 var (
 	defaultResHandler = func(res *http.Response) error {
 		if res == nil {
@@ -38,20 +106,216 @@ var (
 )
 
 // Client represents retryable http client.
+// Zero value of Client is ready to use with default options, so a Client can be embedded without calling NewClient.
 type Client struct {
+	// stats is shared with clients derived for host policies.
+	stats *stats
+
+	initOnce sync.Once
+	// mu guards settings which can be changed by Set methods after client is created, and policyClients.
+	mu sync.RWMutex
+	// policyClients holds clients derived for policies keyed by policy index, they are derived once so that state of their options is shared
+	// by requests, and derived again after settings change.
+	policyClients map[int]*Client
+	// pending holds background work of applied options which starts once client is constructed, closers stop started background work.
+	// They are guarded by mu and are not shared with clients derived by Clone or With.
+	pending []func(ctx context.Context)
+	closers []func()
+	settings
+}
+
+// settings holds client's options.
+type settings struct {
 	httpClient  *http.Client
 	maxReqCount int
-	backoff     time.Duration
+	backoff     BackoffPolicy
 	resHandler  func(res *http.Response) error
+
+	resValidator          func(res *http.Response, body []byte) error
+	graphQLRetryableCodes map[string]bool
+	codecs                *CodecRegistry
+	retryOnInvalidJSON    bool
+	contentLengthCheck    bool
+	dumper                *dumper
+	redactor              *redactor
+	curlReproduction      bool
+	auditSink             AuditSink
+	events                chan<- Event
+	eventPolicy           EventPolicy
+	attemptHeader         string
+	requestIDGen          func() string
+	requestIDPerAttempt   bool
+	tracePropagator       TracePropagator
+	profilerLabels        bool
+	slowThreshold         time.Duration
+	slowCallback          func(slow SlowRequest)
+	clock                 Clock
+	sleeper               Sleeper
+	jitter                float64
+	maxBackoff            time.Duration
+	maxTotalBackoff       time.Duration
+	rand                  *rand.Rand
+	deadlineSplit         DeadlineSplit
+	deadlineHeader        string
+	deadlineFormat        DeadlineFormat
+	minAttemptTime        time.Duration
+	limiter               *limiter
+	semaphore             *limiter
+	maxQueueDepth         int
+	maxQueueWait          time.Duration
+	retryLimiter          *tokenBucket
+	panicPolicy           PanicPolicy
+	errorHandler          func(res *http.Response, err error, attempts int) (*http.Response, error)
+	discardFailedRes      bool
+	maxBufferedBody       int64
+	bodyBudget            *memoryBudget
+	maxResBytes           int64
+	retryLargeRes         bool
+	expectContinue        bool
+	expectMinBody         int64
+	redirectPolicy        RedirectPolicy
+	maxRedirects          int
+	redirectAuth          func(req *http.Request) error
+	cookieJar             http.CookieJar
+	compressor            Compressor
+	compressMinSize       int64
+	errorBodyParser       ErrorBodyParser
+	maintenance           *maintenance
+	quota                 *quotaPacer
+	urlRefresh            URLRefreshFunc
+	maxURLRefreshes       int
+	mirrorTarget          *url.URL
+	mirrorRate            float64
+	mirrorInFlight        *int64
+	mirrorMax             int
+	mirrorTimeout         time.Duration
+	canary                *canary
+	endpoints             *endpointPool
+	affinity              AffinityKey
+	ejectionFailures      int
+	ejectionDuration      time.Duration
+	localRegion           string
+	localZone             string
+	localAttempts         int
+	endpointSelection     EndpointSelection
+	failoverPolicy        *FailoverPolicy
+	retryDNSNotFound      bool
+	fallbackResolver      *fallbackResolver
+	dialRetry             *dialRetry
+	retryBudgets          bool
+	connectionRetries     int
+	responseRetries       int
+	attemptContexts       bool
+	bodyRetry             BodyRetryPredicate
+	bodyRetryBytes        int64
+	resTransformers       []ResTransformer
+	reqMutators           []ReqMutator
+	transportErrors       bool
+	successPredicates     []func(out interface{}) error
+	decompressors         map[string]Decompressor
+	acceptEncoding        string
+	acceptHeader          string
+	acceptedTypes         []string
+	policies              []policy
+	configErrorHandler    func(err error)
+	retryOn               RetryCondition
 }
 
 // Option configures client options.
 type Option func(c *Client) error
 
+// Clone returns a copy of client with the same options, sharing underlying http client. Statistics of the copy start from zero.
+func (c *Client) Clone() *Client {
+	c.init()
+
+	c.mu.RLock()
+	clone := &Client{settings: c.settings}
+	c.mu.RUnlock()
+	clone.initOnce.Do(func() {})
+	clone.stats = &stats{}
+
+	return clone
+}
+
+// With returns a copy of client with provided options applied, sharing underlying http client unless it is replaced.
+// It is useful for deriving per endpoint variants of a client, client itself is not modified. Errors are reported like NewClient does.
+// Background work of provided options, such as watching a config provider, belongs to the copy and is stopped by its Close method.
+func (c *Client) With(opts ...Option) (*Client, error) {
+	clone := c.Clone()
+	if err := applyOptions(clone, opts); err != nil {
+		clone.Close()
+		return nil, err
+	}
+	clone.start()
+
+	return clone, nil
+}
+
+// Close stops background work started by client's options, such as watching a config provider or an endpoint watcher, it always returns nil.
+// Client keeps working after it is closed with the settings it has, clients derived by Clone or With are not closed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	closers, derived := c.closers, c.policyClients
+	c.pending, c.closers, c.policyClients = nil, nil, nil
+	c.mu.Unlock()
+
+	for _, stop := range closers {
+		stop()
+	}
+	for _, d := range derived {
+		d.Close()
+	}
+
+	return nil
+}
+
+// background records background work of an option, it is started with a context cancelled by Close once client is constructed,
+// so that work of clients failing validation is never started.
+func (c *Client) background(work func(ctx context.Context)) {
+	c.pending = append(c.pending, work)
+}
+
+// onClose records a function stopping background work of an option, it is called by Close.
+func (c *Client) onClose(stop func()) {
+	c.closers = append(c.closers, stop)
+}
+
+// start starts pending background work of client.
+func (c *Client) start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.closers = append(c.closers, cancel)
+	for _, work := range c.pending {
+		go work(ctx)
+	}
+	c.pending = nil
+}
+
+// init sets default options of client once, so that zero value of Client is usable.
+func (c *Client) init() {
+	c.initOnce.Do(func() {
+		c.stats = &stats{}
+		c.httpClient = http.DefaultClient
+		c.maxReqCount = defaultMaxReqCount
+		c.backoff = ConstantBackoff(defaultBackoff)
+		c.resHandler = defaultResHandler
+		c.redactor = defaultRedactor
+		c.clock = systemClock{}
+		c.sleeper = systemClock{}
+		c.rand = newRand()
+	})
+}
+
 // WithHTTPClient configures client's http client.
 // Default http client is http.DefaultClient{}.
 func WithHTTPClient(httpClient *http.Client) Option {
-	return func(c *Client) error {
+	return named("WithHTTPClient", func(c *Client) error {
 		if httpClient == nil {
 			return ErrNilHTTPClient
 		}
@@ -59,13 +323,13 @@ func WithHTTPClient(httpClient *http.Client) Option {
 		c.httpClient = httpClient
 
 		return nil
-	}
+	})
 }
 
 // WithMaxReqCount configures client's max request count.
 // Default maximum request count is 1.
 func WithMaxReqCount(maxReqCount int) Option {
-	return func(c *Client) error {
+	return named("WithMaxReqCount", func(c *Client) error {
 		if maxReqCount < 1 {
 			return ErrInvalidMaxReqCount
 		}
@@ -73,21 +337,21 @@ func WithMaxReqCount(maxReqCount int) Option {
 		c.maxReqCount = maxReqCount
 
 		return nil
-	}
+	})
 }
 
 // WithBackoff configures client's backoff duration, which represents sleeping intervals between retries.
 // Default backoff duration is 0.
 func WithBackoff(backoff time.Duration) Option {
-	return func(c *Client) error {
+	return named("WithBackoff", func(c *Client) error {
 		if backoff < 0 {
 			return ErrInvalidBackoff
 		}
 
-		c.backoff = backoff
+		c.backoff = ConstantBackoff(backoff)
 
 		return nil
-	}
+	})
 }
 
 // WithResHandler configures client's response handler function which handles http response.
@@ -106,7 +370,7 @@ func WithBackoff(backoff time.Duration) Option {
 //  	return nil
 //  }
 func WithResHandler(resHandler func(res *http.Response) error) Option {
-	return func(c *Client) error {
+	return named("WithResHandler", func(c *Client) error {
 		if resHandler == nil {
 			return ErrNilResHandler
 		}
@@ -114,46 +378,637 @@ func WithResHandler(resHandler func(res *http.Response) error) Option {
 		c.resHandler = resHandler
 
 		return nil
-	}
+	})
 }
 
-// NewClient creates and returns new retryable http client instance.
+// NewClient creates and returns new retryable http client instance. Every option is applied, and when some of them are invalid or conflict
+// with each other, an OptionsError listing all of them is returned, errors.Is and errors.As match each of its errors.
+// Background work of options starts once every option is applied successfully, see Close.
 func NewClient(opts ...Option) (*Client, error) {
-	c := &Client{
-		httpClient:  http.DefaultClient,
-		maxReqCount: defaultMaxReqCount,
-		backoff:     defaultBackoff,
-		resHandler:  defaultResHandler,
-	}
+	c := &Client{}
+	c.init()
 
-	for _, opt := range opts {
-		if err := opt(c); err != nil {
-			return nil, err
-		}
+	if err := applyOptions(c, opts); err != nil {
+		c.Close()
+		return nil, err
 	}
+	c.start()
 
 	return c, nil
 }
 
 // Do sends http request with automatic retries returns first successful or last unsuccessful response.
+// Last unsuccessful response is returned unread alongside the error and must be closed by the caller, unless client is configured with WithDiscardFailedResponse.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req, nil)
+}
+
+// do sends http request with automatic retries, recording attempts into provided report when it is not nil.
+func (c *Client) do(req *http.Request, report *Report) (*http.Response, error) {
+	return c.pipeline(req, report, nil)
+}
+
+// exchange holds hooks of a helper sending a logical request through client's pipeline, such as typed helpers.
+type exchange struct {
+	// rebuild returns the request of a retried attempt, retried attempts send a clone of the previous request when it is nil.
+	rebuild func() (*http.Request, error)
+	// consume is called with the response of each attempt receiving one and the error of handling it, it returns the error of the attempt.
+	// It runs within the retry loop, so that its failures, such as failures reading the body, are retried like failed attempts.
+	consume func(res *http.Response, err error) error
+}
+
+// pipeline sends http request with automatic retries through every stage of client, which are policies, mirroring, canary and endpoint routing,
+// redirects and url refreshes in the retry loop, response handling and transformers, and handling of failed responses.
+// Helpers provide an exchange to rebuild retried requests and consume responses within the retry loop, provided exchange may be nil.
+func (c *Client) pipeline(req *http.Request, report *Report, x *exchange) (*http.Response, error) {
+	if p, rule := c.policy(req.Method, req.URL); p != c {
+		if report != nil {
+			report.Policy = rule
+		}
+
+		return p.pipeline(req, report, x)
+	}
+	if c.mirrorTarget != nil {
+		c.mirror(req)
+	}
+
+	cl := getCall(req.Context(), report)
+	defer putCall(cl)
+	cl.canary = c.canary != nil && c.routeCanary(req)
+
 	var res *http.Response
-	var err error
-	for i := 0; i < c.maxReqCount; i++ {
-		res, err = c.httpClient.Do(req)
+	current := req
+	attempts, err := c.retry(cl, func() error {
+		attemptReq := current
+		if cl.attempt > 1 {
+			var err error
+			if x != nil && x.rebuild != nil && current == req {
+				attemptReq, err = x.rebuild()
+			} else {
+				attemptReq, err = cloneRequest(req.Context(), current)
+			}
+			if err != nil {
+				return Permanent(err)
+			}
+		}
+		attemptReq, err := c.route(cl, attemptReq)
+		if err != nil {
+			return err
+		}
+		if cl.endpoint != nil {
+			defer atomic.AddInt64(&cl.endpoint.inflight, -1)
+		}
 
+		res, err = c.send(cl, attemptReq)
+		if err == nil && c.redirectPolicy == RedirectInLoop {
+			next, err := c.redirect(cl, req, attemptReq, res)
+			if err != nil {
+				return Permanent(err)
+			}
+			if next != nil {
+				current = next
+				return nil
+			}
+		}
+		if err == nil && c.urlRefresh != nil {
+			next, err := c.refreshURL(cl, attemptReq, res)
+			if err != nil {
+				return Permanent(err)
+			}
+			if next != nil {
+				current = next
+				return nil
+			}
+		}
+		received := err == nil
 		if err == nil {
-			err = c.resHandler(res)
+			err = c.handle(cl, res)
+		}
+		if err == nil && c.resTransformers != nil {
+			res, err = c.transform(cl, res)
 		}
+		if received && res != nil && x != nil && x.consume != nil {
+			err = x.consume(res, err)
+		}
+		if c.recordRoute(cl, err) {
+			discard(res)
+		}
+
+		return err
+	})
+	if err == nil && (cl.redirected || cl.refreshed) {
+		res, err = nil, cl.ctx.Err()
+	}
+	if err != nil {
+		err = c.giveUp(cl, attempts, err)
+	}
+	if err != nil && c.discardFailedRes {
+		discard(res)
+		res = nil
+	}
+	if err != nil && c.errorHandler != nil {
+		return c.errorHandler(res, err, attempts)
+	}
+
+	return res, err
+}
+
+// route rewrites provided request of call's current attempt to client's canary when the call is routed to it, and to a balanced endpoint of
+// request's host when client has endpoints. The endpoint of the attempt is in flight until the caller decrements its in-flight count.
+func (c *Client) route(cl *call, req *http.Request) (*http.Request, error) {
+	if cl.canary {
+		req = c.canary.rewrite(req)
+	}
+	if c.endpoints != nil {
+		return c.balance(cl, req)
+	}
+
+	return req, nil
+}
+
+// recordRoute records provided error of call's current attempt in health of its endpoint and canary, it reports whether the attempt failed on
+// the canary and the call falls back to the primary.
+func (c *Client) recordRoute(cl *call, err error) bool {
+	if cl.endpoint != nil && cl.ctx.Err() == nil {
+		c.recordEndpoint(cl, err)
+	}
+	if cl.canary {
+		c.recordCanary(cl, err)
+		if err != nil && c.canary.cfg.Fallback && cl.ctx.Err() == nil {
+			cl.canary = false
+			cl.fellBack = true
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// call holds state of a logical request sent with automatic retries.
+type call struct {
+	ctx    context.Context
+	report *Report
+
+	attempt       int
+	maxReqCount   int
+	attemptReport *AttemptReport
+	req           *http.Request
+	res           *http.Response
+	requestIDs    []string
+	labels        map[string]string
+	retryOn       RetryCondition
+	trace         *TraceContext
+	start         time.Time
+	latency       time.Duration
+	release       func(cl *call)
+	// cancel cancels the context of current attempt.
+	cancel context.CancelFunc
+	// progress records progress of current attempt when it is traced, see attemptProgress.
+	progress *progress
+	// expected is set when current attempt is sent with Expect: 100-continue header.
+	expected       bool
+	expectRejected bool
+	expectFailed   bool
+	// hops counts redirects followed in the retry loop and redirected is set when current attempt is redirected.
+	hops       int
+	redirected bool
+	// refreshes counts url refreshes of the call and refreshed is set when the url of current attempt is refreshed.
+	refreshes int
+	refreshed bool
+	// canary is set when attempts of the call are routed to the canary and fellBack is set when current attempt failed on the canary.
+	canary   bool
+	fellBack bool
+	// endpoint is the endpoint current attempt is balanced to and localFailures counts failed attempts of the call sent to local endpoints.
+	endpoint      *endpoint
+	localFailures int
+	// pinned is the endpoint the next attempt is sent to and avoided is the endpoint the next attempt is not sent to, decided by failover policy.
+	// sameFailures counts consecutive failed attempts sent to pinned endpoint.
+	pinned       *endpoint
+	avoided      *endpoint
+	sameFailures int
+	// dnsFailed is set when an attempt of the call failed to resolve its host.
+	dnsFailed bool
+	// connectionRetries and responseRetries count failed attempts of the call without and with a response.
+	connectionRetries int
+	responseRetries   int
+	// retryAfter is the delay before next attempt hinted by error body of current attempt.
+	retryAfter time.Duration
+	// compressed holds compressed request body kept for retries.
+	compressed []byte
+}
+
+// newCall creates and returns new call state with provided context and report.
+func newCall(ctx context.Context, report *Report) *call {
+	return &call{
+		ctx:    ctx,
+		report: report,
+	}
+}
+
+// callPool holds call states of Do, so that sending a request does not allocate one.
+var callPool = sync.Pool{
+	New: func() interface{} {
+		return &call{}
+	},
+}
+
+// getCall returns a pooled call state with provided context and report.
+func getCall(ctx context.Context, report *Report) *call {
+	cl := callPool.Get().(*call)
+	cl.ctx = ctx
+	cl.report = report
+
+	return cl
+}
+
+// putCall resets provided call state and returns it to the pool, call state must not be used afterwards.
+func putCall(cl *call) {
+	*cl = call{}
+	callPool.Put(cl)
+}
+
+// progress holds progress of an attempt recorded by httptrace hooks and request bodies. The transport may run them after Do returns,
+// such as when a server responds before a request body is written, so progress is allocated per attempt instead of living in pooled call state.
+type progress struct {
+	// wrote is set atomically when request headers are written, phase is set atomically to phase progress and uploaded is set atomically
+	// when request body is read.
+	wrote    int32
+	phase    int32
+	uploaded int32
+}
+
+// attemptProgress returns progress of call's current attempt, allocating it on first use.
+func (cl *call) attemptProgress() *progress {
+	if cl.progress == nil {
+		cl.progress = &progress{}
+	}
+
+	return cl.progress
+}
+
+// written reports whether request headers of the attempt are written, it is false for untraced attempts.
+func (p *progress) written() bool {
+	return p != nil && atomic.LoadInt32(&p.wrote) == 1
+}
+
+// begin resets call's attempt state for provided attempt number.
+func (cl *call) begin(attempt int) {
+	cl.attempt = attempt
+	cl.attemptReport = cl.report.begin(attempt)
+	cl.req = nil
+	cl.res = nil
+	cl.latency = 0
+	cl.progress = nil
+	cl.expected = false
+	cl.expectRejected = false
+	cl.redirected = false
+	cl.refreshed = false
+	cl.fellBack = false
+	cl.endpoint = nil
+	cl.retryAfter = 0
+	cl.cancel = nil
+}
 
+// attemptHTTPClient returns the http client sending call's current attempt, a copy of client's http client when redirects or cookies are handled
+// by the client or when the attempt is dialed by the client.
+func (c *Client) attemptHTTPClient(cl *call) (*http.Client, error) {
+	httpClient := c.currentHTTPClient()
+	if c.dialRetry != nil || (cl.dnsFailed && c.fallbackResolver != nil) {
+		transport, err := c.attemptTransport(cl, httpClient.Transport)
 		if err != nil {
-			time.Sleep(c.backoff)
+			return nil, err
+		}
+		copied := *httpClient
+		copied.Transport = transport
+		httpClient = &copied
+	}
+	if c.redirectPolicy == RedirectFollow && c.redirectAuth == nil && c.cookieJar == nil {
+		return httpClient, nil
+	}
+
+	if c.redirectPolicy != RedirectFollow || c.redirectAuth != nil {
+		httpClient = c.redirectClient(httpClient)
+	}
+	if c.cookieJar != nil {
+		copied := *httpClient
+		copied.Jar = c.cookieJar
+		httpClient = &copied
+	}
+
+	return httpClient, nil
+}
+
+// send sends a single attempt of provided request using client's http client and records it into call state.
+func (c *Client) send(cl *call, req *http.Request) (*http.Response, error) {
+	cl.req = req
+	if c.maintenance != nil {
+		if err := c.admitMaintenance(req); err != nil {
+			return nil, err
+		}
+	}
+	if c.quota != nil {
+		if err := c.paceQuota(req); err != nil {
+			return nil, err
+		}
+	}
+	if c.compressor != nil {
+		var err error
+		if req, err = c.compress(cl, req); err != nil {
+			return nil, Permanent(err)
+		}
+	}
+	if c.limiter != nil || c.semaphore != nil {
+		if err := c.acquireSlot(cl, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.minAttemptTime > 0 {
+		if err := c.checkDeadline(req); err != nil {
+			return nil, err
+		}
+	}
 
-			continue
+	attemptReq := c.stamp(cl, c.attemptContext(cl, req))
+	if c.reqMutators != nil {
+		var err error
+		if attemptReq, err = c.mutate(attemptReq); err != nil {
+			cl.endAttempt()
+			return nil, err
+		}
+	}
+	if c.decompressors != nil {
+		attemptReq = c.acceptEncodings(attemptReq)
+	}
+	if c.profilerLabels {
+		attemptReq = c.labelAttempt(cl, attemptReq)
+	}
+	if cl.retryOn == RetryOnConnectionFailure {
+		attemptReq = traceWrite(cl.attemptProgress(), attemptReq)
+	}
+	if c.transportErrors {
+		attemptReq = tracePhases(cl.attemptProgress(), attemptReq)
+	}
+	if c.expectContinue {
+		attemptReq = c.expect(cl, attemptReq)
+	}
+
+	var recorder *timingRecorder
+	if cl.attemptReport != nil {
+		attemptReq, recorder = cl.attemptReport.trace(attemptReq, c.clock)
+	}
+
+	dump := c.debugDump(req.Context())
+	if dump != nil {
+		dump.request(attemptReq, cl.attempt, c.redactor)
+	}
+
+	cl.start = c.clock.Now()
+	if c.events != nil {
+		c.emitAttemptStarted(cl)
+	}
+
+	httpClient, err := c.attemptHTTPClient(cl)
+	if err != nil {
+		cl.endAttempt()
+		return nil, Permanent(err)
+	}
+
+	res, err := httpClient.Do(attemptReq)
+	cl.latency = c.since(cl.start)
+	cl.res = res
+	if cl.expected {
+		cl.checkExpectation(res)
+	}
+	if err != nil {
+		err = c.redactor.error(err)
+		if c.transportErrors {
+			err = cl.transportError(err)
+		}
+		err = c.classifyDNS(cl, err)
+	}
+	if c.transportErrors && res != nil && res.Body != nil && res.Body != http.NoBody {
+		res.Body = &phaseBody{ReadCloser: res.Body}
+	}
+	if cl.cancel != nil {
+		if res != nil && res.Body != nil {
+			res.Body = &cancelBody{ReadCloser: res.Body, cancel: cl.cancel}
+		} else {
+			cl.endAttempt()
 		}
+	}
 
-		break
+	if err == nil && c.decompressors != nil {
+		err = c.decompress(req, res)
+	}
+	if err == nil && c.maxResBytes > 0 {
+		err = c.limitResponse(res)
+	}
+	if err == nil && c.maintenance != nil {
+		c.observeMaintenance(req, res)
+	}
+	if err == nil && c.quota != nil {
+		c.observeQuota(req, res)
+	}
+
+	if recorder != nil {
+		recorder.wrapBody(res)
+	}
+
+	if dump != nil {
+		dump.response(res, err, cl.attempt, c.redactor)
 	}
 
 	return res, err
 }
+
+// handle runs client's response handler and response validator on provided response of call's current attempt.
+// Bodies of responses rejected by response handler are parsed for retry hints when client has an error body parser.
+func (c *Client) handle(cl *call, res *http.Response) error {
+	if c.redirectPolicy == RedirectAccept && res != nil && res.StatusCode >= 300 && res.StatusCode <= 399 {
+		return nil
+	}
+
+	return c.safely(func() error {
+		err := c.resHandlerFunc()(res)
+		if c.bodyRetry != nil {
+			err = c.inspectBody(res, err)
+		}
+		if err != nil {
+			if c.errorBodyParser != nil {
+				err = c.parseErrorBody(cl, res, err)
+			}
+
+			return err
+		}
+
+		if c.resValidator == nil {
+			return nil
+		}
+
+		return c.validate(res)
+	})
+}
+
+// retry calls provided function until it succeeds, returns a permanent error or maximum request count is reached, sleeping backoff duration between calls.
+// Attempts are recorded into provided call state. It returns call count and last error.
+func (c *Client) retry(cl *call, fn func() error) (int, error) {
+	c.init()
+
+	var start time.Time
+	if c.slowThreshold > 0 {
+		start = c.clock.Now()
+		if cl.report == nil {
+			cl.report = &Report{}
+		}
+	}
+
+	var err error
+	var totalBackoff time.Duration
+	i, extra := 0, 0
+	maxReqCount, backoffPolicy, jitter := c.retrySettings()
+	if noRetry(cl.ctx) {
+		maxReqCount = 1
+	} else if limit := attemptLimit(cl.ctx); limit > 0 && limit < maxReqCount {
+		maxReqCount = limit
+	}
+	cl.labels = LabelsFromContext(cl.ctx)
+	cl.retryOn = c.retryCondition()
+	if cond, ok := retryConditionFromContext(cl.ctx); ok {
+		cl.retryOn = cond
+	}
+	cl.maxReqCount = maxReqCount
+	for i < maxReqCount {
+		i++
+		cl.begin(i)
+
+		err = fn()
+		if c.profilerLabels {
+			c.unlabelAttempt(cl)
+		}
+		if cl.release != nil {
+			cl.release(cl)
+			cl.release = nil
+		}
+
+		if cl.redirected || cl.refreshed || cl.fellBack {
+			maxReqCount++
+		} else if cl.expectRejected && err != nil && extra < cl.maxReqCount-1 {
+			maxReqCount++
+			extra++
+		}
+
+		outcome := OutcomeRetry
+		if err == nil && cl.redirected {
+			outcome = OutcomeRedirect
+		} else if err == nil && cl.refreshed {
+			outcome = OutcomeURLRefresh
+		} else if err == nil {
+			outcome = OutcomeSuccess
+		} else if cl.fellBack {
+			outcome = OutcomeRetry
+		} else if IsPermanent(err) || i == maxReqCount || !c.retryable(cl) {
+			outcome = OutcomeFailure
+		}
+		if err != nil && !c.spendRetryBudget(cl, err) && outcome == OutcomeRetry {
+			outcome = OutcomeFailure
+		}
+		var backoff time.Duration
+		if outcome == OutcomeRetry {
+			var ok bool
+			if panicErr := c.safely(func() error {
+				backoff = backoffAfter(backoffPolicy, i, totalBackoff)
+				return nil
+			}); panicErr != nil {
+				err = panicErr
+				outcome = OutcomeFailure
+			} else if backoff, ok = c.capBackoff(c.hinted(cl, c.jittered(backoff, jitter)), totalBackoff); !ok || !c.allowRetry() {
+				outcome = OutcomeFailure
+			}
+			if (cl.expectRejected && cl.res.StatusCode == http.StatusExpectationFailed) || cl.fellBack {
+				backoff = 0
+			}
+		}
+		c.audit(cl, err, outcome)
+		cl.attemptReport.finish(cl, err, outcome)
+		c.recordHost(cl, err)
+		if c.slowThreshold > 0 {
+			c.checkSlowAttempt(cl)
+		}
+		if c.events != nil {
+			c.emitAttemptFinished(cl, err, outcome)
+			if outcome == OutcomeFailure {
+				c.emitGaveUp(cl, err)
+			} else if outcome == OutcomeRetry {
+				c.emitRetryScheduled(cl, backoff, err)
+			}
+		}
+
+		if outcome == OutcomeSuccess || outcome == OutcomeFailure {
+			break
+		}
+
+		cl.endAttempt()
+		c.sleep(cl.ctx, backoff)
+		cl.report.wait(backoff)
+		totalBackoff += backoff
+
+		if cl.ctx.Err() != nil {
+			break
+		}
+	}
+
+	if c.slowThreshold > 0 {
+		c.checkSlowRequest(cl, c.since(start))
+	}
+
+	return i, err
+}
+
+// giveUp returns the error returned when client gives up retrying provided call, context errors take precedence over last error
+// unless last error wraps them.
+func (c *Client) giveUp(cl *call, attempts int, err error) *GiveUpError {
+	if ctxErr := cl.ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		err = ctxErr
+	}
+
+	giveUpErr := &GiveUpError{Attempts: attempts, Err: err}
+	if cl.req != nil {
+		giveUpErr.Method = cl.req.Method
+		giveUpErr.URL = c.redactor.urlString(cl.req.URL.String())
+	}
+	if c.curlReproduction && cl.req != nil {
+		giveUpErr.Curl = curl(cl.req, c.redactor)
+	}
+	if len(cl.requestIDs) > 0 {
+		giveUpErr.RequestIDs = cl.requestIDs
+	}
+
+	return giveUpErr
+}
+
+// discard drains and closes provided response's body so that underlying connection can be reused.
+func discard(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxDiscardBytes))
+	res.Body.Close()
+}
+
+// cloneRequest clones provided request with provided context, request body is recreated by GetBody function when available.
+// The clone shares header and other reference fields with provided request, they are copied on write by stamp.
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.WithContext(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+
+	return clone, nil
+}
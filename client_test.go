@@ -1,8 +1,12 @@
 package retryablehttp
 
 import (
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -15,7 +19,7 @@ func TestNilHTTPClientOption(t *testing.T) {
 	if err == nil {
 		t.Error("unexpected nil error")
 	}
-	if err != ErrNilHTTPClient {
+	if !errors.Is(err, ErrNilHTTPClient) {
 		t.Errorf("unexpected error, %s", err)
 	}
 }
@@ -28,7 +32,7 @@ func TestInvalidMaxReqCountOption(t *testing.T) {
 	if err == nil {
 		t.Error("unexpected nil error")
 	}
-	if err != ErrInvalidMaxReqCount {
+	if !errors.Is(err, ErrInvalidMaxReqCount) {
 		t.Errorf("unexpected error, %s", err)
 	}
 }
@@ -41,7 +45,7 @@ func TestInvalidBackoffOption(t *testing.T) {
 	if err == nil {
 		t.Error("unexpected nil error")
 	}
-	if err != ErrInvalidBackoff {
+	if !errors.Is(err, ErrInvalidBackoff) {
 		t.Errorf("unexpected error, %s", err)
 	}
 }
@@ -54,7 +58,7 @@ func TestNilResponseHandler(t *testing.T) {
 	if err == nil {
 		t.Error("unexpected nil error")
 	}
-	if err != ErrNilResHandler {
+	if !errors.Is(err, ErrNilResHandler) {
 		t.Errorf("unexpected error, %s", err)
 	}
 }
@@ -232,3 +236,222 @@ func TestWithOptionsAndStatusBadRequest(t *testing.T) {
 		t.Error("unexpected duration")
 	}
 }
+
+// Do method of a zero value client should send request with default options.
+func TestZeroValueClient(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	var config struct {
+		Client Client
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := config.Client.Do(req)
+	if !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if res == nil || res.StatusCode != http.StatusServiceUnavailable {
+		t.Error("unexpected response")
+	}
+}
+
+// With method of a client should return a modified copy without modifying the client.
+func TestWith(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	derived, err := c.With(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("deriving client failed, %s", err.Error())
+	}
+	if derived.httpClient != c.httpClient {
+		t.Error("derived client does not share http client")
+	}
+
+	var optionsErr *OptionsError
+	if _, err := c.With(WithMaxReqCount(0)); !errors.Is(err, ErrInvalidMaxReqCount) || !errors.As(err, &optionsErr) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	for _, client := range []*Client{c, derived} {
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		client.Do(req)
+	}
+
+	if reqCount != 5 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// Do method of a client should not allocate beyond underlying http client when a single attempt succeeds.
+func TestDoAllocations(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return res, nil
+	})}
+
+	c, err := NewClient(
+		WithHTTPClient(httpClient),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	expected := testing.AllocsPerRun(100, func() {
+		httpClient.Do(req)
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Do(req)
+	})
+	if allocs > expected {
+		t.Errorf("unexpected allocation count, %v, http client allocates %v", allocs, expected)
+	}
+}
+
+// BenchmarkDo measures a single successful attempt sent by Do method of a client.
+func BenchmarkDo(b *testing.B) {
+	res := &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return res, nil
+		})}),
+	)
+	if err != nil {
+		b.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		b.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Do(req)
+	}
+}
+
+// BenchmarkDoWithReport measures a single successful attempt sent by DoWithReport method of a client.
+func BenchmarkDoWithReport(b *testing.B) {
+	res := &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return res, nil
+		})}),
+	)
+	if err != nil {
+		b.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		b.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.DoWithReport(req)
+	}
+}
+
+// Trace hooks of an attempt running after Do method returns should not modify state of following requests.
+func TestDoLateTraceHooks(t *testing.T) {
+	var late *httptrace.ClientTrace
+	attempts := 0
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if late == nil {
+			late = httptrace.ContextClientTrace(r.Context())
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}
+
+		// the transport of the first request writes its headers while the second request is sent
+		attempts++
+		late.WroteHeaders()
+
+		return nil, errors.New("connection refused")
+	})}
+
+	c, err := NewClient(
+		WithHTTPClient(httpClient),
+		WithMethodPolicy(map[string]Policy{
+			"GET": {MaxReqCount: 3, RetryOn: RetryOnConnectionFailure},
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		c.Do(req)
+	}
+
+	if attempts != 3 {
+		t.Errorf("unexpected attempt count, %d", attempts)
+	}
+}
+
+// Do method of a client should return *GiveUpError wrapping the last error with method, url and attempt count of the final attempt.
+func TestDoWrappedError(t *testing.T) {
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	_, err = c.Get("http://example.com/users?page=2")
+
+	var giveUpErr *GiveUpError
+	if !errors.As(err, &giveUpErr) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if giveUpErr.Method != http.MethodGet || giveUpErr.URL != "http://example.com/users?page=2" || giveUpErr.Attempts != 2 {
+		t.Errorf("unexpected give up error, %s, %s, %d", giveUpErr.Method, giveUpErr.URL, giveUpErr.Attempts)
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	if !errors.As(err, &urlErr) || !errors.As(err, &netErr) {
+		t.Errorf("http client errors are not wrapped, %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// clock errors
+var (
+	ErrNilClock   = errors.New("clock is nil")
+	ErrNilSleeper = errors.New("sleeper is nil")
+)
+
+// Clock represents a source of current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Sleeper represents a function which pauses current goroutine for provided duration.
+type Sleeper interface {
+	Sleep(d time.Duration)
+}
+
+// ContextSleeper represents a sleeper which stops pausing when provided context is done.
+// Sleepers implementing it are woken when request's context is cancelled during backoff.
+type ContextSleeper interface {
+	Sleeper
+	SleepContext(ctx context.Context, d time.Duration)
+}
+
+// systemClock is a clock which uses time package.
+type systemClock struct{}
+
+// Now returns current time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses current goroutine for provided duration.
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// SleepContext pauses current goroutine for provided duration or until provided context is done, using a pooled timer.
+func (systemClock) SleepContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	t := getTimer(d)
+	defer putTimer(t)
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// timerPool holds stopped timers.
+var timerPool sync.Pool
+
+// getTimer returns a pooled timer reset to provided duration.
+func getTimer(d time.Duration) *time.Timer {
+	if t, ok := timerPool.Get().(*time.Timer); ok {
+		t.Reset(d)
+		return t
+	}
+
+	return time.NewTimer(d)
+}
+
+// putTimer stops provided timer, drains its channel and returns it to the pool.
+func putTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+
+	timerPool.Put(t)
+}
+
+// WithClock configures client's clock which is used for timestamps, latencies and timing reports.
+// A fake clock together with a fake sleeper makes retry and backoff behavior testable without real waiting. Default clock uses time package.
+func WithClock(clock Clock) Option {
+	return named("WithClock", func(c *Client) error {
+		if clock == nil {
+			return ErrNilClock
+		}
+
+		c.clock = clock
+
+		return nil
+	})
+}
+
+// WithSleeper configures client's sleeper which is used for waiting backoff durations and keep-alive intervals, sleepers implementing ContextSleeper
+// are woken on cancellation.
+// Default sleeper uses pooled timers of time package and wakes when request's context is done.
+func WithSleeper(sleeper Sleeper) Option {
+	return named("WithSleeper", func(c *Client) error {
+		if sleeper == nil {
+			return ErrNilSleeper
+		}
+
+		c.sleeper = sleeper
+
+		return nil
+	})
+}
+
+// since returns duration elapsed since provided time according to client's clock.
+func (c *Client) since(t time.Time) time.Duration {
+	return c.clock.Now().Sub(t)
+}
+
+// sleep waits provided backoff duration with client's sleeper, stopping early when provided context is done and sleeper supports it.
+func (c *Client) sleep(ctx context.Context, d time.Duration) {
+	if s, ok := c.sleeper.(ContextSleeper); ok {
+		s.SleepContext(ctx, d)
+		return
+	}
+
+	c.sleeper.Sleep(d)
+}
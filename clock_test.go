@@ -0,0 +1,133 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose time advances only when it sleeps.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns fake current time.
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Sleep advances fake current time by provided duration without waiting.
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// NewClient function should return ErrNilClock and ErrNilSleeper when nil clock or sleeper is provided.
+func TestNilClockAndSleeperOptions(t *testing.T) {
+	_, err := NewClient(
+		WithClock(nil),
+	)
+	if !errors.Is(err, ErrNilClock) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	_, err = NewClient(
+		WithSleeper(nil),
+	)
+	if !errors.Is(err, ErrNilSleeper) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// DoWithReport method of a client with fake clock and sleeper should wait backoff durations on fake clock instantly.
+func TestFakeClockAndSleeper(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(time.Hour),
+		WithClock(clock),
+		WithSleeper(clock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	beginning := time.Now()
+	_, report, _ := c.DoWithReport(req)
+	if time.Since(beginning) > time.Minute {
+		t.Error("client waited on real clock")
+	}
+
+	if report.Elapsed != 2*time.Hour || report.TotalBackoff != 2*time.Hour {
+		t.Errorf("unexpected report, elapsed %s, total backoff %s", report.Elapsed, report.TotalBackoff)
+	}
+	for _, attempt := range report.Attempts {
+		if attempt.Latency != 0 {
+			t.Errorf("unexpected latency of attempt %d, %s", attempt.Attempt, attempt.Latency)
+		}
+	}
+}
+
+// Do method of a client with default sleeper should stop waiting backoff duration when request's context is cancelled.
+func TestBackoffCancellation(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(time.Minute),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	start := time.Now()
+	c.Do(req)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("backoff is not cancelled, %s", elapsed)
+	}
+}
+
+// getTimer function should return reset timers after putTimer stops them.
+func TestTimerPool(t *testing.T) {
+	timer := getTimer(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	putTimer(timer)
+
+	timer = getTimer(10 * time.Millisecond)
+	defer putTimer(timer)
+	select {
+	case <-timer.C:
+		t.Error("pooled timer fired early")
+	case <-time.After(5 * time.Millisecond):
+	}
+}
@@ -0,0 +1,197 @@
+package retryablehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// codec errors
+var (
+	ErrNilCodecRegistry        = errors.New("codec registry is nil")
+	ErrUnsupportedContentType  = errors.New("content type is not supported")
+	ErrUnsupportedFormValue    = errors.New("form value type is not supported")
+	ErrInvalidAccept           = errors.New("accepted content types are not valid")
+	ErrUnacceptableContentType = errors.New("response content type is not accepted")
+)
+
+// content types
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// Codec represents an encoding of request and response bodies for a content type.
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes and decodes JSON bodies.
+type JSONCodec struct{}
+
+// ContentType returns application/json.
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+// Marshal encodes provided value as JSON.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into provided value.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// FormCodec encodes and decodes url encoded form bodies.
+// Supported values are url.Values, map[string][]string and map[string]string, and pointers to them for decoding.
+type FormCodec struct{}
+
+// ContentType returns application/x-www-form-urlencoded.
+func (FormCodec) ContentType() string {
+	return ContentTypeForm
+}
+
+// Marshal encodes provided value as url encoded form.
+func (FormCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for key, value := range v {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	default:
+		return nil, fmt.Errorf("%w, %T", ErrUnsupportedFormValue, v)
+	}
+}
+
+// Unmarshal decodes url encoded form data into provided value.
+func (FormCodec) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		*v = make(map[string]string, len(values))
+		for key := range values {
+			(*v)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("%w, %T", ErrUnsupportedFormValue, v)
+	}
+
+	return nil
+}
+
+// CodecRegistry represents a set of codecs keyed by content type, it is safe for concurrent use.
+type CodecRegistry struct {
+	mu           sync.RWMutex
+	codecs       map[string]Codec
+	contentTypes []string
+}
+
+// NewCodecRegistry creates and returns new codec registry with provided codecs.
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	r := &CodecRegistry{
+		codecs: make(map[string]Codec),
+	}
+
+	for _, codec := range codecs {
+		r.Register(codec)
+	}
+
+	return r
+}
+
+// DefaultCodecs is the codec registry used by clients unless configured otherwise, it contains JSON and form codecs.
+var DefaultCodecs = NewCodecRegistry(JSONCodec{}, FormCodec{})
+
+// Register registers provided codec for its content type, replacing any codec previously registered for the same content type.
+func (r *CodecRegistry) Register(codec Codec) {
+	r.RegisterAs(codec.ContentType(), codec)
+}
+
+// RegisterAs registers provided codec for provided content type, which is useful for registering a codec under alias content types.
+func (r *CodecRegistry) RegisterAs(contentType string, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contentType = mediaType(contentType)
+	if _, ok := r.codecs[contentType]; !ok {
+		r.contentTypes = append(r.contentTypes, contentType)
+	}
+	r.codecs[contentType] = codec
+}
+
+// Lookup returns codec registered for provided content type, content type parameters are ignored.
+// Structured syntax suffixes such as application/problem+json fall back to codec of their base type.
+func (r *CodecRegistry) Lookup(contentType string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contentType = mediaType(contentType)
+	if codec, ok := r.codecs[contentType]; ok {
+		return codec, true
+	}
+
+	if i := strings.LastIndex(contentType, "+"); i >= 0 {
+		if slash := strings.Index(contentType, "/"); slash >= 0 {
+			codec, ok := r.codecs[contentType[:slash+1]+contentType[i+1:]]
+			return codec, ok
+		}
+	}
+
+	return nil, false
+}
+
+// ContentTypes returns registered content types in registration order.
+func (r *CodecRegistry) ContentTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contentTypes := make([]string, len(r.contentTypes))
+	copy(contentTypes, r.contentTypes)
+
+	return contentTypes
+}
+
+// WithCodecRegistry configures client's codec registry used by typed helpers.
+// Default codec registry is DefaultCodecs.
+func WithCodecRegistry(codecs *CodecRegistry) Option {
+	return named("WithCodecRegistry", func(c *Client) error {
+		if codecs == nil {
+			return ErrNilCodecRegistry
+		}
+
+		c.codecs = codecs
+
+		return nil
+	})
+}
+
+// mediaType returns lower cased media type of provided content type without parameters.
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
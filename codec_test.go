@@ -0,0 +1,44 @@
+package retryablehttp
+
+import (
+	"net/url"
+	"testing"
+)
+
+// Lookup method of codec registry should ignore content type parameters and fall back to base type of structured syntax suffixes.
+func TestCodecRegistryLookup(t *testing.T) {
+	r := NewCodecRegistry(JSONCodec{}, FormCodec{})
+
+	for _, contentType := range []string{"application/json; charset=utf-8", "application/problem+json", "APPLICATION/JSON"} {
+		codec, ok := r.Lookup(contentType)
+		if !ok || codec.ContentType() != ContentTypeJSON {
+			t.Errorf("unexpected codec for %s", contentType)
+		}
+	}
+
+	if _, ok := r.Lookup("application/xml"); ok {
+		t.Error("unexpected codec for application/xml")
+	}
+}
+
+// FormCodec should encode and decode url encoded forms.
+func TestFormCodec(t *testing.T) {
+	codec := FormCodec{}
+
+	data, err := codec.Marshal(map[string]string{"name": "gopher"})
+	if err != nil {
+		t.Errorf("encoding form failed, %s", err.Error())
+	}
+
+	var values url.Values
+	if err := codec.Unmarshal(data, &values); err != nil {
+		t.Errorf("decoding form failed, %s", err.Error())
+	}
+	if values.Get("name") != "gopher" {
+		t.Errorf("unexpected form values, %v", values)
+	}
+
+	if _, err := codec.Marshal(1); err == nil {
+		t.Error("unexpected nil error")
+	}
+}
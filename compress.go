@@ -0,0 +1,277 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compression errors
+var (
+	ErrNilCompressor          = errors.New("compressor is nil")
+	ErrInvalidCompressionSize = errors.New("compression size must not be negative")
+	ErrNilDecompressor        = errors.New("decompressor is nil")
+	ErrCorruptBody            = errors.New("response body is corrupt")
+)
+
+// content encodings
+const (
+	ContentEncodingGzip = "gzip"
+)
+
+// Compressor represents a content coding of request bodies.
+type Compressor interface {
+	ContentEncoding() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// Decompressor represents a content coding of response bodies.
+type Decompressor interface {
+	ContentEncoding() string
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCompressor compresses request bodies and decompresses response bodies with gzip.
+type GzipCompressor struct{}
+
+// ContentEncoding returns gzip.
+func (GzipCompressor) ContentEncoding() string {
+	return ContentEncodingGzip
+}
+
+// NewWriter returns a gzip writer compressing into provided writer with default compression level.
+func (GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// NewReader returns a gzip reader decompressing provided reader.
+func (GzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// WithRequestCompression configures client to compress replayable request bodies, bodies of requests with GetBody, of at least provided size
+// or of unknown size with provided compressor and to set Content-Encoding header. Bodies are compressed once per request, the compressed form is kept for retries.
+// Requests which already have a Content-Encoding header are sent as they are. GzipCompressor uses gzip, the zstd package provides zstd compression.
+func WithRequestCompression(compressor Compressor, minSize int64) Option {
+	return named("WithRequestCompression", func(c *Client) error {
+		if compressor == nil {
+			return ErrNilCompressor
+		}
+		if minSize < 0 {
+			return ErrInvalidCompressionSize
+		}
+
+		c.compressor = compressor
+		c.compressMinSize = minSize
+
+		return nil
+	})
+}
+
+// compress returns a copy of provided request with compressed body, it returns provided request when its body is not compressed.
+// Body is compressed in the first attempt and the compressed form is kept in call state for following attempts.
+func (c *Client) compress(cl *call, req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil || req.Header.Get("Content-Encoding") != "" ||
+		(req.ContentLength >= 0 && req.ContentLength < c.compressMinSize) {
+		return req, nil
+	}
+
+	if cl.compressed == nil {
+		var buf bytes.Buffer
+		w, err := c.compressor.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(w, req.Body); err != nil {
+			w.Close()
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		cl.compressed = buf.Bytes()
+	}
+	req.Body.Close()
+
+	compressed := req.WithContext(req.Context())
+	compressed.Header = req.Header.Clone()
+	compressed.Header.Set("Content-Encoding", c.compressor.ContentEncoding())
+	compressed.Header.Del("Content-Length")
+	compressed.GetBody = bytesBody(cl.compressed)
+	compressed.Body, _ = compressed.GetBody()
+	compressed.ContentLength = int64(len(cl.compressed))
+
+	return compressed, nil
+}
+
+// WithResponseDecompression configures client to advertise content codings of provided decompressors in Accept-Encoding header of attempts,
+// in provided order, and to decompress response bodies encoded with them transparently. Requests which already have an Accept-Encoding header
+// are sent as they are. Since the header is set by the client, the transport does not decompress gzip itself, GzipCompressor should be provided to accept gzip.
+// Failures of decompression are wrapped with ErrCorruptBody and retried. The zstd and brotli packages provide zstd and brotli decompression.
+// Corrupt or truncated streams detected while the caller reads the body of an idempotent request, such as gzip checksum errors and unexpected EOFs,
+// are recovered by fetching the response again, up to maximum request count minus one times. Bytes already read are skipped in the new response
+// after verifying that they are identical, otherwise the read fails with ErrCorruptBody.
+func WithResponseDecompression(decompressors ...Decompressor) Option {
+	return named("WithResponseDecompression", func(c *Client) error {
+		if len(decompressors) == 0 {
+			return ErrNilDecompressor
+		}
+
+		byEncoding := make(map[string]Decompressor, len(decompressors))
+		encodings := make([]string, 0, len(decompressors))
+		for _, d := range decompressors {
+			if d == nil {
+				return ErrNilDecompressor
+			}
+
+			encoding := strings.ToLower(d.ContentEncoding())
+			if _, ok := byEncoding[encoding]; !ok {
+				encodings = append(encodings, encoding)
+			}
+			byEncoding[encoding] = d
+		}
+
+		c.decompressors = byEncoding
+		c.acceptEncoding = strings.Join(encodings, ", ")
+
+		return nil
+	})
+}
+
+// acceptEncodings returns a copy of provided request with client's Accept-Encoding header, it returns provided request when the header is already set.
+func (c *Client) acceptEncodings(req *http.Request) *http.Request {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return req
+	}
+
+	accepting := req.WithContext(req.Context())
+	accepting.Header = req.Header.Clone()
+	if accepting.Header == nil {
+		accepting.Header = make(http.Header)
+	}
+	accepting.Header.Set("Accept-Encoding", c.acceptEncoding)
+
+	return accepting
+}
+
+// decompress replaces body of provided response of provided request, encoded with one of client's content codings, with a decompressing body.
+// Content-Encoding and Content-Length headers of a decompressed response are removed.
+func (c *Client) decompress(req *http.Request, res *http.Response) error {
+	d, ok := c.decompressors[strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))]
+	if !ok || res.Body == nil || res.Body == http.NoBody || res.ContentLength == 0 || (res.Request != nil && res.Request.Method == http.MethodHead) {
+		return nil
+	}
+
+	r, err := d.NewReader(res.Body)
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrCorruptBody, err)
+	}
+
+	body := &decompressedBody{r: r, body: res.Body}
+	if idempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) && req.Context().Value(refetchKey{}) == nil {
+		maxReqCount, _, _ := c.retrySettings()
+		body.refetch = c.refetch(req)
+		body.refetches = maxReqCount - 1
+	}
+	res.Body = body
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+
+	return nil
+}
+
+// refetchKey is the context key marking requests which fetch a response again after a decompression failure.
+type refetchKey struct{}
+
+// refetch returns a function which sends provided request again and returns its decompressed body, the body does not recover itself.
+func (c *Client) refetch(req *http.Request) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		clone, err := cloneRequest(context.WithValue(req.Context(), refetchKey{}, true), req)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.Do(clone)
+		if err != nil {
+			discard(res)
+			return nil, err
+		}
+
+		return res.Body, nil
+	}
+}
+
+// decompressedBody is a response body read through a decompressor, failures of decompression are wrapped with ErrCorruptBody.
+// Bodies with a refetch function recover from failures by reading the rest of the body from a new response.
+type decompressedBody struct {
+	r    io.ReadCloser
+	body io.ReadCloser
+
+	refetch   func() (io.ReadCloser, error)
+	refetches int
+	// read and sum hold the number and the checksum of bytes already read.
+	read int64
+	sum  uint32
+}
+
+// Read reads decompressed bytes, recovering from failures when possible.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.r.Read(p)
+		if b.refetch != nil {
+			b.read += int64(n)
+			b.sum = crc32.Update(b.sum, crc32.IEEETable, p[:n])
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		if !b.recover() {
+			return n, fmt.Errorf("%w, %v", ErrCorruptBody, err)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// recover replaces the decompressor with the body of a new response positioned after the bytes already read, it reports whether it succeeded.
+func (b *decompressedBody) recover() bool {
+	if b.refetch == nil || b.refetches == 0 {
+		return false
+	}
+	b.refetches--
+
+	body, err := b.refetch()
+	if err != nil {
+		return false
+	}
+
+	h := crc32.NewIEEE()
+	if _, err := io.CopyN(h, body, b.read); err != nil || h.Sum32() != b.sum {
+		body.Close()
+		return false
+	}
+
+	b.r.Close()
+	b.body.Close()
+	b.r = body
+	b.body = http.NoBody
+
+	return true
+}
+
+// Close closes decompressor and underlying body.
+func (b *decompressedBody) Close() error {
+	b.r.Close()
+
+	return b.body.Close()
+}
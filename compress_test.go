@@ -0,0 +1,198 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingCompressor is a gzip compressor which counts created writers.
+type countingCompressor struct {
+	GzipCompressor
+	count int32
+}
+
+// NewWriter counts and returns a gzip writer.
+func (c *countingCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	atomic.AddInt32(&c.count, 1)
+	return c.GzipCompressor.NewWriter(w)
+}
+
+// NewClient function should return ErrNilCompressor and ErrInvalidCompressionSize when invalid compression options are provided.
+func TestInvalidRequestCompressionOptions(t *testing.T) {
+	if _, err := NewClient(WithRequestCompression(nil, 0)); !errors.Is(err, ErrNilCompressor) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithRequestCompression(GzipCompressor{}, -1)); !errors.Is(err, ErrInvalidCompressionSize) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with request compression should compress bodies once and send the compressed form in every attempt.
+func TestRequestCompression(t *testing.T) {
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("unexpected content encoding, %q", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("reading gzip body failed, %s", err.Error())
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != strings.Repeat("body", 100) {
+			t.Errorf("unexpected body, %q", body)
+		}
+		if atomic.AddInt32(&reqCount, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	compressor := &countingCompressor{}
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithRequestCompression(compressor, 100),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Post(s.URL, "text/plain", strings.NewReader(strings.Repeat("body", 100)))
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	res.Body.Close()
+	if reqCount != 2 || compressor.count != 1 {
+		t.Errorf("unexpected counts, %d, %d", reqCount, compressor.count)
+	}
+
+	s.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("small body is compressed")
+		}
+	})
+	res, err = c.Post(s.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	res.Body.Close()
+}
+
+// NewClient function should return ErrNilDecompressor when no decompressor or a nil decompressor is provided.
+func TestInvalidResponseDecompressionOptions(t *testing.T) {
+	for _, opt := range []Option{WithResponseDecompression(), WithResponseDecompression(GzipCompressor{}, nil)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrNilDecompressor) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Do method of a client with response decompression should decompress responses and retry responses whose compressed stream cannot be opened.
+func TestResponseDecompression(t *testing.T) {
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("unexpected accept encoding, %q", r.Header.Get("Accept-Encoding"))
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		if atomic.AddInt32(&reqCount, 1) == 1 {
+			w.Write([]byte("not gzip"))
+			return
+		}
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("body"))
+		zw.Close()
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithResponseDecompression(GzipCompressor{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil || string(body) != "body" || res.Header.Get("Content-Encoding") != "" {
+		t.Errorf("unexpected response, %q, %v", body, err)
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// Body of a decompressed response of an idempotent request should recover from truncated streams by fetching the response again.
+func TestDecompressionIntegrity(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(strings.Repeat("body", 1000)))
+	zw.Close()
+	compressed := buf.Bytes()
+
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		if atomic.AddInt32(&reqCount, 1) == 1 {
+			w.Write(compressed[:len(compressed)-10])
+			return
+		}
+		w.Write(compressed)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithResponseDecompression(GzipCompressor{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		atomic.StoreInt32(&reqCount, 0)
+		req, err := http.NewRequest(method, s.URL, nil)
+		if err != nil {
+			t.Errorf("creating request failed, %s", err.Error())
+		}
+
+		res, err := c.Do(req)
+		if err != nil {
+			t.Errorf("sending request failed, %s", err.Error())
+			continue
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if method == http.MethodPost {
+			if !errors.Is(err, ErrCorruptBody) || reqCount != 1 {
+				t.Errorf("unexpected error, %v, %d", err, reqCount)
+			}
+			continue
+		}
+		if err != nil || string(body) != strings.Repeat("body", 1000) || reqCount != 2 {
+			t.Errorf("unexpected body, %d, %v, %d", len(body), err, reqCount)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package retryablehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// config errors
+var (
+	ErrInvalidTimeout    = errors.New("timeout is not valid")
+	ErrInvalidStatusCode = errors.New("status code is not valid")
+)
+
+// Config represents client configuration as a plain struct, it is an alternative to functional options.
+// Zero values mean defaults. Timeout is the timeout of each attempt and creates a new underlying http client when it is set.
+// When RetryableStatusCodes is set, unsuccessful responses with other status codes are not retried.
+type Config struct {
+	MaxReqCount          int
+	Backoff              time.Duration
+	Jitter               float64
+	Timeout              time.Duration
+	RetryableStatusCodes []int
+}
+
+// ConfigError represents all problems of a configuration.
+type ConfigError struct {
+	Errs []error
+}
+
+// Error returns joined error messages.
+func (e *ConfigError) Error() string {
+	messages := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		messages[i] = err.Error()
+	}
+
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether any problem of configuration matches provided target.
+func (e *ConfigError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate returns *ConfigError reporting all problems of configuration at once, it returns nil when configuration is valid.
+func (cfg Config) Validate() error {
+	var errs []error
+	if cfg.MaxReqCount < 0 {
+		errs = append(errs, ErrInvalidMaxReqCount)
+	}
+	if cfg.Backoff < 0 {
+		errs = append(errs, ErrInvalidBackoff)
+	}
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		errs = append(errs, ErrInvalidJitter)
+	}
+	if cfg.Timeout < 0 {
+		errs = append(errs, ErrInvalidTimeout)
+	}
+	for _, statusCode := range cfg.RetryableStatusCodes {
+		if statusCode < 100 || statusCode > 599 {
+			errs = append(errs, fmt.Errorf("%w, %d", ErrInvalidStatusCode, statusCode))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ConfigError{Errs: errs}
+	}
+
+	return nil
+}
+
+// NewClientFromConfig validates provided configuration and creates a client from it, provided options are applied after configuration.
+func NewClientFromConfig(cfg Config, opts ...Option) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return NewClient(append(cfg.options(), opts...)...)
+}
+
+// options converts configuration to options.
+func (cfg Config) options() []Option {
+	var opts []Option
+	if cfg.MaxReqCount > 0 {
+		opts = append(opts, WithMaxReqCount(cfg.MaxReqCount))
+	}
+	if cfg.Backoff > 0 {
+		opts = append(opts, WithBackoff(cfg.Backoff))
+	}
+	if cfg.Jitter > 0 {
+		opts = append(opts, WithJitter(cfg.Jitter))
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithHTTPClient(&http.Client{Timeout: cfg.Timeout}))
+	}
+	if len(cfg.RetryableStatusCodes) > 0 {
+		opts = append(opts, WithResHandler(statusCodeHandler(cfg.RetryableStatusCodes)))
+	}
+
+	return opts
+}
+
+// statusCodeHandler returns a response handler which accepts successful responses and retries unsuccessful responses only with provided status codes.
+func statusCodeHandler(retryableStatusCodes []int) func(res *http.Response) error {
+	retryable := make(map[int]bool, len(retryableStatusCodes))
+	for _, statusCode := range retryableStatusCodes {
+		retryable[statusCode] = true
+	}
+
+	return func(res *http.Response) error {
+		if err := defaultResHandler(res); err != nil {
+			if res != nil && !retryable[res.StatusCode] {
+				return Permanent(err)
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,295 @@
+// Package config loads retryablehttp client settings from JSON or YAML documents.
+//
+// A document looks like:
+//
+//	maxReqCount: 3
+//	backoff:
+//	  strategy: exponential
+//	  initial: 100ms
+//	  max: 5s
+//	  multiplier: 2
+//	  jitter: 0.2
+//	timeout: 2s
+//	retryableStatusCodes: [429, 503]
+//	hosts:
+//	  payments.example.com:
+//	    maxReqCount: 5
+//
+// Omitted settings take default values, host settings override omitted settings with document settings.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ermanimer/retryablehttp"
+	"github.com/ermanimer/retryablehttp/backoff"
+	"gopkg.in/yaml.v3"
+)
+
+// config errors
+var (
+	ErrInvalidDuration        = errors.New("duration is not valid")
+	ErrUnknownBackoffStrategy = errors.New("backoff strategy is unknown")
+	ErrInvalidMultiplier      = errors.New("backoff multiplier must be at least 1")
+	ErrUnknownFormat          = errors.New("config file format is unknown")
+	ErrEmptyHost              = errors.New("host is empty")
+)
+
+// backoff strategies
+const (
+	StrategyConstant    = "constant"
+	StrategyExponential = "exponential"
+)
+
+// defaultMultiplier is the default multiplier of exponential backoff strategy.
+const defaultMultiplier = 2
+
+// Duration represents a duration written as a string such as "100ms" or as a number of nanoseconds.
+type Duration time.Duration
+
+// UnmarshalJSON decodes provided duration string or number.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(v)
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%w, %s", ErrInvalidDuration, v)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("%w, %s", ErrInvalidDuration, data)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes duration as a duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Backoff represents a backoff strategy and its parameters.
+// Constant strategy waits Duration after every attempt. Exponential strategy waits Initial after first attempt and multiplies it by Multiplier after each attempt, up to Max when it is set.
+type Backoff struct {
+	Strategy   string   `json:"strategy,omitempty"`
+	Duration   Duration `json:"duration,omitempty"`
+	Initial    Duration `json:"initial,omitempty"`
+	Max        Duration `json:"max,omitempty"`
+	Multiplier float64  `json:"multiplier,omitempty"`
+	Jitter     float64  `json:"jitter,omitempty"`
+}
+
+// Settings represents settings of a client.
+type Settings struct {
+	MaxReqCount          int      `json:"maxReqCount,omitempty"`
+	Backoff              Backoff  `json:"backoff,omitempty"`
+	Timeout              Duration `json:"timeout,omitempty"`
+	RetryableStatusCodes []int    `json:"retryableStatusCodes,omitempty"`
+}
+
+// Config represents a configuration document, Hosts holds settings overriding document settings for requests to a host.
+type Config struct {
+	Settings
+	Hosts map[string]Settings `json:"hosts,omitempty"`
+}
+
+// ParseJSON parses and validates provided JSON document, unknown fields are rejected.
+func ParseJSON(data []byte) (*Config, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+
+	cfg := &Config{}
+	if err := d.Decode(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ParseYAML parses and validates provided YAML document, unknown fields are rejected.
+func ParseYAML(data []byte) (*Config, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJSON(data)
+}
+
+// Load reads and parses configuration file at provided path, format is chosen by file extension: .json, .yaml or .yml.
+func Load(path string) (*Config, error) {
+	var parse func(data []byte) (*Config, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		parse = ParseJSON
+	case ".yaml", ".yml":
+		parse = ParseYAML
+	default:
+		return nil, fmt.Errorf("%w, %s", ErrUnknownFormat, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(data)
+}
+
+// Validate returns *retryablehttp.ConfigError reporting all problems of document and host settings at once, it returns nil when document is valid.
+func (cfg *Config) Validate() error {
+	errs := cfg.Settings.problems("")
+	for host, settings := range cfg.Hosts {
+		if host == "" {
+			errs = append(errs, ErrEmptyHost)
+		}
+		errs = append(errs, settings.problems(host+": ")...)
+	}
+
+	if len(errs) > 0 {
+		return &retryablehttp.ConfigError{Errs: errs}
+	}
+
+	return nil
+}
+
+// problems returns all problems of settings with provided prefix.
+func (s Settings) problems(prefix string) []error {
+	var errs []error
+	if err := s.Backoff.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := s.config().Validate(); err != nil {
+		var configErr *retryablehttp.ConfigError
+		if errors.As(err, &configErr) {
+			errs = append(errs, configErr.Errs...)
+		}
+	}
+
+	if prefix == "" {
+		return errs
+	}
+
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s%w", prefix, err)
+	}
+
+	return errs
+}
+
+// validate returns an error when backoff strategy or its parameters are not valid.
+func (b Backoff) validate() error {
+	switch b.Strategy {
+	case "", StrategyConstant:
+		if b.Duration < 0 {
+			return retryablehttp.ErrInvalidBackoff
+		}
+	case StrategyExponential:
+		if b.Initial < 0 || b.Max < 0 {
+			return retryablehttp.ErrInvalidBackoff
+		}
+		if b.Multiplier != 0 && b.Multiplier < 1 {
+			return ErrInvalidMultiplier
+		}
+	default:
+		return fmt.Errorf("%w, %s", ErrUnknownBackoffStrategy, b.Strategy)
+	}
+
+	return nil
+}
+
+// config converts settings to client configuration.
+func (s Settings) config() retryablehttp.Config {
+	cfg := retryablehttp.Config{
+		MaxReqCount:          s.MaxReqCount,
+		Jitter:               s.Backoff.Jitter,
+		Timeout:              time.Duration(s.Timeout),
+		RetryableStatusCodes: s.RetryableStatusCodes,
+	}
+	if s.Backoff.Strategy == "" || s.Backoff.Strategy == StrategyConstant {
+		cfg.Backoff = time.Duration(s.Backoff.Duration)
+	}
+
+	return cfg
+}
+
+// merge returns provided host settings with omitted settings taken from document settings.
+func (cfg *Config) merge(host Settings) Settings {
+	if host.MaxReqCount == 0 {
+		host.MaxReqCount = cfg.MaxReqCount
+	}
+	if host.Backoff == (Backoff{}) {
+		host.Backoff = cfg.Backoff
+	}
+	if host.Timeout == 0 {
+		host.Timeout = cfg.Timeout
+	}
+	if host.RetryableStatusCodes == nil {
+		host.RetryableStatusCodes = cfg.RetryableStatusCodes
+	}
+
+	return host
+}
+
+// NewClient creates a client with document settings, provided options are applied after settings.
+func (cfg *Config) NewClient(opts ...retryablehttp.Option) (*retryablehttp.Client, error) {
+	return newClient(cfg.Settings, opts)
+}
+
+// NewHostClient creates a client with settings of provided host, falling back to document settings. Provided options are applied after settings.
+func (cfg *Config) NewHostClient(host string, opts ...retryablehttp.Option) (*retryablehttp.Client, error) {
+	settings, ok := cfg.Hosts[host]
+	if !ok {
+		return cfg.NewClient(opts...)
+	}
+
+	return newClient(cfg.merge(settings), opts)
+}
+
+// newClient creates a client with provided settings and options.
+func newClient(s Settings, opts []retryablehttp.Option) (*retryablehttp.Client, error) {
+	if err := s.Backoff.validate(); err != nil {
+		return nil, err
+	}
+
+	if s.Backoff.Strategy == StrategyExponential {
+		multiplier := s.Backoff.Multiplier
+		if multiplier == 0 {
+			multiplier = defaultMultiplier
+		}
+		policy := backoff.Exponential(time.Duration(s.Backoff.Initial), multiplier)
+		if s.Backoff.Max > 0 {
+			policy = backoff.WithCap(policy, time.Duration(s.Backoff.Max))
+		}
+		opts = append([]retryablehttp.Option{retryablehttp.WithBackoffPolicy(policy)}, opts...)
+	}
+
+	return retryablehttp.NewClientFromConfig(s.config(), opts...)
+}
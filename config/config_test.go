@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ermanimer/retryablehttp"
+)
+
+const yamlDocument = `
+maxReqCount: 3
+backoff:
+  strategy: exponential
+  initial: 1ms
+  max: 3ms
+timeout: 2s
+retryableStatusCodes: [503]
+hosts:
+  payments.example.com:
+    maxReqCount: 5
+`
+
+const jsonDocument = `{
+	"maxReqCount": 3,
+	"backoff": {"strategy": "exponential", "initial": "1ms", "max": "3ms"},
+	"timeout": "2s",
+	"retryableStatusCodes": [503],
+	"hosts": {"payments.example.com": {"maxReqCount": 5}}
+}`
+
+// ParseYAML and ParseJSON functions should parse equivalent documents into the same configuration.
+func TestParse(t *testing.T) {
+	fromYAML, err := ParseYAML([]byte(yamlDocument))
+	if err != nil {
+		t.Fatalf("parsing yaml failed, %s", err.Error())
+	}
+
+	fromJSON, err := ParseJSON([]byte(jsonDocument))
+	if err != nil {
+		t.Fatalf("parsing json failed, %s", err.Error())
+	}
+
+	for _, cfg := range []*Config{fromYAML, fromJSON} {
+		if cfg.MaxReqCount != 3 || cfg.Backoff.Strategy != StrategyExponential || cfg.Backoff.Max != Duration(3*time.Millisecond) || cfg.Timeout != Duration(2*time.Second) {
+			t.Errorf("unexpected config, %+v", cfg.Settings)
+		}
+		if host := cfg.merge(cfg.Hosts["payments.example.com"]); host.MaxReqCount != 5 || host.Timeout != cfg.Timeout {
+			t.Errorf("unexpected host settings, %+v", host)
+		}
+	}
+
+	if _, err := ParseYAML([]byte("maxReqCount: 3\nunknown: true\n")); err == nil {
+		t.Error("unexpected nil error for unknown field")
+	}
+	if cfg, err := ParseYAML(nil); err != nil || cfg.MaxReqCount != 0 {
+		t.Errorf("unexpected result for empty document, %v", err)
+	}
+}
+
+// Validate method of a configuration should report all problems of document and host settings at once.
+func TestValidate(t *testing.T) {
+	_, err := ParseYAML([]byte(`
+maxReqCount: -1
+backoff:
+  strategy: linear
+timeout: -1s
+hosts:
+  api.example.com:
+    backoff:
+      strategy: exponential
+      multiplier: 0.5
+    retryableStatusCodes: [42]
+`))
+
+	var configErr *retryablehttp.ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errs) != 5 {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	for _, target := range []error{retryablehttp.ErrInvalidMaxReqCount, ErrUnknownBackoffStrategy, retryablehttp.ErrInvalidTimeout, ErrInvalidMultiplier, retryablehttp.ErrInvalidStatusCode} {
+		if !errors.Is(err, target) {
+			t.Errorf("missing error, %v", target)
+		}
+	}
+}
+
+// Load function should choose format by file extension.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, document := range map[string]string{"client.yaml": yamlDocument, "client.json": jsonDocument} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(document), 0o600); err != nil {
+			t.Fatalf("writing config file failed, %s", err.Error())
+		}
+
+		cfg, err := Load(path)
+		if err != nil || cfg.MaxReqCount != 3 {
+			t.Errorf("loading %s failed, %v", name, err)
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "client.toml")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// NewClient and NewHostClient methods of a configuration should create clients with document and host settings.
+func TestNewClient(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	cfg, err := ParseYAML([]byte(yamlDocument))
+	if err != nil {
+		t.Fatalf("parsing yaml failed, %s", err.Error())
+	}
+
+	c, err := cfg.NewClient()
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+	hostClient, err := cfg.NewHostClient("payments.example.com")
+	if err != nil {
+		t.Fatalf("creating host client failed, %s", err.Error())
+	}
+
+	for _, client := range []*retryablehttp.Client{c, hostClient} {
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		client.Do(req)
+	}
+
+	if reqCount != 8 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/ermanimer/retryablehttp/config
+
+go 1.18
+
+require (
+	github.com/ermanimer/retryablehttp v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/ermanimer/retryablehttp => ../
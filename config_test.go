@@ -0,0 +1,71 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Validate method of a config should report all problems at once.
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	err := Config{
+		MaxReqCount:          -1,
+		Backoff:              -time.Second,
+		Jitter:               2,
+		Timeout:              -time.Second,
+		RetryableStatusCodes: []int{503, 42},
+	}.Validate()
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errs) != 5 {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	for _, target := range []error{ErrInvalidMaxReqCount, ErrInvalidBackoff, ErrInvalidJitter, ErrInvalidTimeout, ErrInvalidStatusCode} {
+		if !errors.Is(err, target) {
+			t.Errorf("missing error, %v", target)
+		}
+	}
+
+	if _, err := NewClientFromConfig(Config{MaxReqCount: -1}); !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client created from config should retry only retryable status codes.
+func TestNewClientFromConfig(t *testing.T) {
+	statusCodes := []int{http.StatusServiceUnavailable, http.StatusBadRequest, http.StatusOK}
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCodes[reqCount])
+		reqCount++
+	}))
+	defer s.Close()
+
+	c, err := NewClientFromConfig(Config{
+		MaxReqCount:          3,
+		Timeout:              time.Second,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	})
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if !errors.Is(err, ErrUnsuccessfulStatusCode) || !IsPermanent(err) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest || reqCount != 2 {
+		t.Errorf("unexpected status code %d or request count %d", res.StatusCode, reqCount)
+	}
+}
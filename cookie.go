@@ -0,0 +1,264 @@
+package retryablehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cookie errors
+var (
+	ErrNilCookieJar = errors.New("cookie jar is nil")
+)
+
+// WithCookieJar configures client to apply cookies of provided jar to every attempt and to store cookies of every response in it,
+// including responses of failed attempts and of redirects, so that retries and failover endpoints see the latest cookies.
+// It replaces the jar of the http client, which is not modified. FileCookieJar persists cookies across restarts.
+func WithCookieJar(jar http.CookieJar) Option {
+	return named("WithCookieJar", func(c *Client) error {
+		if jar == nil {
+			return ErrNilCookieJar
+		}
+
+		c.cookieJar = jar
+
+		return nil
+	})
+}
+
+// cookieSaveDelay is the delay after a cookie change before the file of a FileCookieJar is written, changes within the delay are written together.
+const cookieSaveDelay = time.Second
+
+// FileCookieJar represents a cookie jar persisted to a file, so that cookies, including session cookies, survive restarts.
+// Cookies are matched like the jar of net/http/cookiejar. The file is rewritten atomically in the background a second after cookies change,
+// so that responses do not wait for the disk, Save writes it immediately and should be called before exiting. Expired cookies are pruned
+// when the file is loaded and written.
+type FileCookieJar struct {
+	path string
+	jar  *cookiejar.Jar
+	psl  cookiejar.PublicSuffixList
+
+	// writeMu serializes writes of the file.
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	cookies map[string]storedCookie
+	pending *time.Timer
+}
+
+// storedCookie represents a cookie in the file of a FileCookieJar with the url it was set for.
+type storedCookie struct {
+	URL      string        `json:"url"`
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Path     string        `json:"path,omitempty"`
+	Domain   string        `json:"domain,omitempty"`
+	Expires  time.Time     `json:"expires,omitempty"`
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"http_only,omitempty"`
+	SameSite http.SameSite `json:"same_site,omitempty"`
+}
+
+// NewFileCookieJar creates and returns a cookie jar persisted to provided file, loading unexpired cookies of the file when it exists.
+// Provided public suffix list, such as publicsuffix.List of golang.org/x/net/publicsuffix, stops servers from setting cookies for every domain
+// under a public suffix such as "co.uk". Without a list servers can set cookies for sibling domains, which is safe only for trusted servers.
+func NewFileCookieJar(path string, psl cookiejar.PublicSuffixList) (*FileCookieJar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: psl})
+	if err != nil {
+		return nil, err
+	}
+
+	j := &FileCookieJar{
+		path:    path,
+		jar:     jar,
+		psl:     psl,
+		cookies: make(map[string]storedCookie),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []storedCookie
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, s := range stored {
+		u, err := url.Parse(s.URL)
+		if err != nil || s.expired(now) || !j.accepts(u, s) {
+			continue
+		}
+
+		j.jar.SetCookies(u, []*http.Cookie{s.cookie()})
+		j.cookies[s.key()] = s
+	}
+
+	return j, nil
+}
+
+// Cookies returns cookies to send in a request to provided url.
+func (j *FileCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies stores cookies of a response from provided url and schedules writing the file, Save can be called to report errors writing the file.
+func (j *FileCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	for _, cookie := range cookies {
+		s := newStoredCookie(u, cookie, now)
+		if s.expired(now) {
+			delete(j.cookies, s.key())
+			continue
+		}
+		if j.accepts(u, s) {
+			j.cookies[s.key()] = s
+		}
+	}
+
+	if j.pending == nil {
+		j.pending = time.AfterFunc(cookieSaveDelay, func() {
+			j.mu.Lock()
+			j.pending = nil
+			j.mu.Unlock()
+
+			j.Save()
+		})
+	}
+}
+
+// accepts reports whether provided cookie set by provided url is kept by the jar, cookies for other domains and for public suffixes are not.
+func (j *FileCookieJar) accepts(u *url.URL, s storedCookie) bool {
+	if s.Domain == "" {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == s.Domain {
+		return true
+	}
+	if !strings.HasSuffix(host, "."+s.Domain) {
+		return false
+	}
+
+	return j.psl == nil || j.psl.PublicSuffix(s.Domain) != s.Domain
+}
+
+// Save writes unexpired cookies of the jar to its file atomically, through a temporary file which is renamed over it.
+func (j *FileCookieJar) Save() error {
+	j.writeMu.Lock()
+	defer j.writeMu.Unlock()
+
+	data, err := j.marshal(time.Now())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), j.path)
+}
+
+// marshal prunes expired cookies and encodes unexpired cookies of the jar.
+func (j *FileCookieJar) marshal(now time.Time) ([]byte, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stored := make([]storedCookie, 0, len(j.cookies))
+	for key, s := range j.cookies {
+		if s.expired(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		stored = append(stored, s)
+	}
+
+	return json.Marshal(stored)
+}
+
+// newStoredCookie converts provided cookie set for provided url, Max-Age is converted to an expiry time.
+func newStoredCookie(u *url.URL, cookie *http.Cookie, now time.Time) storedCookie {
+	s := storedCookie{
+		URL:      (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")),
+		Expires:  cookie.Expires,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		SameSite: cookie.SameSite,
+	}
+	switch {
+	case cookie.MaxAge < 0:
+		s.Expires = time.Unix(1, 0)
+	case cookie.MaxAge > 0:
+		s.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	}
+
+	return s
+}
+
+// key returns the key identifying stored cookie by domain, path and name.
+func (s storedCookie) key() string {
+	domain := s.Domain
+	if domain == "" {
+		if u, err := url.Parse(s.URL); err == nil {
+			domain = strings.ToLower(u.Hostname())
+		}
+	}
+
+	return domain + ";" + s.Path + ";" + s.Name
+}
+
+// expired reports whether stored cookie is expired at provided time, cookies without expiry time never expire.
+func (s storedCookie) expired(now time.Time) bool {
+	return !s.Expires.IsZero() && !s.Expires.After(now)
+}
+
+// cookie converts stored cookie to a cookie.
+func (s storedCookie) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     s.Name,
+		Value:    s.Value,
+		Path:     s.Path,
+		Domain:   s.Domain,
+		Expires:  s.Expires,
+		Secure:   s.Secure,
+		HttpOnly: s.HttpOnly,
+		SameSite: s.SameSite,
+	}
+}
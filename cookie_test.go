@@ -0,0 +1,163 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrNilCookieJar when nil cookie jar is provided.
+func TestNilCookieJarOption(t *testing.T) {
+	if _, err := NewClient(WithCookieJar(nil)); !errors.Is(err, ErrNilCookieJar) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with a cookie jar should send cookies set by failed attempts in following attempts.
+func TestCookieJarRetries(t *testing.T) {
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&reqCount, 1) == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "1" {
+			t.Errorf("cookie is not sent, %v", err)
+		}
+	}))
+	defer s.Close()
+
+	jar, err := NewFileCookieJar(filepath.Join(t.TempDir(), "cookies.json"), nil)
+	if err != nil {
+		t.Errorf("creating cookie jar failed, %s", err.Error())
+	}
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithCookieJar(jar),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	res.Body.Close()
+}
+
+// NewFileCookieJar function should load unexpired cookies saved by another jar and prune expired ones.
+func TestFileCookieJarPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	u, _ := url.Parse("https://example.com/api")
+
+	jar, err := NewFileCookieJar(path, nil)
+	if err != nil {
+		t.Errorf("creating cookie jar failed, %s", err.Error())
+		return
+	}
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "1", Path: "/"},
+		{Name: "persistent", Value: "2", Path: "/", MaxAge: 3600},
+		{Name: "expired", Value: "3", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	})
+	if err := jar.Save(); err != nil {
+		t.Errorf("saving cookie jar failed, %s", err.Error())
+	}
+
+	loaded, err := NewFileCookieJar(path, nil)
+	if err != nil {
+		t.Errorf("loading cookie jar failed, %s", err.Error())
+		return
+	}
+
+	values := make(map[string]string)
+	for _, cookie := range loaded.Cookies(u) {
+		values[cookie.Name] = cookie.Value
+	}
+	if len(values) != 2 || values["session"] != "1" || values["persistent"] != "2" {
+		t.Errorf("unexpected cookies, %v", values)
+	}
+
+	loaded.SetCookies(u, []*http.Cookie{{Name: "session", Path: "/", MaxAge: -1}})
+	if err := loaded.Save(); err != nil {
+		t.Errorf("saving cookie jar failed, %s", err.Error())
+	}
+	reloaded, err := NewFileCookieJar(path, nil)
+	if err != nil {
+		t.Errorf("loading cookie jar failed, %s", err.Error())
+		return
+	}
+	if cookies := reloaded.Cookies(u); len(cookies) != 1 || cookies[0].Name != "persistent" {
+		t.Errorf("unexpected cookies, %v", cookies)
+	}
+}
+
+// suffixList is a public suffix list with a single public suffix.
+type suffixList string
+
+// PublicSuffix returns the public suffix of provided domain.
+func (l suffixList) PublicSuffix(domain string) string {
+	if strings.HasSuffix(domain, "."+string(l)) || domain == string(l) {
+		return string(l)
+	}
+
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+// String returns the name of the list.
+func (l suffixList) String() string {
+	return string(l)
+}
+
+// SetCookies method of a FileCookieJar should reject cookies for public suffixes and write the file in the background.
+func TestFileCookieJarPublicSuffix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	jar, err := NewFileCookieJar(path, suffixList("co.uk"))
+	if err != nil {
+		t.Errorf("creating cookie jar failed, %s", err.Error())
+		return
+	}
+
+	u, _ := url.Parse("https://a.example.co.uk")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "shared", Value: "1", Domain: "co.uk"},
+		{Name: "own", Value: "2", Domain: "example.co.uk"},
+	})
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file is written synchronously, %v", err)
+	}
+
+	sibling, _ := url.Parse("https://b.other.co.uk")
+	if cookies := jar.Cookies(sibling); len(cookies) != 0 {
+		t.Errorf("unexpected cookies, %v", cookies)
+	}
+
+	deadline := time.Now().Add(3 * cookieSaveDelay)
+	for {
+		if _, err := os.Stat(path); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	loaded, err := NewFileCookieJar(path, suffixList("co.uk"))
+	if err != nil {
+		t.Errorf("loading cookie jar failed, %s", err.Error())
+		return
+	}
+	if cookies := loaded.Cookies(u); len(cookies) != 1 || cookies[0].Name != "own" {
+		t.Errorf("unexpected cookies, %v", cookies)
+	}
+}
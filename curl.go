@@ -0,0 +1,86 @@
+package retryablehttp
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxCurlBodyBytes is the maximum request body size included in curl commands.
+const maxCurlBodyBytes = 64 << 10
+
+// WithCurlReproduction configures whether a curl command reproducing the final attempt is attached to give up errors.
+// The command is available to the caller in *GiveUpError returned by Do.
+// Curl reproduction is disabled by default.
+func WithCurlReproduction(enabled bool) Option {
+	return named("WithCurlReproduction", func(c *Client) error {
+		c.curlReproduction = enabled
+
+		return nil
+	})
+}
+
+// Curl returns a copy-pasteable curl command reproducing provided request, redacted with DefaultRedactionPolicy.
+// Request body is included when it can be recreated by request's GetBody function and it is not larger than 64 KiB.
+func Curl(req *http.Request) string {
+	return curl(req, defaultRedactor)
+}
+
+// curl returns a curl command reproducing provided request redacted by provided redactor.
+func curl(req *http.Request, r *redactor) string {
+	var b strings.Builder
+	b.WriteString("curl -X ")
+	b.WriteString(req.Method)
+	b.WriteString(" ")
+	b.WriteString(shellQuote(r.url(req.URL).String()))
+
+	header := r.header(req.Header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			b.WriteString(" -H ")
+			b.WriteString(shellQuote(name + ": " + value))
+		}
+	}
+
+	if body, ok := curlBody(req); ok && len(body) > 0 {
+		b.WriteString(" --data-raw ")
+		b.WriteString(shellQuote(body))
+	}
+
+	return b.String()
+}
+
+// curlBody returns provided request's body when it can be recreated and it is small enough.
+func curlBody(req *http.Request) (string, bool) {
+	if req.GetBody == nil {
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	b := getBuffer(maxCurlBodyBytes + 1)
+	defer putBuffer(b)
+
+	_, err = b.ReadFrom(io.LimitReader(body, maxCurlBodyBytes+1))
+	if err != nil || b.Len() > maxCurlBodyBytes {
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+// shellQuote quotes provided string for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
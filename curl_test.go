@@ -0,0 +1,58 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Curl function should reproduce request with body and redact sensitive headers.
+func TestCurl(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/users?name=gopher", strings.NewReader(`{"name":"it's me"}`))
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+
+	expected := `curl -X POST 'https://example.com/users?name=gopher' -H 'Authorization: REDACTED' -H 'Content-Type: application/json' --data-raw '{"name":"it'\''s me"}'`
+	if curl := Curl(req); curl != expected {
+		t.Errorf("unexpected curl command, %s", curl)
+	}
+}
+
+// Do method of a client with curl reproduction should return give up error with curl command.
+func TestDoWithCurlReproduction(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithCurlReproduction(true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, err = c.Do(req)
+
+	var giveUpErr *GiveUpError
+	if !errors.As(err, &giveUpErr) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if giveUpErr.Curl != "curl -X GET '"+s.URL+"'" {
+		t.Errorf("unexpected curl command, %s", giveUpErr.Curl)
+	}
+}
@@ -0,0 +1,181 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// deadline errors
+var (
+	ErrInvalidDeadlineSplit  = errors.New("deadline split is not valid")
+	ErrInvalidDeadlineFormat = errors.New("deadline format is not valid")
+	ErrInvalidMinAttemptTime = errors.New("minimum attempt time must be greater than zero")
+	ErrDeadlineInsufficient  = errors.New("remaining deadline is shorter than minimum attempt time")
+)
+
+// DefaultDeadlineHeader is the header advertising remaining deadline in milliseconds.
+const DefaultDeadlineHeader = "X-Request-Timeout-Ms"
+
+// DeadlineFormat represents how remaining deadline is written into deadline header.
+type DeadlineFormat int
+
+// deadline formats
+const (
+	// DeadlineMilliseconds writes remaining deadline as whole milliseconds, such as 1500.
+	DeadlineMilliseconds DeadlineFormat = iota
+	// DeadlineGRPC writes remaining deadline in grpc-timeout style, at most 8 digits followed by a unit, such as 1500m or 90S.
+	DeadlineGRPC
+)
+
+// DeadlineSplit represents how the remaining deadline of a request's context is divided across its remaining attempts.
+type DeadlineSplit int
+
+// deadline splits
+const (
+	// DeadlineSplitNone lets every attempt use the whole remaining deadline, it is the default.
+	DeadlineSplitNone DeadlineSplit = iota
+	// DeadlineSplitEqual gives every remaining attempt an equal share of the remaining deadline.
+	DeadlineSplitEqual
+	// DeadlineSplitFrontLoaded gives earlier attempts larger shares of the remaining deadline, with n remaining attempts
+	// current attempt gets n/(n+...+1) of it. The last attempt always gets the whole remaining deadline.
+	DeadlineSplitFrontLoaded
+)
+
+// WithDeadlineSplit configures client to divide the remaining deadline of request's context across remaining attempts,
+// setting a timeout on each attempt so that slow early attempts do not starve the last one. Requests without a deadline are not affected.
+func WithDeadlineSplit(split DeadlineSplit) Option {
+	return named("WithDeadlineSplit", func(c *Client) error {
+		if split < DeadlineSplitNone || split > DeadlineSplitFrontLoaded {
+			return ErrInvalidDeadlineSplit
+		}
+
+		c.deadlineSplit = split
+
+		return nil
+	})
+}
+
+// splitDeadline returns a copy of provided request whose context times out after call's share of the remaining deadline,
+// and a function cancelling that context. Provided request and a nil function are returned when deadline is not split.
+func (c *Client) splitDeadline(cl *call, req *http.Request) (*http.Request, context.CancelFunc) {
+	deadline, ok := req.Context().Deadline()
+	if c.deadlineSplit == DeadlineSplitNone || !ok {
+		return req, nil
+	}
+
+	remaining := cl.maxReqCount - cl.attempt + 1
+	if remaining <= 1 {
+		return req, nil
+	}
+
+	budget := deadline.Sub(c.clock.Now())
+	var share time.Duration
+	switch c.deadlineSplit {
+	case DeadlineSplitEqual:
+		share = budget / time.Duration(remaining)
+	case DeadlineSplitFrontLoaded:
+		share = budget * 2 / time.Duration(remaining+1)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), share)
+
+	return req.WithContext(ctx), cancel
+}
+
+// WithMinAttemptTime configures client not to start an attempt when the remaining deadline of request's context is shorter than provided duration,
+// since such an attempt would likely be cancelled midway. ErrDeadlineInsufficient is returned as permanent error instead. It is checked right before
+// an attempt is sent, after waiting for rate limits and concurrency limits. Attempts are started regardless of the remaining deadline by default.
+func WithMinAttemptTime(d time.Duration) Option {
+	return named("WithMinAttemptTime", func(c *Client) error {
+		if d <= 0 {
+			return ErrInvalidMinAttemptTime
+		}
+
+		c.minAttemptTime = d
+
+		return nil
+	})
+}
+
+// checkDeadline returns ErrDeadlineInsufficient as permanent error when the remaining deadline of provided request's context is shorter than client's minimum attempt time.
+func (c *Client) checkDeadline(req *http.Request) error {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return nil
+	}
+
+	if remaining := deadline.Sub(c.clock.Now()); remaining < c.minAttemptTime {
+		return Permanent(fmt.Errorf("%w, %s remaining", ErrDeadlineInsufficient, remaining))
+	}
+
+	return nil
+}
+
+// WithDeadlineHeader configures client to advertise the remaining deadline of each attempt's context in provided header using provided format,
+// so that upstreams can shed work they cannot finish in time. DefaultDeadlineHeader can be used. Attempts without a deadline are sent without the header.
+func WithDeadlineHeader(name string, format DeadlineFormat) Option {
+	return named("WithDeadlineHeader", func(c *Client) error {
+		if name == "" {
+			return ErrEmptyHeaderName
+		}
+		if format < DeadlineMilliseconds || format > DeadlineGRPC {
+			return ErrInvalidDeadlineFormat
+		}
+
+		c.deadlineHeader = name
+		c.deadlineFormat = format
+
+		return nil
+	})
+}
+
+// format writes provided remaining deadline in deadline format, negative durations are written as zero.
+func (f DeadlineFormat) format(remaining time.Duration) string {
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if f == DeadlineMilliseconds {
+		return strconv.FormatInt(remaining.Milliseconds(), 10)
+	}
+
+	const maxValue = 99999999
+	units := []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"n", time.Nanosecond},
+		{"u", time.Microsecond},
+		{"m", time.Millisecond},
+		{"S", time.Second},
+		{"M", time.Minute},
+	}
+	for _, u := range units {
+		if v := remaining / u.d; v <= maxValue {
+			return strconv.FormatInt(int64(v), 10) + u.unit
+		}
+	}
+
+	return strconv.FormatInt(int64(remaining/time.Hour), 10) + "H"
+}
+
+// cancelBody is a response body which cancels attempt's context when it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// Close closes body and cancels attempt's context.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.cancel)
+
+	return err
+}
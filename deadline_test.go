@@ -0,0 +1,301 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidDeadlineSplit when unknown deadline split is provided.
+func TestInvalidDeadlineSplitOption(t *testing.T) {
+	_, err := NewClient(
+		WithDeadlineSplit(DeadlineSplit(-1)),
+	)
+	if !errors.Is(err, ErrInvalidDeadlineSplit) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with deadline split should give attempts shares of the remaining deadline.
+func TestDeadlineSplit(t *testing.T) {
+	tests := []struct {
+		split    DeadlineSplit
+		expected []time.Duration
+	}{
+		{DeadlineSplitEqual, []time.Duration{time.Second, 1500 * time.Millisecond, 3 * time.Second}},
+		{DeadlineSplitFrontLoaded, []time.Duration{1500 * time.Millisecond, 2 * time.Second, 3 * time.Second}},
+	}
+
+	for _, test := range tests {
+		var budgets []time.Duration
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		c, err := NewClient(
+			WithMaxReqCount(3),
+			WithBackoff(0),
+			WithDeadlineSplit(test.split),
+			WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				deadline, _ := r.Context().Deadline()
+				budgets = append(budgets, time.Until(deadline))
+
+				return http.DefaultTransport.RoundTrip(r)
+			})}),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		c.Do(req)
+		cancel()
+		s.Close()
+
+		if len(budgets) != 3 {
+			t.Fatalf("unexpected attempt count, %d", len(budgets))
+		}
+		for i, budget := range budgets {
+			if budget > test.expected[i] || budget < test.expected[i]-200*time.Millisecond {
+				t.Errorf("unexpected budget of attempt %d, %s", i+1, budget)
+			}
+		}
+	}
+}
+
+// Do method of a client with deadline split should retry attempts timing out within their share of the deadline.
+func TestDeadlineSplitRetry(t *testing.T) {
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&reqCount, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithDeadlineSplit(DeadlineSplitEqual),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error, %s", err.Error())
+	}
+	res.Body.Close()
+
+	if reqCount := atomic.LoadInt32(&reqCount); reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// NewClient function should return errors when empty deadline header name or unknown deadline format is provided.
+func TestInvalidDeadlineHeaderOptions(t *testing.T) {
+	if _, err := NewClient(WithDeadlineHeader("", DeadlineMilliseconds)); !errors.Is(err, ErrEmptyHeaderName) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithDeadlineHeader(DefaultDeadlineHeader, DeadlineFormat(2))); !errors.Is(err, ErrInvalidDeadlineFormat) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with deadline header should advertise remaining deadline of each attempt.
+func TestDeadlineHeader(t *testing.T) {
+	var values []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values = append(values, r.Header.Get(DefaultDeadlineHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithDeadlineSplit(DeadlineSplitEqual),
+		WithDeadlineHeader(DefaultDeadlineHeader, DeadlineMilliseconds),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if len(values) != 2 {
+		t.Fatalf("unexpected attempt count, %d", len(values))
+	}
+	first, _ := strconv.Atoi(values[0])
+	second, _ := strconv.Atoi(values[1])
+	if first > 5000 || first < 4800 || second > 10000 || second < 9500 {
+		t.Errorf("unexpected deadline headers, %v", values)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	values = nil
+	c.Do(req)
+	if values[0] != "" {
+		t.Errorf("unexpected deadline header without deadline, %s", values[0])
+	}
+}
+
+// Do method of a client with deadline header should measure remaining deadline with client's clock.
+func TestDeadlineHeaderClock(t *testing.T) {
+	var value string
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	c, err := NewClient(
+		WithClock(&fakeClock{now: deadline.Add(-30 * time.Minute)}),
+		WithDeadlineHeader(DefaultDeadlineHeader, DeadlineMilliseconds),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			value = r.Header.Get(DefaultDeadlineHeader)
+
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+	if value != "1800000" {
+		t.Errorf("unexpected deadline header, %s", value)
+	}
+}
+
+// format method of grpc deadline format should write at most 8 digits with the finest fitting unit.
+func TestDeadlineGRPCFormat(t *testing.T) {
+	for d, expected := range map[time.Duration]string{
+		1500 * time.Millisecond: "1500000u",
+		90 * time.Second:        "90000000u",
+		200 * time.Hour:         "720000S",
+		0:                       "0n",
+	} {
+		if value := DeadlineGRPC.format(d); value != expected {
+			t.Errorf("unexpected format of %s, %s", d, value)
+		}
+	}
+}
+
+// NewClient function should return ErrInvalidMinAttemptTime when non-positive minimum attempt time is provided.
+func TestInvalidMinAttemptTimeOption(t *testing.T) {
+	_, err := NewClient(
+		WithMinAttemptTime(0),
+	)
+	if !errors.Is(err, ErrInvalidMinAttemptTime) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with minimum attempt time should not start attempts which can not fit in the remaining deadline.
+func TestMinAttemptTime(t *testing.T) {
+	reqCount := 0
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(700*time.Millisecond),
+		WithMinAttemptTime(500*time.Millisecond),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			reqCount++
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, err = c.Do(req)
+	if !errors.Is(err, ErrDeadlineInsufficient) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// Do method of a client with deadline split and minimum attempt time should measure the remaining deadline with client's clock.
+func TestDeadlineClock(t *testing.T) {
+	var budgets []time.Duration
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	clock := &fakeClock{now: deadline.Add(-30 * time.Minute)}
+	c, err := NewClient(
+		WithClock(clock),
+		WithSleeper(clock),
+		WithMaxReqCount(3),
+		WithDeadlineSplit(DeadlineSplitEqual),
+		WithMinAttemptTime(2*time.Minute),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			deadline, _ := r.Context().Deadline()
+			budgets = append(budgets, time.Until(deadline))
+
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("unexpected error, %s", err.Error())
+	}
+	if len(budgets) != 1 || budgets[0] > 10*time.Minute || budgets[0] < 9*time.Minute {
+		t.Errorf("unexpected budgets, %v", budgets)
+	}
+
+	clock.Sleep(29 * time.Minute)
+	if _, err := c.Do(req); !errors.Is(err, ErrDeadlineInsufficient) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
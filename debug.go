@@ -0,0 +1,126 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// debug errors
+var (
+	ErrNilDumpWriter = errors.New("dump writer is nil")
+)
+
+// maxDumpBytes is the maximum number of bytes written for a single dump, longer dumps are truncated.
+const maxDumpBytes = 64 << 10
+
+// debugDumpKey is the context key of per request debug dumps.
+type debugDumpKey struct{}
+
+// dumper writes dumps of requests and responses to a writer.
+type dumper struct {
+	mu          sync.Mutex
+	w           io.Writer
+	includeBody bool
+}
+
+// WithDebugDump configures client to write dumps of every attempt's request and response to provided writer.
+// Bodies are included when includeBody is true, each dump is truncated to 64 KiB.
+// Debug dumps are disabled by default.
+func WithDebugDump(w io.Writer, includeBody bool) Option {
+	return named("WithDebugDump", func(c *Client) error {
+		if w == nil {
+			return ErrNilDumpWriter
+		}
+
+		c.dumper = &dumper{w: w, includeBody: includeBody}
+
+		return nil
+	})
+}
+
+// ContextWithDebugDump returns a copy of provided context which enables debug dumps to provided writer for requests using it.
+// Per request debug dumps take precedence over client's debug dumps.
+func ContextWithDebugDump(ctx context.Context, w io.Writer, includeBody bool) context.Context {
+	return context.WithValue(ctx, debugDumpKey{}, &dumper{w: w, includeBody: includeBody})
+}
+
+// debugDump returns dumper of provided context or client's dumper, it returns nil when debug dumps are disabled.
+func (c *Client) debugDump(ctx context.Context) *dumper {
+	if d, ok := ctx.Value(debugDumpKey{}).(*dumper); ok && d.w != nil {
+		return d
+	}
+
+	return c.dumper
+}
+
+// request writes dump of provided request redacted by provided redactor.
+func (d *dumper) request(req *http.Request, attempt int, r *redactor) {
+	redacted := r.request(req)
+
+	var err error
+	if d.includeBody {
+		req.Body, redacted.Body, err = copyBody(req.Body)
+	}
+
+	var dump []byte
+	if err == nil {
+		dump, err = httputil.DumpRequestOut(redacted, d.includeBody)
+	}
+	d.write(fmt.Sprintf("attempt %d request", attempt), dump, err)
+}
+
+// response writes dump of provided response redacted by provided redactor, or provided error.
+// At most maximum dump size of response's body is read for the dump, the read bytes are put back so that the body can be read from the start.
+func (d *dumper) response(res *http.Response, err error, attempt int, r *redactor) {
+	var dump []byte
+	if err == nil {
+		redacted := r.response(res)
+		if d.includeBody && res.Body != nil && res.Body != http.NoBody {
+			var body []byte
+			body, err = PeekBody(res, maxDumpBytes+1)
+			redacted.Body = io.NopCloser(bytes.NewReader(body))
+			if int64(len(body)) < redacted.ContentLength {
+				redacted.ContentLength = int64(len(body))
+			}
+		}
+
+		if err == nil {
+			dump, err = httputil.DumpResponse(redacted, d.includeBody)
+		}
+	}
+	d.write(fmt.Sprintf("attempt %d response", attempt), dump, err)
+}
+
+// copyBody reads and closes provided body, and returns two readers of its content.
+func copyBody(body io.ReadCloser) (io.ReadCloser, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return body, body, nil
+	}
+
+	data, err := readAll(body, -1)
+	body.Close()
+
+	return io.NopCloser(bytes.NewReader(data)), io.NopCloser(bytes.NewReader(data)), err
+}
+
+// write writes provided dump with a title line, truncating it to maximum dump size.
+func (d *dumper) write(title string, dump []byte, err error) {
+	var suffix string
+	if err != nil {
+		dump = []byte(err.Error())
+	} else if len(dump) > maxDumpBytes {
+		suffix = fmt.Sprintf("\n[truncated %d bytes]", len(dump)-maxDumpBytes)
+		dump = dump[:maxDumpBytes]
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fmt.Fprintf(d.w, "--- %s ---\n%s%s\n", title, dump, suffix)
+}
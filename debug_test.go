@@ -0,0 +1,119 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Do method of a client with debug dump should write dumps of every attempt's request and response.
+func TestDebugDump(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte("hello gopher"))
+	}))
+	defer s.Close()
+
+	var buf bytes.Buffer
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithDebugDump(&buf, true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, strings.NewReader("ping"))
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("ping")), nil
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+
+	dump := buf.String()
+	for _, expected := range []string{"--- attempt 1 request ---", "503 Service Unavailable", "--- attempt 2 response ---", "ping", "hello gopher"} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("dump does not contain %q", expected)
+		}
+	}
+}
+
+// Do method should write debug dumps of requests with debug dump context without body when body is excluded.
+func TestContextWithDebugDump(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello gopher"))
+	}))
+	defer s.Close()
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	ctx := ContextWithDebugDump(context.Background(), &buf, false)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+
+	dump := buf.String()
+	if !strings.Contains(dump, "200 OK") || strings.Contains(dump, "hello gopher") {
+		t.Errorf("unexpected dump, %s", dump)
+	}
+}
+
+// Do method of a client with debug dump should read at most maximum dump size of response bodies for dumps and return the whole body.
+func TestDebugDumpLargeResponse(t *testing.T) {
+	body := strings.Repeat("a", 4*maxDumpBytes)
+	r := &countingReader{Reader: strings.NewReader(body)}
+
+	var buf bytes.Buffer
+	c, err := NewClient(
+		WithDebugDump(&buf, true),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, ProtoMajor: 1, ProtoMinor: 1, ContentLength: int64(len(body)), Body: io.NopCloser(r)}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get("http://example.com")
+	if err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+	defer res.Body.Close()
+
+	if r.n > maxDumpBytes+1 {
+		t.Errorf("unexpected read byte count before body is read, %d", r.n)
+	}
+	if !strings.Contains(buf.String(), "[truncated ") {
+		t.Errorf("dump is not truncated")
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil || string(data) != body {
+		t.Errorf("unexpected response body of length %d, %v", len(data), err)
+	}
+}
@@ -0,0 +1,66 @@
+package retryablehttp
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// defaultClient holds the package level default client.
+var defaultClient atomic.Value
+
+func init() {
+	defaultClient.Store(&Client{})
+}
+
+// Default returns the package level default client used by Get, Post and Do functions.
+// Initial default client is a zero value Client with default options.
+func Default() *Client {
+	return defaultClient.Load().(*Client)
+}
+
+// SetDefault makes provided client the package level default client, nil restores a client with default options.
+// It is safe to call SetDefault concurrently with Get, Post and Do functions.
+func SetDefault(c *Client) {
+	if c == nil {
+		c = &Client{}
+	}
+
+	defaultClient.Store(c)
+}
+
+// Get sends a GET request to provided url with automatic retries.
+func (c *Client) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+// Post sends a POST request with provided content type and body to provided url with automatic retries.
+func (c *Client) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return c.Do(req)
+}
+
+// Get sends a GET request to provided url with automatic retries using the default client.
+func Get(url string) (*http.Response, error) {
+	return Default().Get(url)
+}
+
+// Post sends a POST request with provided content type and body to provided url with automatic retries using the default client.
+func Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	return Default().Post(url, contentType, body)
+}
+
+// Do sends provided http request with automatic retries using the default client.
+func Do(req *http.Request) (*http.Response, error) {
+	return Default().Do(req)
+}
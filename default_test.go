@@ -0,0 +1,60 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Get, Post and Do functions should send requests using the default client and SetDefault function should replace it.
+func TestDefaultClient(t *testing.T) {
+	var received []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.Header.Get("Content-Type")+" "+string(body))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	original := Default()
+	defer SetDefault(original)
+
+	if _, err := Get(s.URL); !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+	SetDefault(c)
+	if Default() != c {
+		t.Error("default client is not replaced")
+	}
+
+	if _, err := Post(s.URL, "text/plain", strings.NewReader("body")); !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	if _, err := Do(req); !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	SetDefault(nil)
+	if Default() == nil || Default() == c {
+		t.Error("default client is not restored")
+	}
+
+	if len(received) != 5 || received[0] != "GET  " || received[1] != "POST text/plain body" || received[2] != received[1] || received[4] != "DELETE  " {
+		t.Errorf("unexpected requests, %q", received)
+	}
+}
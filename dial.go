@@ -0,0 +1,189 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// dial retry errors
+var (
+	ErrInvalidDialRetry = errors.New("dial retry address timeout and rounds must not be negative")
+)
+
+// default dial retry settings
+const (
+	defaultDialAddressTimeout = 2 * time.Second
+	defaultDialRounds         = 2
+)
+
+// DialRetry represents settings of dialing with retries across resolved addresses, zero values take default values.
+// Addresses of a host are dialed one by one, alternating IPv6 and IPv4 addresses, each with AddressTimeout (2 seconds), and all of them are dialed
+// up to Rounds (2) times before dialing fails. Hosts are resolved with Resolver, net.DefaultResolver when it is nil.
+type DialRetry struct {
+	AddressTimeout time.Duration
+	Rounds         int
+	Resolver       *net.Resolver
+}
+
+// RetryingDialer returns a dial function with provided settings, for transports of http clients, which retries connection establishment across
+// resolved addresses of a host before the retry loop of the client engages.
+func RetryingDialer(cfg DialRetry) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if cfg.AddressTimeout == 0 {
+		cfg.AddressTimeout = defaultDialAddressTimeout
+	}
+	if cfg.Rounds == 0 {
+		cfg.Rounds = defaultDialRounds
+	}
+	resolver := cfg.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		addrs = alternateFamilies(addrs)
+
+		var d net.Dialer
+		var lastErr error
+		for round := 0; round < cfg.Rounds; round++ {
+			for _, addr := range addrs {
+				dialCtx, cancel := context.WithTimeout(ctx, cfg.AddressTimeout)
+				conn, err := d.DialContext(dialCtx, network, net.JoinHostPort(addr.String(), port))
+				cancel()
+				if err == nil {
+					return conn, nil
+				}
+				lastErr = err
+
+				if ctx.Err() != nil {
+					return nil, lastErr
+				}
+			}
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses are resolved for %s", host)
+		}
+
+		return nil, lastErr
+	}
+}
+
+// alternateFamilies returns provided addresses reordered to alternate IPv6 and IPv4 addresses, starting with the family of the first address.
+func alternateFamilies(addrs []net.IPAddr) []net.IPAddr {
+	var first, second []net.IPAddr
+	for _, addr := range addrs {
+		if (addr.IP.To4() == nil) == (addrs[0].IP.To4() == nil) {
+			first = append(first, addr)
+		} else {
+			second = append(second, addr)
+		}
+	}
+
+	ordered := make([]net.IPAddr, 0, len(addrs))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			ordered = append(ordered, first[i])
+		}
+		if i < len(second) {
+			ordered = append(ordered, second[i])
+		}
+	}
+
+	return ordered
+}
+
+// WithDialRetry configures client to dial connections with a RetryingDialer of provided settings, so that unreachable addresses of multi-homed hosts
+// are skipped within an attempt instead of costing a whole attempt and its backoff. It requires the transport of client's http client to be
+// an *http.Transport, or nil, whose dialer is replaced in attempts. Connections are dialed by the transport by default.
+func WithDialRetry(cfg DialRetry) Option {
+	return named("WithDialRetry", func(c *Client) error {
+		if cfg.AddressTimeout < 0 || cfg.Rounds < 0 {
+			return ErrInvalidDialRetry
+		}
+
+		c.dialRetry = &dialRetry{cfg: cfg}
+
+		return nil
+	})
+}
+
+// dialRetry holds dial retry settings and the transport derived for them.
+type dialRetry struct {
+	cfg   DialRetry
+	cache transportCache
+}
+
+// dialer returns the dial function of attempts resolving hosts with provided resolver, which may be nil.
+func (c *Client) dialer(resolver *net.Resolver) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if c.dialRetry == nil {
+		return (&net.Dialer{Resolver: resolver}).DialContext
+	}
+
+	cfg := c.dialRetry.cfg
+	if resolver != nil {
+		cfg.Resolver = resolver
+	}
+
+	return RetryingDialer(cfg)
+}
+
+// attemptTransport returns the transport of call's current attempt derived from provided transport with client's dialer.
+func (c *Client) attemptTransport(cl *call, base http.RoundTripper) (http.RoundTripper, error) {
+	if cl.dnsFailed && c.fallbackResolver != nil {
+		return c.fallbackResolver.cache.derive(base, func() dialFunc {
+			return c.dialer(c.fallbackResolver.resolver)
+		})
+	}
+
+	return c.dialRetry.cache.derive(base, func() dialFunc {
+		return c.dialer(nil)
+	})
+}
+
+// dialFunc is the dial function of a transport.
+type dialFunc = func(ctx context.Context, network, address string) (net.Conn, error)
+
+// transportCache holds a transport derived from a base transport with another dial function.
+type transportCache struct {
+	mu        sync.Mutex
+	base      http.RoundTripper
+	transport http.RoundTripper
+}
+
+// derive returns a copy of provided transport dialing with the dial function returned by provided function, copies are cached per transport.
+func (tc *transportCache) derive(base http.RoundTripper, dial func() dialFunc) (http.RoundTripper, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.transport != nil && tc.base == base {
+		return tc.transport, nil
+	}
+
+	t, ok := base.(*http.Transport)
+	if base == nil {
+		t, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return nil, ErrUnsupportedTransport
+	}
+
+	copied := t.Clone()
+	copied.DialContext = dial()
+	tc.base = base
+	tc.transport = copied
+
+	return copied, nil
+}
@@ -0,0 +1,69 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidDialRetry when negative dial retry settings are provided.
+func TestInvalidDialRetryOption(t *testing.T) {
+	if _, err := NewClient(WithDialRetry(DialRetry{Rounds: -1})); !errors.Is(err, ErrInvalidDialRetry) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with dial retry should dial connections with the retrying dialer.
+func TestDialRetry(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: &http.Transport{}}),
+		WithDialRetry(DialRetry{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+
+	if _, err := c.Get(closed.URL); err == nil {
+		t.Error("request to closed server succeeded")
+	}
+
+	unsupported, err := c.With(WithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}))
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+	if _, err := unsupported.Get(s.URL); !IsPermanent(err) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// alternateFamilies function should alternate IPv6 and IPv4 addresses starting with the family of the first address.
+func TestAlternateFamilies(t *testing.T) {
+	addrs := []net.IPAddr{
+		{IP: net.ParseIP("::1")},
+		{IP: net.ParseIP("::2")},
+		{IP: net.ParseIP("127.0.0.1")},
+		{IP: net.ParseIP("127.0.0.2")},
+	}
+
+	ordered := alternateFamilies(addrs)
+	expected := []string{"::1", "127.0.0.1", "::2", "127.0.0.2"}
+	for i := range expected {
+		if ordered[i].IP.String() != expected[i] {
+			t.Errorf("unexpected order, %v", ordered)
+			break
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net"
+)
+
+// dns errors
+var (
+	ErrNilFallbackResolver  = errors.New("fallback resolver is nil")
+	ErrUnsupportedTransport = errors.New("transport is not an *http.Transport")
+)
+
+// WithRetryDNSNotFound configures whether attempts failing because their host does not exist, NXDOMAIN responses, are retried.
+// They are not retried by default, since a mistyped hostname does not start resolving, while temporary DNS failures, such as SERVFAIL responses
+// and timeouts, are retried like other network failures.
+func WithRetryDNSNotFound(retry bool) Option {
+	return named("WithRetryDNSNotFound", func(c *Client) error {
+		c.retryDNSNotFound = retry
+
+		return nil
+	})
+}
+
+// WithFallbackResolver configures client to resolve hosts with provided resolver in attempts following an attempt which failed to resolve its host,
+// for example a resolver querying another DNS server. It requires the transport of client's http client to be an *http.Transport, or nil,
+// whose dialer is replaced in attempts using the fallback resolver. Hosts are resolved with the transport's resolver by default.
+func WithFallbackResolver(resolver *net.Resolver) Option {
+	return named("WithFallbackResolver", func(c *Client) error {
+		if resolver == nil {
+			return ErrNilFallbackResolver
+		}
+
+		c.fallbackResolver = &fallbackResolver{resolver: resolver}
+
+		return nil
+	})
+}
+
+// dnsError returns the dns error in provided error's chain, it reports whether there is one.
+func dnsError(err error) (*net.DNSError, bool) {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		return nil, false
+	}
+
+	return dnsErr, true
+}
+
+// classifyDNS records in call state whether provided error of call's current attempt is a dns failure, it returns failures of hosts which do not exist
+// as permanent unless client retries them.
+func (c *Client) classifyDNS(cl *call, err error) error {
+	dnsErr, ok := dnsError(err)
+	if !ok {
+		return err
+	}
+
+	cl.dnsFailed = true
+	if dnsErr.IsNotFound && !c.retryDNSNotFound {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+// fallbackResolver holds a fallback resolver and the transport derived from the transport of client's http client to use it.
+type fallbackResolver struct {
+	resolver *net.Resolver
+	cache    transportCache
+}
@@ -0,0 +1,81 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// NewClient function should return ErrNilFallbackResolver when nil fallback resolver is provided.
+func TestNilFallbackResolverOption(t *testing.T) {
+	if _, err := NewClient(WithFallbackResolver(nil)); !errors.Is(err, ErrNilFallbackResolver) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client should not retry hosts which do not exist unless configured and should retry temporary dns failures.
+func TestDNSErrors(t *testing.T) {
+	var dnsErr *net.DNSError
+	attempts := 0
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, dnsErr
+	})
+
+	tests := []struct {
+		err      *net.DNSError
+		retry    bool
+		attempts int
+	}{
+		{err: &net.DNSError{Err: "no such host", Name: "typo.example.com", IsNotFound: true}, attempts: 1},
+		{err: &net.DNSError{Err: "no such host", Name: "typo.example.com", IsNotFound: true}, retry: true, attempts: 3},
+		{err: &net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}, attempts: 3},
+	}
+	for _, test := range tests {
+		c, err := NewClient(
+			WithHTTPClient(&http.Client{Transport: transport}),
+			WithMaxReqCount(3),
+			WithRetryDNSNotFound(test.retry),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		dnsErr, attempts = test.err, 0
+		if _, err := c.Get("http://" + test.err.Name); !errors.As(err, &dnsErr) || attempts != test.attempts {
+			t.Errorf("unexpected error or attempt count, %v, %d", err, attempts)
+		}
+	}
+}
+
+// Do method of a client with a fallback resolver should resolve hosts with it after a dns failure.
+func TestFallbackResolver(t *testing.T) {
+	var fallbackUsed int32
+	fallback := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			atomic.StoreInt32(&fallbackUsed, 1)
+			return nil, errors.New("fallback dns server is not reachable")
+		},
+	}
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return nil, &net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}
+			},
+		}}),
+		WithMaxReqCount(2),
+		WithFallbackResolver(fallback),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if _, err := c.Get("http://api.example.com"); err == nil || atomic.LoadInt32(&fallbackUsed) != 1 {
+		t.Errorf("fallback resolver is not used, %v", err)
+	}
+}
@@ -0,0 +1,426 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// endpoint errors
+var (
+	ErrInvalidEndpoints = errors.New("endpoint host must not be empty and endpoints must be absolute urls")
+	ErrInvalidEjection  = errors.New("ejection failures and duration must be greater than zero")
+	ErrNilAffinityKey   = errors.New("affinity key is nil")
+	ErrNoEndpoints      = errors.New("no endpoints are available")
+)
+
+// default endpoint ejection settings
+const (
+	defaultEjectionFailures = 3
+	defaultEjectionDuration = 30 * time.Second
+)
+
+// WithEndpoints configures client to balance attempts of requests sent with Do and methods built on it to provided logical host, such as
+// "http://api.internal/v1/users" for host "api.internal", across provided endpoint base urls. Scheme and host of an attempt are replaced with
+// those of its endpoint and its path is appended to endpoint's path. Endpoints are chosen in round robin order, endpoints failing consecutively
+// are ejected for a while, see WithEndpointEjection, and every endpoint is used when all of them are ejected. Requests are not balanced by default.
+func WithEndpoints(host string, endpoints ...string) Option {
+	return named("WithEndpoints", func(c *Client) error {
+		if host == "" || len(endpoints) == 0 {
+			return ErrInvalidEndpoints
+		}
+
+		static := make([]Endpoint, 0, len(endpoints))
+		for _, e := range endpoints {
+			static = append(static, Endpoint{URL: e})
+		}
+		parsed, err := parseEndpoints(static)
+		if err != nil {
+			return err
+		}
+
+		c.endpoints = &endpointPool{
+			host:      strings.ToLower(host),
+			endpoints: parsed,
+		}
+
+		return nil
+	})
+}
+
+// WithEndpointEjection configures endpoints balanced with WithEndpoints to be ejected for provided duration after provided number of consecutive failed attempts.
+// Endpoints are ejected for 30 seconds after 3 consecutive failures by default.
+func WithEndpointEjection(failures int, duration time.Duration) Option {
+	return named("WithEndpointEjection", func(c *Client) error {
+		if failures <= 0 || duration <= 0 {
+			return ErrInvalidEjection
+		}
+
+		c.ejectionFailures = failures
+		c.ejectionDuration = duration
+
+		return nil
+	})
+}
+
+// AffinityKey returns the affinity key of a request, requests with the same non-empty key prefer the same endpoint.
+type AffinityKey func(req *http.Request) string
+
+// HeaderAffinity returns an affinity key reading provided request header.
+func HeaderAffinity(name string) AffinityKey {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// CookieAffinity returns an affinity key reading provided request cookie.
+func CookieAffinity(name string) AffinityKey {
+	return func(req *http.Request) string {
+		cookie, err := req.Cookie(name)
+		if err != nil {
+			return ""
+		}
+
+		return cookie.Value
+	}
+}
+
+// PathAffinity returns an affinity key reading request path.
+func PathAffinity() AffinityKey {
+	return func(req *http.Request) string {
+		return req.URL.Path
+	}
+}
+
+// WithAffinity configures client to send requests with the same affinity key, and their retries, to the same endpoint balanced with WithEndpoints
+// until the endpoint is ejected. Keys are mapped to endpoints with rendezvous hashing, so that most keys keep their endpoint when endpoints change.
+// Requests with an empty key are balanced in round robin order.
+func WithAffinity(key AffinityKey) Option {
+	return named("WithAffinity", func(c *Client) error {
+		if key == nil {
+			return ErrNilAffinityKey
+		}
+
+		c.affinity = key
+
+		return nil
+	})
+}
+
+// endpointPool holds endpoints of a logical host, endpoints of a pool with a resolver are resolved again every refresh interval,
+// or pushed by the resolver while it is watching.
+type endpointPool struct {
+	host     string
+	resolver EndpointResolver
+	interval time.Duration
+	// ctx is the context of watching, it is cancelled when the client configured with the pool is closed.
+	ctx context.Context
+
+	refreshMu sync.Mutex
+	refreshAt time.Time
+	watching  bool
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+}
+
+// endpoint holds an endpoint, its weight, priority, lower priorities are preferred, and locality, and its health.
+// Current is the current weight of smooth weighted round robin selection and inflight is the number of attempts in flight, it is updated atomically.
+type endpoint struct {
+	url          *url.URL
+	weight       int
+	priority     int
+	region       string
+	zone         string
+	current      int
+	inflight     int64
+	failures     int
+	ejectedUntil time.Time
+}
+
+// refresh resolves endpoints of provided pool again when they are stale and starts watching them when pool's resolver is a watcher
+// and its client is not closed.
+// Previous endpoints are kept when resolving fails, the error is returned only when the pool has no endpoints.
+func (p *endpointPool) refresh(ctx context.Context, now time.Time) error {
+	if p.resolver == nil {
+		return nil
+	}
+
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	if w, ok := p.resolver.(EndpointWatcher); ok && !p.watching && p.ctx.Err() == nil {
+		p.watching = true
+		go p.watch(w)
+	}
+	if now.Before(p.refreshAt) || (p.watching && p.size() > 0) {
+		return nil
+	}
+
+	var resolved []*endpoint
+	endpoints, err := p.resolver.Resolve(ctx)
+	if err == nil {
+		resolved, err = parseEndpoints(endpoints)
+	}
+	if err == nil && len(resolved) == 0 {
+		err = fmt.Errorf("%w, %s", ErrNoEndpoints, p.host)
+	}
+	if err != nil {
+		if p.size() == 0 {
+			return err
+		}
+		p.refreshAt = now.Add(p.interval)
+		return nil
+	}
+
+	p.update(resolved)
+	p.refreshAt = now.Add(p.interval)
+
+	return nil
+}
+
+// watch updates endpoints of provided pool with endpoints pushed by provided watcher until watching stops or pool's context is done,
+// endpoints are resolved periodically afterwards.
+func (p *endpointPool) watch(w EndpointWatcher) {
+	w.Watch(p.ctx, func(endpoints []Endpoint) {
+		if resolved, err := parseEndpoints(endpoints); err == nil && len(resolved) > 0 {
+			p.update(resolved)
+		}
+	})
+
+	p.refreshMu.Lock()
+	p.watching = false
+	p.refreshAt = time.Time{}
+	p.refreshMu.Unlock()
+}
+
+// size returns the number of endpoints of provided pool.
+func (p *endpointPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.endpoints)
+}
+
+// update replaces endpoints of provided pool with provided endpoints, endpoints which are already in the pool keep their health.
+func (p *endpointPool) update(resolved []*endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	previous := make(map[string]*endpoint, len(p.endpoints))
+	for _, e := range p.endpoints {
+		previous[e.url.String()] = e
+	}
+	for i, e := range resolved {
+		if old, ok := previous[e.url.String()]; ok && old.region == e.region && old.zone == e.zone {
+			old.weight, old.priority = e.weight, e.priority
+			resolved[i] = old
+		}
+	}
+	p.endpoints = resolved
+}
+
+// balance returns a copy of provided request sent to an endpoint of client's endpoint pool, recording the endpoint in call state.
+// It returns provided request when it is not sent to pool's host.
+func (c *Client) balance(cl *call, req *http.Request) (*http.Request, error) {
+	p := c.endpoints
+	if !strings.EqualFold(req.URL.Host, p.host) {
+		return req, nil
+	}
+	if err := p.refresh(req.Context(), c.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	var key string
+	if c.affinity != nil {
+		c.hook(func() {
+			key = c.affinity(req)
+		})
+	}
+
+	minRank := localZone
+	if c.localAttempts > 0 && cl.localFailures >= c.localAttempts {
+		minRank = remoteRegion
+	}
+
+	var choose func(candidates []*endpoint) *endpoint
+	if c.endpointSelection != SelectRoundRobin {
+		choose = c.chooseEndpoint
+	}
+
+	e := cl.pinned
+	if e == nil || !p.contains(e) {
+		e = p.pick(key, c.clock.Now(), c.localityRank, minRank, choose, cl.avoided)
+	}
+	if e == nil {
+		return nil, fmt.Errorf("%w, %s", ErrNoEndpoints, p.host)
+	}
+	cl.endpoint = e
+	atomic.AddInt64(&e.inflight, 1)
+
+	u := *req.URL
+	u.Scheme = e.url.Scheme
+	u.Host = e.url.Host
+	u.Path = strings.TrimSuffix(e.url.Path, "/") + req.URL.Path
+	u.RawPath = ""
+
+	balanced := req.WithContext(req.Context())
+	balanced.URL = &u
+	balanced.Host = ""
+
+	return balanced, nil
+}
+
+// pick returns the endpoint of provided affinity key at provided time, or the next endpoint in smooth weighted round robin order when key is empty.
+// Ejected endpoints are skipped unless every endpoint is ejected, endpoints ranked lower than provided minimum rank are skipped unless every
+// remaining endpoint is, and only endpoints of the lowest rank and then of the lowest priority among the rest are picked.
+// Provided choose function picks among them instead of round robin order when it is not nil. Provided avoided endpoint is skipped unless it is the only one.
+func (p *endpointPool) pick(key string, now time.Time, rank func(e *endpoint) int, minRank int, choose func(candidates []*endpoint) *endpoint, avoided *endpoint) *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endpoints := p.endpoints
+	if avoided != nil && len(endpoints) > 1 {
+		endpoints = make([]*endpoint, 0, len(p.endpoints))
+		for _, e := range p.endpoints {
+			if e != avoided {
+				endpoints = append(endpoints, e)
+			}
+		}
+	}
+
+	healthy := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if !now.Before(e.ejectedUntil) {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = endpoints
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	ranked := make([]*endpoint, 0, len(healthy))
+	for _, e := range healthy {
+		if rank(e) >= minRank {
+			ranked = append(ranked, e)
+		}
+	}
+	if len(ranked) > 0 {
+		healthy = ranked
+	}
+	healthy = lowest(lowest(healthy, rank), func(e *endpoint) int {
+		return e.priority
+	})
+
+	if key == "" && choose != nil {
+		return choose(healthy)
+	}
+	if key == "" {
+		var picked *endpoint
+		total := 0
+		for _, e := range healthy {
+			e.current += e.weight
+			total += e.weight
+			if picked == nil || e.current > picked.current {
+				picked = e
+			}
+		}
+		picked.current -= total
+
+		return picked
+	}
+
+	var picked *endpoint
+	var best uint64
+	for _, e := range healthy {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte(e.url.String()))
+		if score := h.Sum64(); picked == nil || score > best {
+			picked, best = e, score
+		}
+	}
+
+	return picked
+}
+
+// contains reports whether provided endpoint is one of the endpoints of provided pool.
+func (p *endpointPool) contains(e *endpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, candidate := range p.endpoints {
+		if candidate == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lowest returns provided endpoints with the lowest value of provided function.
+func lowest(endpoints []*endpoint, value func(e *endpoint) int) []*endpoint {
+	var selected []*endpoint
+	for _, e := range endpoints {
+		if len(selected) > 0 && value(e) > value(selected[0]) {
+			continue
+		}
+		if len(selected) > 0 && value(e) < value(selected[0]) {
+			selected = selected[:0]
+		}
+		selected = append(selected, e)
+	}
+
+	return selected
+}
+
+// recordEndpoint updates health of the endpoint of call's current attempt with provided error of the attempt, ejecting the endpoint after consecutive failures.
+// Failures of attempts sent to local endpoints are counted in call state.
+func (c *Client) recordEndpoint(cl *call, err error) {
+	e := cl.endpoint
+	if err != nil && c.localityRank(e) < remoteRegion {
+		cl.localFailures++
+	}
+	if c.failoverPolicy != nil {
+		c.failover(cl, err)
+	}
+
+	failures, duration := c.ejectionFailures, c.ejectionDuration
+	if failures == 0 {
+		failures = defaultEjectionFailures
+	}
+	if duration == 0 {
+		duration = defaultEjectionDuration
+	}
+
+	p := c.endpoints
+	p.mu.Lock()
+	if err == nil {
+		e.failures = 0
+		p.mu.Unlock()
+		return
+	}
+
+	e.failures++
+	ejected := e.failures >= failures
+	if ejected {
+		e.failures = 0
+		e.ejectedUntil = c.clock.Now().Add(duration)
+	}
+	until := e.ejectedUntil
+	p.mu.Unlock()
+
+	if ejected && c.events != nil {
+		c.emitCircuitOpened(cl, e.url, until, err)
+	}
+}
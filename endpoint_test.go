@@ -0,0 +1,83 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return endpoint errors when invalid endpoint settings are provided.
+func TestInvalidEndpointOptions(t *testing.T) {
+	if _, err := NewClient(WithEndpoints("api.internal", "/relative")); err == nil {
+		t.Error("relative endpoint is accepted")
+	}
+	if _, err := NewClient(WithEndpointEjection(0, time.Second)); !errors.Is(err, ErrInvalidEjection) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithAffinity(nil)); !errors.Is(err, ErrNilAffinityKey) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with endpoints should balance requests in round robin order and keep requests with the same affinity key on one endpoint until it is ejected.
+func TestEndpointAffinity(t *testing.T) {
+	var hits []int
+	failing := -1
+	urls := make([]string, 3)
+	for i := range urls {
+		i := i
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits = append(hits, i)
+			if i == failing {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer s.Close()
+		urls[i] = s.URL
+	}
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithEndpoints("api.internal", urls...),
+		WithEndpointEjection(1, time.Minute),
+		WithAffinity(HeaderAffinity("X-Session")),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	send := func(session string) {
+		req, _ := http.NewRequest(http.MethodGet, "http://api.internal/users", nil)
+		req.Header.Set("X-Session", session)
+		res, err := c.Do(req)
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+		}
+		discard(res)
+	}
+
+	for i := 0; i < 3; i++ {
+		send("")
+	}
+	if len(hits) != 3 || hits[0] == hits[1] || hits[1] == hits[2] || hits[0] == hits[2] {
+		t.Errorf("requests are not balanced, %v", hits)
+	}
+
+	hits = nil
+	send("user-1")
+	send("user-1")
+	if len(hits) != 2 || hits[0] != hits[1] {
+		t.Errorf("requests are not sticky, %v", hits)
+	}
+
+	sticky := hits[0]
+	failing = sticky
+	hits = nil
+	send("user-1")
+	send("user-1")
+	if len(hits) != 3 || hits[0] != sticky || hits[1] == sticky || hits[2] != hits[1] {
+		t.Errorf("requests do not move from ejected endpoint, %v", hits)
+	}
+}
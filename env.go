@@ -0,0 +1,147 @@
+package retryablehttp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// environment errors
+var (
+	ErrInvalidEnv = errors.New("environment variable is not valid")
+)
+
+// DefaultEnvPrefix is the environment variable prefix used when an empty prefix is provided.
+const DefaultEnvPrefix = "RETRYABLEHTTP"
+
+// NewClientFromEnv creates a client from environment variables with provided prefix, provided options are applied after configuration.
+// Unset variables take default values. Supported variables, shown with DefaultEnvPrefix, are:
+//
+//	RETRYABLEHTTP_MAX_REQ_COUNT             maximum request count, such as 3
+//	RETRYABLEHTTP_BACKOFF                   backoff duration, such as 100ms
+//	RETRYABLEHTTP_JITTER                    backoff jitter between 0 and 1, such as 0.2
+//	RETRYABLEHTTP_TIMEOUT                   timeout of each attempt, such as 2s
+//	RETRYABLEHTTP_RETRYABLE_STATUS_CODES    comma separated retryable status codes, such as 429,503
+//
+// Parse and validation problems of all variables are returned at once as *ConfigError, parse problems wrap ErrInvalidEnv.
+func NewClientFromEnv(prefix string, opts ...Option) (*Client, error) {
+	cfg, err := ConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientFromConfig(cfg, opts...)
+}
+
+// ConfigFromEnv reads and validates configuration from environment variables with provided prefix, see NewClientFromEnv.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	e := envReader{prefix: strings.TrimSuffix(prefix, "_") + "_"}
+
+	cfg := Config{
+		MaxReqCount:          e.int("MAX_REQ_COUNT"),
+		Backoff:              e.duration("BACKOFF"),
+		Jitter:               e.float("JITTER"),
+		Timeout:              e.duration("TIMEOUT"),
+		RetryableStatusCodes: e.ints("RETRYABLE_STATUS_CODES"),
+	}
+
+	errs := e.errs
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err.(*ConfigError).Errs...)
+	}
+	if len(errs) > 0 {
+		return Config{}, &ConfigError{Errs: errs}
+	}
+
+	return cfg, nil
+}
+
+// envReader reads environment variables with a prefix and collects parse errors.
+type envReader struct {
+	prefix string
+	errs   []error
+}
+
+// lookup returns trimmed value of environment variable with provided name.
+func (e *envReader) lookup(name string) (string, string, bool) {
+	name = e.prefix + name
+	value, ok := os.LookupEnv(name)
+
+	return name, strings.TrimSpace(value), ok && strings.TrimSpace(value) != ""
+}
+
+// fail records a parse error of provided variable.
+func (e *envReader) fail(name, value string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("%w, %s=%q: %v", ErrInvalidEnv, name, value, err))
+}
+
+// int parses provided variable as an integer.
+func (e *envReader) int(name string) int {
+	name, value, ok := e.lookup(name)
+	if !ok {
+		return 0
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(name, value, err)
+	}
+
+	return i
+}
+
+// float parses provided variable as a floating point number.
+func (e *envReader) float(name string) float64 {
+	name, value, ok := e.lookup(name)
+	if !ok {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.fail(name, value, err)
+	}
+
+	return f
+}
+
+// duration parses provided variable as a duration.
+func (e *envReader) duration(name string) time.Duration {
+	name, value, ok := e.lookup(name)
+	if !ok {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(name, value, err)
+	}
+
+	return d
+}
+
+// ints parses provided variable as comma separated integers.
+func (e *envReader) ints(name string) []int {
+	name, value, ok := e.lookup(name)
+	if !ok {
+		return nil
+	}
+
+	var ints []int
+	for _, field := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			e.fail(name, value, err)
+			return nil
+		}
+		ints = append(ints, i)
+	}
+
+	return ints
+}
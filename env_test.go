@@ -0,0 +1,75 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// ConfigFromEnv function should read configuration from prefixed environment variables.
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RETRYABLEHTTP_MAX_REQ_COUNT", "3")
+	t.Setenv("RETRYABLEHTTP_BACKOFF", "100ms")
+	t.Setenv("RETRYABLEHTTP_JITTER", "0.2")
+	t.Setenv("RETRYABLEHTTP_TIMEOUT", "2s")
+	t.Setenv("RETRYABLEHTTP_RETRYABLE_STATUS_CODES", "429, 503")
+
+	cfg, err := ConfigFromEnv("")
+	if err != nil {
+		t.Fatalf("reading config failed, %s", err.Error())
+	}
+	if cfg.MaxReqCount != 3 || cfg.Backoff != 100*time.Millisecond || cfg.Jitter != 0.2 || cfg.Timeout != 2*time.Second ||
+		len(cfg.RetryableStatusCodes) != 2 || cfg.RetryableStatusCodes[1] != 503 {
+		t.Errorf("unexpected config, %+v", cfg)
+	}
+}
+
+// NewClientFromEnv function should report parse and validation problems of all variables at once.
+func TestNewClientFromEnvErrors(t *testing.T) {
+	t.Setenv("APP_MAX_REQ_COUNT", "three")
+	t.Setenv("APP_BACKOFF", "-1s")
+	t.Setenv("APP_TIMEOUT", "2")
+	t.Setenv("APP_RETRYABLE_STATUS_CODES", "42")
+
+	_, err := NewClientFromEnv("APP")
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || len(configErr.Errs) != 4 {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	for _, target := range []error{ErrInvalidEnv, ErrInvalidBackoff, ErrInvalidStatusCode} {
+		if !errors.Is(err, target) {
+			t.Errorf("missing error, %v", target)
+		}
+	}
+}
+
+// Do method of a client created from environment variables should use configured maximum request count.
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("APP_MAX_REQ_COUNT", "2")
+
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClientFromEnv("APP_")
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
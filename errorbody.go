@@ -0,0 +1,216 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// error body errors
+var (
+	ErrNilErrorBodyParser = errors.New("error body parser is nil")
+)
+
+// maxErrorBodyBytes is the maximum number of bytes of an unsuccessful response's body passed to error body parser.
+const maxErrorBodyBytes = 64 << 10
+
+// RetryHint represents retry hints found in the body of an unsuccessful response.
+// Retryable is nil when the body does not tell whether the request can be retried and RetryAfter is zero when the body does not suggest a delay.
+type RetryHint struct {
+	Retryable  *bool
+	RetryAfter time.Duration
+}
+
+// ErrorBodyParser represents a parser of bodies of unsuccessful responses, it reports whether provided body contains retry hints.
+type ErrorBodyParser interface {
+	ParseErrorBody(res *http.Response, body []byte) (RetryHint, bool)
+}
+
+// ErrorBodyParserFunc is an adapter to use a function as error body parser.
+type ErrorBodyParserFunc func(res *http.Response, body []byte) (RetryHint, bool)
+
+// ParseErrorBody calls f(res, body).
+func (f ErrorBodyParserFunc) ParseErrorBody(res *http.Response, body []byte) (RetryHint, bool) {
+	return f(res, body)
+}
+
+// WithErrorBodyParser configures client to parse bodies of unsuccessful responses rejected by response handler with provided parser.
+// Failures hinted as not retryable are not retried and a hinted delay is waited at least before the next attempt, maximum backoff limits still apply.
+// At most 64 KiB of a body is parsed, the body stays readable. Error bodies are not parsed by default.
+func WithErrorBodyParser(parser ErrorBodyParser) Option {
+	return named("WithErrorBodyParser", func(c *Client) error {
+		if parser == nil {
+			return ErrNilErrorBodyParser
+		}
+
+		c.errorBodyParser = parser
+
+		return nil
+	})
+}
+
+// parseErrorBody feeds retry hints of provided unsuccessful response into call's current attempt, it returns provided error as permanent when
+// the failure is hinted as not retryable.
+func (c *Client) parseErrorBody(cl *call, res *http.Response, err error) error {
+	if res == nil || res.Body == nil || res.Body == http.NoBody || IsPermanent(err) {
+		return err
+	}
+
+	body, readErr := PeekBody(res, maxErrorBodyBytes)
+	if readErr != nil {
+		return err
+	}
+
+	hint, ok := c.errorBodyParser.ParseErrorBody(res, body)
+	if !ok {
+		return err
+	}
+
+	cl.retryAfter = hint.RetryAfter
+	if hint.Retryable != nil && !*hint.Retryable {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+// JSONErrorBodyParser parses retry hints of JSON error bodies, such as {"error":{"retryable":true,"retry_after_ms":500}},
+// from fields at dotted paths, such as "error.retryable" and "error.retry_after_ms". Empty paths are not parsed.
+// Retryable field must be a boolean, retry after field must be a number or a numeric string of RetryAfterUnit, milliseconds when it is zero.
+type JSONErrorBodyParser struct {
+	RetryablePath  string
+	RetryAfterPath string
+	RetryAfterUnit time.Duration
+}
+
+// ParseErrorBody parses retry hints of provided JSON body.
+func (p JSONErrorBodyParser) ParseErrorBody(res *http.Response, body []byte) (RetryHint, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return RetryHint{}, false
+	}
+
+	lookup := func(path string) (interface{}, bool) {
+		if path == "" {
+			return nil, false
+		}
+
+		v := doc
+		for _, key := range strings.Split(path, ".") {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if v, ok = m[key]; !ok {
+				return nil, false
+			}
+		}
+
+		return v, true
+	}
+
+	var hint RetryHint
+	var found bool
+	if v, ok := lookup(p.RetryablePath); ok {
+		if retryable, ok := v.(bool); ok {
+			hint.Retryable = &retryable
+			found = true
+		}
+	}
+	if v, ok := lookup(p.RetryAfterPath); ok {
+		switch v := v.(type) {
+		case float64:
+			hint.RetryAfter = retryAfter(v, p.RetryAfterUnit)
+			found = true
+		case string:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				hint.RetryAfter = retryAfter(n, p.RetryAfterUnit)
+				found = true
+			}
+		}
+	}
+
+	return hint, found
+}
+
+// XMLErrorBodyParser parses retry hints of XML error bodies from text of elements at dotted paths starting from the root element,
+// such as "Error.Retryable" and "Error.RetryAfterMs". Empty paths are not parsed. Retryable element must be a boolean,
+// retry after element must be a number of RetryAfterUnit, milliseconds when it is zero.
+type XMLErrorBodyParser struct {
+	RetryablePath  string
+	RetryAfterPath string
+	RetryAfterUnit time.Duration
+}
+
+// ParseErrorBody parses retry hints of provided XML body.
+func (p XMLErrorBodyParser) ParseErrorBody(res *http.Response, body []byte) (RetryHint, bool) {
+	texts := make(map[string]string)
+	var path []string
+
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return RetryHint{}, false
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			path = append(path, token.Name.Local)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			key := strings.Join(path, ".")
+			if key == p.RetryablePath || key == p.RetryAfterPath {
+				texts[key] += string(token)
+			}
+		}
+	}
+
+	var hint RetryHint
+	var found bool
+	if text, ok := texts[p.RetryablePath]; ok && p.RetryablePath != "" {
+		if retryable, err := strconv.ParseBool(strings.TrimSpace(text)); err == nil {
+			hint.Retryable = &retryable
+			found = true
+		}
+	}
+	if text, ok := texts[p.RetryAfterPath]; ok && p.RetryAfterPath != "" {
+		if n, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			hint.RetryAfter = retryAfter(n, p.RetryAfterUnit)
+			found = true
+		}
+	}
+
+	return hint, found
+}
+
+// retryAfter converts provided number of provided unit to a duration, unit is milliseconds when it is zero.
+func retryAfter(n float64, unit time.Duration) time.Duration {
+	if unit == 0 {
+		unit = time.Millisecond
+	}
+	if n <= 0 {
+		return 0
+	}
+
+	return time.Duration(n * float64(unit))
+}
+
+// hinted returns provided backoff extended to the delay hinted by error body of call's current attempt, negative backoffs stopping retries are kept.
+func (c *Client) hinted(cl *call, backoff time.Duration) time.Duration {
+	if backoff >= 0 && cl.retryAfter > backoff {
+		return cl.retryAfter
+	}
+
+	return backoff
+}
@@ -0,0 +1,73 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrNilErrorBodyParser when nil error body parser is provided.
+func TestNilErrorBodyParserOption(t *testing.T) {
+	if _, err := NewClient(WithErrorBodyParser(nil)); !errors.Is(err, ErrNilErrorBodyParser) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with an error body parser should wait hinted delays and stop retrying failures hinted as not retryable.
+func TestErrorBodyParser(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if reqCount == 1 {
+			w.Write([]byte(`{"error":{"retryable":true,"retry_after_ms":500}}`))
+			return
+		}
+		w.Write([]byte(`{"error":{"retryable":false}}`))
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithMaxReqCount(5),
+		WithBackoff(100*time.Millisecond),
+		WithClock(clock),
+		WithSleeper(clock),
+		WithErrorBodyParser(JSONErrorBodyParser{RetryablePath: "error.retryable", RetryAfterPath: "error.retry_after_ms"}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 2 || clock.Now().Sub(time.Time{}) != 500*time.Millisecond {
+		t.Errorf("unexpected request count or backoff, %d, %s", reqCount, clock.Now().Sub(time.Time{}))
+	}
+	if res != nil {
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != `{"error":{"retryable":false}}` {
+			t.Errorf("unexpected body, %s", body)
+		}
+	}
+}
+
+// ParseErrorBody method of XMLErrorBodyParser should parse retry hints from element text.
+func TestXMLErrorBodyParser(t *testing.T) {
+	p := XMLErrorBodyParser{RetryablePath: "Error.Retryable", RetryAfterPath: "Error.RetryAfter", RetryAfterUnit: time.Second}
+
+	hint, ok := p.ParseErrorBody(nil, []byte(`<Error><Code>Busy</Code><Retryable>true</Retryable><RetryAfter> 2 </RetryAfter></Error>`))
+	if !ok || hint.Retryable == nil || !*hint.Retryable || hint.RetryAfter != 2*time.Second {
+		t.Errorf("unexpected hint, %v, %v", hint, ok)
+	}
+
+	if _, ok := p.ParseErrorBody(nil, []byte(`<Error><Code>Busy</Code></Error>`)); ok {
+		t.Errorf("hint is found in body without hints")
+	}
+}
@@ -0,0 +1,207 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// event errors
+var (
+	ErrNilEventChannel    = errors.New("event channel is nil")
+	ErrInvalidEventPolicy = errors.New("event policy is not valid")
+)
+
+// EventPolicy represents behavior of event emission when event channel is full.
+type EventPolicy int
+
+// event policies
+const (
+	// EventPolicyDrop drops events when event channel is full, dropped events are counted in client's statistics.
+	EventPolicyDrop EventPolicy = iota
+	// EventPolicyBlock blocks attempts until event channel accepts events or their request's context is done, events of done requests are dropped.
+	EventPolicyBlock
+)
+
+// Event represents an event emitted by client, it is one of AttemptStarted, AttemptFinished, RetryScheduled, GaveUp and CircuitOpened.
+// Labels of events are the labels of their requests, see ContextWithLabels.
+type Event interface {
+	event()
+}
+
+// AttemptStarted is emitted before an attempt sends its request.
+type AttemptStarted struct {
+	Time    time.Time
+	Method  string
+	URL     string
+	Attempt int
+	Labels  map[string]string
+}
+
+// AttemptFinished is emitted after an attempt is handled.
+type AttemptFinished struct {
+	Time       time.Time
+	Method     string
+	URL        string
+	Attempt    int
+	StatusCode int
+	Latency    time.Duration
+	Outcome    Outcome
+	Err        error
+	Labels     map[string]string
+}
+
+// RetryScheduled is emitted before client sleeps for backoff duration.
+type RetryScheduled struct {
+	Time        time.Time
+	Method      string
+	URL         string
+	NextAttempt int
+	Backoff     time.Duration
+	Err         error
+	Labels      map[string]string
+}
+
+// GaveUp is emitted when client stops retrying a failed request.
+type GaveUp struct {
+	Time     time.Time
+	Method   string
+	URL      string
+	Attempts int
+	Err      error
+	Labels   map[string]string
+}
+
+// CircuitOpened is emitted when an endpoint or a canary is ejected after failed attempts, requests are not sent to Target until Until.
+// Err is the error of the attempt which ejected the target.
+type CircuitOpened struct {
+	Time   time.Time
+	Target string
+	Until  time.Time
+	Err    error
+	Labels map[string]string
+}
+
+func (AttemptStarted) event()  {}
+func (AttemptFinished) event() {}
+func (RetryScheduled) event()  {}
+func (GaveUp) event()          {}
+func (CircuitOpened) event()   {}
+
+// WithEventChannel configures client to emit events to provided channel with provided policy.
+// Method, url and error messages of events are redacted by client's redaction policy. Events are not emitted by default.
+func WithEventChannel(ch chan<- Event, policy EventPolicy) Option {
+	return named("WithEventChannel", func(c *Client) error {
+		if ch == nil {
+			return ErrNilEventChannel
+		}
+
+		if policy != EventPolicyDrop && policy != EventPolicyBlock {
+			return ErrInvalidEventPolicy
+		}
+
+		c.events = ch
+		c.eventPolicy = policy
+
+		return nil
+	})
+}
+
+// emit sends provided event of provided call to client's event channel according to client's event policy.
+// Blocked events are dropped when call's context is done.
+func (c *Client) emit(cl *call, e Event) {
+	if c.eventPolicy == EventPolicyBlock {
+		select {
+		case c.events <- e:
+		case <-cl.ctx.Done():
+			c.stats.droppedEvents.add(1)
+		}
+
+		return
+	}
+
+	select {
+	case c.events <- e:
+	default:
+		c.stats.droppedEvents.add(1)
+	}
+}
+
+// target returns method and redacted url of call's current request.
+func (c *Client) target(cl *call) (string, string) {
+	if cl.req == nil {
+		return "", ""
+	}
+
+	return cl.req.Method, c.redactor.url(cl.req.URL).String()
+}
+
+// emitAttemptStarted emits AttemptStarted event of call's current attempt.
+func (c *Client) emitAttemptStarted(cl *call) {
+	method, url := c.target(cl)
+	c.emit(cl, AttemptStarted{
+		Time:    cl.start,
+		Method:  method,
+		URL:     url,
+		Attempt: cl.attempt,
+		Labels:  cl.labels,
+	})
+}
+
+// emitAttemptFinished emits AttemptFinished event of call's current attempt.
+func (c *Client) emitAttemptFinished(cl *call, err error, outcome Outcome) {
+	method, url := c.target(cl)
+	e := AttemptFinished{
+		Time:    c.clock.Now(),
+		Method:  method,
+		URL:     url,
+		Attempt: cl.attempt,
+		Latency: cl.latency,
+		Outcome: outcome,
+		Err:     err,
+		Labels:  cl.labels,
+	}
+	if cl.res != nil {
+		e.StatusCode = cl.res.StatusCode
+	}
+
+	c.emit(cl, e)
+}
+
+// emitRetryScheduled emits RetryScheduled event before call's next attempt.
+func (c *Client) emitRetryScheduled(cl *call, backoff time.Duration, err error) {
+	method, url := c.target(cl)
+	c.emit(cl, RetryScheduled{
+		Time:        c.clock.Now(),
+		Method:      method,
+		URL:         url,
+		NextAttempt: cl.attempt + 1,
+		Backoff:     backoff,
+		Err:         err,
+		Labels:      cl.labels,
+	})
+}
+
+// emitGaveUp emits GaveUp event of call.
+func (c *Client) emitGaveUp(cl *call, err error) {
+	method, url := c.target(cl)
+	c.emit(cl, GaveUp{
+		Time:     c.clock.Now(),
+		Method:   method,
+		URL:      url,
+		Attempts: cl.attempt,
+		Err:      err,
+		Labels:   cl.labels,
+	})
+}
+
+// emitCircuitOpened emits CircuitOpened event of provided target ejected until provided time by provided error of call's current attempt.
+func (c *Client) emitCircuitOpened(cl *call, target *url.URL, until time.Time, err error) {
+	c.emit(cl, CircuitOpened{
+		Time:   c.clock.Now(),
+		Target: c.redactor.url(target).String(),
+		Until:  until,
+		Err:    err,
+		Labels: cl.labels,
+	})
+}
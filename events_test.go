@@ -0,0 +1,179 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidEventPolicy when unknown event policy is provided.
+func TestInvalidEventPolicyOption(t *testing.T) {
+	_, err := NewClient(
+		WithEventChannel(make(chan Event), EventPolicy(-1)),
+	)
+	if !errors.Is(err, ErrInvalidEventPolicy) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with event channel should emit typed events of every attempt and giving up.
+func TestEventChannel(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	events := make(chan Event, 16)
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithEventChannel(events, EventPolicyDrop),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err == nil {
+		t.Error("unexpected nil error")
+	}
+	close(events)
+
+	var kinds []string
+	for e := range events {
+		switch e := e.(type) {
+		case AttemptStarted:
+			kinds = append(kinds, "started")
+		case AttemptFinished:
+			kinds = append(kinds, "finished")
+			if e.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("unexpected status code, %d", e.StatusCode)
+			}
+		case RetryScheduled:
+			kinds = append(kinds, "scheduled")
+			if e.NextAttempt != 2 {
+				t.Errorf("unexpected next attempt, %d", e.NextAttempt)
+			}
+		case GaveUp:
+			kinds = append(kinds, "gave up")
+			if e.Attempts != 2 || e.URL != s.URL {
+				t.Errorf("unexpected event, %+v", e)
+			}
+		}
+	}
+
+	expected := "started finished scheduled started finished gave up"
+	if got := strings.Join(kinds, " "); got != expected {
+		t.Errorf("unexpected events, %s", got)
+	}
+}
+
+// Do method of a client with event channel and drop policy should count dropped events when channel is full.
+func TestEventChannelDrop(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithEventChannel(make(chan Event), EventPolicyDrop),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+	if dropped := c.Stats().DroppedEvents; dropped != 2 {
+		t.Errorf("unexpected dropped event count, %d", dropped)
+	}
+}
+
+// Do method of a client with event channel and block policy should stop waiting for the channel when request's context is done.
+func TestEventChannelBlockContext(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithEventChannel(make(chan Event), EventPolicyBlock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		res, _ := c.Do(req)
+		discard(res)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request is blocked by event channel after its context is done")
+	}
+	if dropped := c.Stats().DroppedEvents; dropped == 0 {
+		t.Error("blocked events are not counted as dropped")
+	}
+}
+
+// Do method of a client with event channel should emit CircuitOpened event when an endpoint is ejected.
+func TestEventChannelCircuitOpened(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	events := make(chan Event, 16)
+	c, err := NewClient(
+		WithEndpoints("api.internal", s.URL),
+		WithEndpointEjection(2, time.Minute),
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithEventChannel(events, EventPolicyDrop),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://api.internal/users", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	res, _ := c.Do(req)
+	discard(res)
+	close(events)
+
+	var opened []CircuitOpened
+	for e := range events {
+		if e, ok := e.(CircuitOpened); ok {
+			opened = append(opened, e)
+		}
+	}
+	if len(opened) != 1 || opened[0].Target != s.URL || opened[0].Until.Sub(opened[0].Time) > time.Minute || opened[0].Until.Sub(opened[0].Time) < 59*time.Second || opened[0].Err == nil {
+		t.Errorf("unexpected circuit opened events, %+v", opened)
+	}
+}
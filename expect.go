@@ -0,0 +1,74 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// expect errors
+var (
+	ErrInvalidExpectContinue = errors.New("expect continue body size must not be negative")
+)
+
+// WithExpectContinue configures client to send attempts with bodies of at least provided size, or of unknown size, with Expect: 100-continue header,
+// so that servers can reject requests before their bodies are uploaded. Transport of the http client must have ExpectContinueTimeout set, as http.DefaultTransport does.
+// An attempt rejected before its body is uploaded is not counted as a full attempt, it does not consume maximum request count when it is retried,
+// up to maximum request count of additional attempts. Attempts responded with 417 Expectation Failed are retried without the header and without backoff.
+func WithExpectContinue(minBodySize int64) Option {
+	return named("WithExpectContinue", func(c *Client) error {
+		if minBodySize < 0 {
+			return ErrInvalidExpectContinue
+		}
+
+		c.expectContinue = true
+		c.expectMinBody = minBodySize
+
+		return nil
+	})
+}
+
+// expect returns a copy of provided request with Expect: 100-continue header and a body recording whether it is uploaded,
+// it returns provided request when its body is too small or when server failed the expectation in an earlier attempt.
+func (c *Client) expect(cl *call, req *http.Request) *http.Request {
+	if cl.expectFailed || req.Body == nil || req.Body == http.NoBody || (req.ContentLength >= 0 && req.ContentLength < c.expectMinBody) {
+		return req
+	}
+
+	expected := req.WithContext(req.Context())
+	expected.Header = req.Header.Clone()
+	if expected.Header == nil {
+		expected.Header = make(http.Header)
+	}
+	expected.Header.Set("Expect", "100-continue")
+	expected.Body = &expectBody{ReadCloser: req.Body, progress: cl.attemptProgress()}
+	cl.expected = true
+
+	return expected
+}
+
+// checkExpectation records whether call's current attempt was responded before its body was uploaded.
+func (cl *call) checkExpectation(res *http.Response) {
+	if !cl.expected || res == nil || atomic.LoadInt32(&cl.progress.uploaded) == 1 {
+		return
+	}
+
+	cl.expectRejected = true
+	if res.StatusCode == http.StatusExpectationFailed {
+		cl.expectFailed = true
+	}
+}
+
+// expectBody is a request body which records when it is read.
+type expectBody struct {
+	io.ReadCloser
+	progress *progress
+}
+
+// Read marks body of the attempt as uploaded and reads from underlying body.
+func (b *expectBody) Read(p []byte) (int, error) {
+	atomic.StoreInt32(&b.progress.uploaded, 1)
+
+	return b.ReadCloser.Read(p)
+}
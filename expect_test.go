@@ -0,0 +1,107 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidExpectContinue when a negative body size is provided.
+func TestInvalidExpectContinueOption(t *testing.T) {
+	if _, err := NewClient(WithExpectContinue(-1)); !errors.Is(err, ErrInvalidExpectContinue) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with expect continue should not count attempts rejected before upload toward maximum request count.
+func TestExpectContinueRejectedAttempts(t *testing.T) {
+	var reqCount, uploads int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Expect") != "100-continue" {
+			t.Errorf("unexpected expect header, %q", r.Header.Get("Expect"))
+		}
+		if atomic.AddInt32(&reqCount, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "body" {
+			t.Errorf("unexpected body, %q", body)
+		}
+		atomic.AddInt32(&uploads, 1)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: &http.Transport{ExpectContinueTimeout: time.Minute}}),
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithExpectContinue(1),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	} else {
+		res.Body.Close()
+	}
+	if reqCount != 3 || uploads != 1 {
+		t.Errorf("unexpected request count, %d, %d", reqCount, uploads)
+	}
+}
+
+// Do method of a client with expect continue should retry attempts failing the expectation without the header and without backoff.
+func TestExpectContinueFailed(t *testing.T) {
+	var reqCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqCount, 1)
+		if r.Header.Get("Expect") != "" {
+			w.WriteHeader(http.StatusExpectationFailed)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: &http.Transport{ExpectContinueTimeout: time.Minute}}),
+		WithMaxReqCount(2),
+		WithBackoff(time.Second),
+		WithClock(clock),
+		WithSleeper(clock),
+		WithExpectContinue(0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.URL, strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	} else {
+		res.Body.Close()
+	}
+	if reqCount != 2 || !clock.Now().IsZero() {
+		t.Errorf("unexpected request count or backoff, %d, %s", reqCount, clock.Now())
+	}
+}
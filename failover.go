@@ -0,0 +1,83 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// failover policy errors
+var (
+	ErrInvalidFailoverPolicy = errors.New("failover retries must not be less than never failover")
+)
+
+// NeverFailover is the number of same endpoint retries of outcomes which never fail over to another endpoint.
+const NeverFailover = -1
+
+// FailoverPolicy represents how many times a balanced request is retried on the same endpoint after an outcome before it fails over to another endpoint.
+// ConnectionErrors applies to attempts failed without a response, StatusCodes applies to responses with the status codes and Default applies to other failures.
+// Zero fails over immediately and NeverFailover retries on the same endpoint as long as the request is retried. For example, a policy failing over
+// immediately after connection errors, after one retry of 503 responses and never after 429 responses is
+// FailoverPolicy{StatusCodes: map[int]int{503: 1, 429: NeverFailover}}.
+type FailoverPolicy struct {
+	ConnectionErrors int
+	StatusCodes      map[int]int
+	Default          int
+}
+
+// WithFailoverPolicy configures when balanced requests switch endpoints between retries. A request retried on the same endpoint stays there even when
+// the endpoint is ejected, unless the endpoint is removed by its resolver. Without a failover policy, retries choose endpoints like first attempts.
+func WithFailoverPolicy(policy FailoverPolicy) Option {
+	return named("WithFailoverPolicy", func(c *Client) error {
+		if policy.ConnectionErrors < NeverFailover || policy.Default < NeverFailover {
+			return ErrInvalidFailoverPolicy
+		}
+
+		statusCodes := make(map[int]int, len(policy.StatusCodes))
+		for code, retries := range policy.StatusCodes {
+			if retries < NeverFailover {
+				return ErrInvalidFailoverPolicy
+			}
+			statusCodes[code] = retries
+		}
+		policy.StatusCodes = statusCodes
+
+		c.failoverPolicy = &policy
+
+		return nil
+	})
+}
+
+// sameEndpointRetries returns the number of same endpoint retries of provided outcome.
+func (p *FailoverPolicy) sameEndpointRetries(res *http.Response) int {
+	if res == nil {
+		return p.ConnectionErrors
+	}
+	if retries, ok := p.StatusCodes[res.StatusCode]; ok {
+		return retries
+	}
+
+	return p.Default
+}
+
+// failover decides whether the next attempt of provided call is sent to the endpoint of its current attempt, failed with provided error, or to another endpoint.
+func (c *Client) failover(cl *call, err error) {
+	e := cl.endpoint
+	if err == nil {
+		cl.pinned, cl.avoided, cl.sameFailures = nil, nil, 0
+		return
+	}
+
+	if cl.pinned == e {
+		cl.sameFailures++
+	} else {
+		cl.sameFailures = 1
+	}
+
+	retries := c.failoverPolicy.sameEndpointRetries(cl.res)
+	if retries == NeverFailover || cl.sameFailures <= retries {
+		cl.pinned, cl.avoided = e, nil
+		return
+	}
+
+	cl.pinned, cl.avoided, cl.sameFailures = nil, e, 0
+}
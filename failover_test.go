@@ -0,0 +1,63 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidFailoverPolicy when invalid same endpoint retries are provided.
+func TestInvalidFailoverPolicyOption(t *testing.T) {
+	if _, err := NewClient(WithFailoverPolicy(FailoverPolicy{StatusCodes: map[int]int{503: -2}})); !errors.Is(err, ErrInvalidFailoverPolicy) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with a failover policy should retry on the same endpoint or fail over to another endpoint as configured per outcome.
+func TestFailoverPolicy(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	firstCount, secondCount := 0, 0
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		firstCount++
+		w.WriteHeader(status)
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondCount++
+	}))
+	defer second.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	send := func(endpoints ...string) error {
+		c, err := NewClient(
+			WithMaxReqCount(3),
+			WithEndpoints("api.internal", endpoints...),
+			WithFailoverPolicy(FailoverPolicy{StatusCodes: map[int]int{503: 1, 429: NeverFailover}}),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		res, err := c.Get("http://api.internal/")
+		discard(res)
+
+		return err
+	}
+
+	if err := send(first.URL, second.URL); err != nil || firstCount != 2 || secondCount != 1 {
+		t.Errorf("503 responses are not retried once before failover, %v, %d, %d", err, firstCount, secondCount)
+	}
+
+	status = http.StatusTooManyRequests
+	firstCount, secondCount = 0, 0
+	if err := send(first.URL, second.URL); err == nil || firstCount != 3 || secondCount != 0 {
+		t.Errorf("429 responses fail over, %v, %d, %d", err, firstCount, secondCount)
+	}
+
+	secondCount = 0
+	if err := send(closed.URL, second.URL); err != nil || secondCount != 1 {
+		t.Errorf("connection errors do not fail over immediately, %v, %d", err, secondCount)
+	}
+}
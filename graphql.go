@@ -0,0 +1,157 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// graphql errors
+var (
+	ErrNoGraphQLCodes = errors.New("graphql error codes are empty")
+)
+
+// default retryable graphql error codes
+var defaultGraphQLRetryableCodes = []string{"RATE_LIMITED", "THROTTLED", "SERVICE_UNAVAILABLE"}
+
+// GraphQLError represents an error of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error returns error message.
+func (e *GraphQLError) Error() string {
+	if code := e.Code(); code != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, code)
+	}
+
+	return e.Message
+}
+
+// Code returns error code from error's extensions, it returns an empty string when error has no code.
+func (e *GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+
+	return code
+}
+
+// GraphQLErrors represents errors of a GraphQL response.
+type GraphQLErrors []*GraphQLError
+
+// Error returns joined error messages.
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// WithGraphQLRetryableCodes configures GraphQL error codes which are retried by GraphQL method.
+// Default retryable codes are RATE_LIMITED, THROTTLED and SERVICE_UNAVAILABLE.
+func WithGraphQLRetryableCodes(codes ...string) Option {
+	return named("WithGraphQLRetryableCodes", func(c *Client) error {
+		if len(codes) == 0 {
+			return ErrNoGraphQLCodes
+		}
+
+		c.graphQLRetryableCodes = codeSet(codes)
+
+		return nil
+	})
+}
+
+// codeSet converts provided codes to a set.
+func codeSet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+
+	return set
+}
+
+// graphQLRequest represents body of a GraphQL request.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse represents body of a GraphQL response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQL executes provided query with variables against GraphQL endpoint at provided url and decodes response's data into provided value.
+// Transport failures, unsuccessful responses without GraphQL errors and responses whose GraphQL errors all have retryable codes are retried.
+// Responses with any other GraphQL errors, including validation errors, fail permanently with GraphQLErrors.
+func (c *Client) GraphQL(ctx context.Context, url string, query string, variables map[string]interface{}, data interface{}) error {
+	if p := c.policyURL(http.MethodPost, url); p != c {
+		return p.GraphQL(ctx, url, query, variables, data)
+	}
+
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var gqlRes graphQLResponse
+	res, err := c.pipeline(req, nil, &exchange{consume: func(res *http.Response, err error) error {
+		resBody, bodyErr := c.bufferBody(res)
+		if bodyErr != nil {
+			return bodyErr
+		}
+
+		gqlRes = graphQLResponse{}
+		decodeErr := json.Unmarshal(resBody, &gqlRes)
+		if decodeErr == nil && len(gqlRes.Errors) > 0 {
+			return c.classifyGraphQLErrors(gqlRes.Errors)
+		}
+		if err != nil {
+			return err
+		}
+
+		return decodeErr
+	}})
+	discard(res)
+	if err != nil {
+		return err
+	}
+
+	if data == nil || len(gqlRes.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(gqlRes.Data, data)
+}
+
+// classifyGraphQLErrors returns provided errors as retryable when all errors have retryable codes, otherwise as permanent.
+func (c *Client) classifyGraphQLErrors(errs GraphQLErrors) error {
+	retryableCodes := c.graphQLRetryableCodes
+	if retryableCodes == nil {
+		retryableCodes = codeSet(defaultGraphQLRetryableCodes)
+	}
+
+	for _, err := range errs {
+		if !retryableCodes[err.Code()] {
+			return Permanent(errs)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,79 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// GraphQL method should retry responses with retryable error codes and decode data of successful response.
+func TestGraphQLWithRetryableCode(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.Write([]byte(`{"errors":[{"message":"slow down","extensions":{"code":"RATE_LIMITED"}}]}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"data":{"user":{"name":"gopher"}}}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var data struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	err = c.GraphQL(context.Background(), s.URL, "query { user { name } }", nil, &data)
+	if err != nil {
+		t.Errorf("executing query failed, %s", err.Error())
+	}
+	if data.User.Name != "gopher" {
+		t.Errorf("unexpected data, %+v", data)
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// GraphQL method should not retry validation errors, even when they are returned with unsuccessful status code.
+func TestGraphQLWithValidationError(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors":[{"message":"unknown field","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	err = c.GraphQL(context.Background(), s.URL, "query { unknown }", nil, nil)
+
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if gqlErrs[0].Code() != "GRAPHQL_VALIDATION_FAILED" {
+		t.Errorf("unexpected error code, %s", gqlErrs[0].Code())
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
@@ -0,0 +1,106 @@
+package retryablehttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// header errors
+var (
+	ErrEmptyHeaderName       = errors.New("header name is empty")
+	ErrNilRequestIDGenerator = errors.New("request id generator is nil")
+)
+
+// header names
+const (
+	DefaultAttemptHeader = "X-Retry-Attempt"
+	RequestIDHeader      = "X-Request-ID"
+)
+
+// WithAttemptHeader configures client to stamp each attempt with its attempt number, starting from 1, in provided header.
+// DefaultAttemptHeader can be used as header name. Attempt numbers are not sent by default.
+func WithAttemptHeader(name string) Option {
+	return named("WithAttemptHeader", func(c *Client) error {
+		if name == "" {
+			return ErrEmptyHeaderName
+		}
+
+		c.attemptHeader = http.CanonicalHeaderKey(name)
+
+		return nil
+	})
+}
+
+// WithRequestID configures client to send request ids generated by provided generator in X-Request-ID header.
+// When perAttempt is false, a single id is used for all attempts of a request for correlation, and an X-Request-ID header already set on the request is kept.
+// When perAttempt is true, every attempt gets a new id so that duplicate processing can be detected.
+// Sent ids are available in give up errors and reports. NewRequestID can be used as generator. Request ids are not sent by default.
+func WithRequestID(gen func() string, perAttempt bool) Option {
+	return named("WithRequestID", func(c *Client) error {
+		if gen == nil {
+			return ErrNilRequestIDGenerator
+		}
+
+		c.requestIDGen = gen
+		c.requestIDPerAttempt = perAttempt
+
+		return nil
+	})
+}
+
+// NewRequestID returns a random 128-bit request id encoded as hex.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// stamp returns a copy of provided request with attempt, request id, trace context and deadline headers of call's current attempt, it returns provided request when no header is configured.
+// Header of provided request is copied once per attempt, other fields of the copy are shared with provided request.
+func (c *Client) stamp(cl *call, req *http.Request) *http.Request {
+	deadline, hasDeadline := req.Context().Deadline()
+	hasDeadline = hasDeadline && c.deadlineHeader != ""
+	if c.attemptHeader == "" && c.requestIDGen == nil && c.tracePropagator == nil && !hasDeadline {
+		return req
+	}
+
+	stamped := req.WithContext(req.Context())
+	stamped.Header = req.Header.Clone()
+	if stamped.Header == nil {
+		stamped.Header = make(http.Header)
+	}
+	if c.attemptHeader != "" {
+		stamped.Header.Set(c.attemptHeader, strconv.Itoa(cl.attempt))
+	}
+	if c.requestIDGen != nil {
+		stamped.Header.Set(RequestIDHeader, c.requestID(cl, req))
+	}
+	if c.tracePropagator != nil {
+		c.tracePropagator.Inject(c.attemptTrace(cl, req), stamped.Header)
+	}
+	if hasDeadline {
+		stamped.Header.Set(c.deadlineHeader, c.deadlineFormat.format(deadline.Sub(c.clock.Now())))
+	}
+
+	return stamped
+}
+
+// requestID returns request id of call's current attempt, generating a new one when needed.
+func (c *Client) requestID(cl *call, req *http.Request) string {
+	n := len(cl.requestIDs)
+	if !c.requestIDPerAttempt && n > 0 {
+		return cl.requestIDs[n-1]
+	}
+
+	id := req.Header.Get(RequestIDHeader)
+	if c.requestIDPerAttempt || id == "" {
+		id = c.requestIDGen()
+	}
+	cl.requestIDs = append(cl.requestIDs, id)
+
+	return id
+}
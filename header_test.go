@@ -0,0 +1,185 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrEmptyHeaderName when empty attempt header name is provided.
+func TestEmptyAttemptHeaderOption(t *testing.T) {
+	_, err := NewClient(
+		WithAttemptHeader(""),
+	)
+	if !errors.Is(err, ErrEmptyHeaderName) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with attempt header should stamp each attempt with its attempt number without modifying provided request.
+func TestAttemptHeader(t *testing.T) {
+	var attempts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, r.Header.Get(DefaultAttemptHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithAttemptHeader(DefaultAttemptHeader),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if len(attempts) != 3 || attempts[0] != "1" || attempts[1] != "2" || attempts[2] != "3" {
+		t.Errorf("unexpected attempt headers, %v", attempts)
+	}
+	if req.Header.Get(DefaultAttemptHeader) != "" {
+		t.Error("provided request is modified")
+	}
+}
+
+// NewClient function should return ErrNilRequestIDGenerator when nil request id generator is provided.
+func TestNilRequestIDGeneratorOption(t *testing.T) {
+	_, err := NewClient(
+		WithRequestID(nil, false),
+	)
+	if !errors.Is(err, ErrNilRequestIDGenerator) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with request ids should send the same id for all attempts, or a new id per attempt, and surface sent ids.
+func TestRequestID(t *testing.T) {
+	for _, perAttempt := range []bool{false, true} {
+		var received []string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r.Header.Get(RequestIDHeader))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		var n int
+		c, err := NewClient(
+			WithMaxReqCount(3),
+			WithRequestID(func() string {
+				n++
+				return strconv.Itoa(n)
+			}, perAttempt),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		_, report, err := c.DoWithReport(req)
+		s.Close()
+
+		expected, sent := []string{"1", "1", "1"}, []string{"1"}
+		if perAttempt {
+			expected = []string{"1", "2", "3"}
+			sent = expected
+		}
+		if !reflect.DeepEqual(received, expected) {
+			t.Errorf("unexpected request ids, %v", received)
+		}
+
+		var giveUpErr *GiveUpError
+		if !errors.As(err, &giveUpErr) || !reflect.DeepEqual(giveUpErr.RequestIDs, sent) {
+			t.Errorf("unexpected error, %v", err)
+		}
+		for i, attempt := range report.Attempts {
+			if attempt.RequestID != expected[i] {
+				t.Errorf("unexpected request id of attempt %d, %s", attempt.Attempt, attempt.RequestID)
+			}
+		}
+		if req.Header.Get(RequestIDHeader) != "" {
+			t.Error("provided request is modified")
+		}
+	}
+}
+
+// Do method of a client with request ids per logical request should keep request id set by caller.
+func TestRequestIDSetByCaller(t *testing.T) {
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithRequestID(NewRequestID, false),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req.Header.Set(RequestIDHeader, "caller")
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	}
+	if received != "caller" {
+		t.Errorf("unexpected request id, %s", received)
+	}
+}
+
+// Do method of a client should send every attempt with its own request, leaving caller's request unmodified.
+func TestAttemptRequestIsolation(t *testing.T) {
+	var reqs []*http.Request
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithAttemptHeader(DefaultAttemptHeader),
+		WithDeadlineHeader(DefaultDeadlineHeader, DeadlineMilliseconds),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			reqs = append(reqs, r)
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://example.com", strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	c.Do(req)
+
+	if len(reqs) != 2 || reqs[0] == reqs[1] || reqs[0] == req {
+		t.Fatal("attempts do not have their own requests")
+	}
+	if reqs[0].Header.Get(DefaultAttemptHeader) != "1" || reqs[1].Header.Get(DefaultAttemptHeader) != "2" || reqs[1].Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("unexpected attempt headers, %v, %v", reqs[0].Header, reqs[1].Header)
+	}
+	if len(req.Header) != 1 {
+		t.Errorf("caller's request is modified, %v", req.Header)
+	}
+}
@@ -0,0 +1,80 @@
+package retryablehttp
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// jitter errors
+var (
+	ErrInvalidJitter = errors.New("jitter must be between 0 and 1")
+	ErrNilRandSource = errors.New("rand source is nil")
+)
+
+// lockedSource is a rand source which is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.src.Int63()
+}
+
+// Seed seeds underlying source.
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.src.Seed(seed)
+}
+
+// WithJitter configures client to randomize each backoff duration within provided fraction of it, a jitter of 0.5 waits between 50% and 150% of backoff duration.
+// Jitter spreads retries of concurrent clients. Backoff durations are not randomized by default.
+func WithJitter(jitter float64) Option {
+	return named("WithJitter", func(c *Client) error {
+		if jitter < 0 || jitter > 1 {
+			return ErrInvalidJitter
+		}
+
+		c.jitter = jitter
+
+		return nil
+	})
+}
+
+// WithRandSource configures client's rand source used for jitter, a seeded source makes jittered backoff durations reproducible.
+// Provided source is guarded by a mutex, so it does not need to be safe for concurrent use. Default source is seeded with current time.
+func WithRandSource(src rand.Source) Option {
+	return named("WithRandSource", func(c *Client) error {
+		if src == nil {
+			return ErrNilRandSource
+		}
+
+		c.rand = rand.New(&lockedSource{src: src})
+
+		return nil
+	})
+}
+
+// newRand returns a rand seeded with current time which is safe for concurrent use.
+func newRand() *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(time.Now().UnixNano())})
+}
+
+// jittered returns provided backoff duration randomized within provided jitter.
+func (c *Client) jittered(backoff time.Duration, jitter float64) time.Duration {
+	if jitter == 0 || backoff <= 0 {
+		return backoff
+	}
+
+	delta := jitter * float64(backoff)
+
+	return time.Duration(float64(backoff) - delta + c.rand.Float64()*2*delta)
+}
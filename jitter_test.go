@@ -0,0 +1,81 @@
+package retryablehttp
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidJitter and ErrNilRandSource when invalid jitter or nil rand source is provided.
+func TestInvalidJitterOptions(t *testing.T) {
+	for _, jitter := range []float64{-0.1, 1.1} {
+		_, err := NewClient(
+			WithJitter(jitter),
+		)
+		if !errors.Is(err, ErrInvalidJitter) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+
+	_, err := NewClient(
+		WithRandSource(nil),
+	)
+	if !errors.Is(err, ErrNilRandSource) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// DoWithReport method of clients with the same seeded rand source should wait the same jittered backoff durations within jitter.
+func TestJitterWithRandSource(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	backoff := time.Second
+	schedule := func() []time.Duration {
+		clock := &fakeClock{}
+		c, err := NewClient(
+			WithMaxReqCount(4),
+			WithBackoff(backoff),
+			WithJitter(0.5),
+			WithRandSource(rand.NewSource(42)),
+			WithClock(clock),
+			WithSleeper(clock),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		_, report, _ := c.DoWithReport(req)
+
+		var backoffs []time.Duration
+		for _, attempt := range report.Attempts[1:] {
+			backoffs = append(backoffs, attempt.Timing.Backoff)
+		}
+
+		return backoffs
+	}
+
+	first, second := schedule(), schedule()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("jittered backoff durations are not reproducible, %v, %v", first, second)
+	}
+	for _, d := range first {
+		if d < backoff/2 || d > backoff*3/2 {
+			t.Errorf("unexpected jittered backoff duration, %s", d)
+		}
+	}
+	if first[0] == first[1] && first[1] == first[2] {
+		t.Errorf("backoff durations are not jittered, %v", first)
+	}
+}
@@ -0,0 +1,242 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// json-rpc errors
+var (
+	ErrNoJSONRPCCalls       = errors.New("json-rpc batch has no calls")
+	ErrMissingJSONRPCResult = errors.New("json-rpc response is missing")
+)
+
+// json-rpc error codes
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+)
+
+// jsonRPCVersion is the protocol version sent with every request.
+const jsonRPCVersion = "2.0"
+
+// JSONRPCError represents an error object of a JSON-RPC response.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error returns error message including error code.
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d, %s", e.Code, e.Message)
+}
+
+// Retryable reports whether error is retryable, internal errors and server errors (-32000 to -32099) are retryable.
+func (e *JSONRPCError) Retryable() bool {
+	return e.Code == JSONRPCInternalError || (e.Code >= -32099 && e.Code <= -32000)
+}
+
+// JSONRPCCall represents a single call of a JSON-RPC batch.
+// Result is decoded into Result field and call's error is stored in Error field.
+type JSONRPCCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Error  error
+}
+
+// JSONRPCClient represents a JSON-RPC 2.0 client sending requests with retryable http client.
+type JSONRPCClient struct {
+	client *Client
+	url    string
+	id     uint64
+}
+
+// NewJSONRPCClient creates and returns new JSON-RPC client sending requests to provided url.
+func NewJSONRPCClient(c *Client, url string) *JSONRPCClient {
+	return &JSONRPCClient{
+		client: c,
+		url:    url,
+	}
+}
+
+// jsonRPCRequest represents a JSON-RPC request object.
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// jsonRPCResponse represents a JSON-RPC response object.
+type jsonRPCResponse struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+// Call calls provided method with params and decodes call's result into provided value.
+// Transport failures and retryable JSON-RPC errors are retried, other JSON-RPC errors fail permanently.
+func (rc *JSONRPCClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	call := &JSONRPCCall{
+		Method: method,
+		Params: params,
+		Result: result,
+	}
+
+	if err := rc.Batch(ctx, []*JSONRPCCall{call}); err != nil {
+		return err
+	}
+
+	return call.Error
+}
+
+// Batch sends provided calls in a single JSON-RPC batch, calls failing with retryable errors are re-sent in following attempts.
+// Batch returns an error when calls cannot be sent, errors of individual calls are stored in their Error fields.
+func (rc *JSONRPCClient) Batch(ctx context.Context, calls []*JSONRPCCall) error {
+	if len(calls) == 0 {
+		return ErrNoJSONRPCCalls
+	}
+
+	pending := calls
+	for _, call := range calls {
+		call.Error = nil
+	}
+
+	var byID map[uint64]*JSONRPCCall
+	build := func() (*http.Request, error) {
+		var req *http.Request
+		var err error
+		req, byID, err = rc.request(ctx, pending)
+
+		return req, err
+	}
+
+	req, err := build()
+	if err != nil {
+		return err
+	}
+
+	res, err := rc.client.pipeline(req, nil, &exchange{
+		rebuild: build,
+		consume: func(res *http.Response, err error) error {
+			if err != nil {
+				return err
+			}
+
+			pending, err = rc.settle(res, pending, byID)
+
+			return err
+		},
+	})
+	discard(res)
+	if err != nil && ctx.Err() == nil {
+		var rpcErr *JSONRPCError
+		if errors.As(err, &rpcErr) || errors.Is(err, ErrMissingJSONRPCResult) {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// request creates a request sending provided calls, it returns the request and calls keyed by their ids.
+func (rc *JSONRPCClient) request(ctx context.Context, calls []*JSONRPCCall) (*http.Request, map[uint64]*JSONRPCCall, error) {
+	reqs := make([]jsonRPCRequest, len(calls))
+	byID := make(map[uint64]*JSONRPCCall, len(calls))
+	for i, call := range calls {
+		id := atomic.AddUint64(&rc.id, 1)
+		reqs[i] = jsonRPCRequest{
+			JSONRPC: jsonRPCVersion,
+			ID:      id,
+			Method:  call.Method,
+			Params:  call.Params,
+		}
+		byID[id] = call
+	}
+
+	var body []byte
+	var err error
+	if len(reqs) == 1 {
+		body, err = json.Marshal(reqs[0])
+	} else {
+		body, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rc.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	return req, byID, nil
+}
+
+// settle settles provided calls with results or permanent errors of provided response, it returns calls which should be retried.
+func (rc *JSONRPCClient) settle(res *http.Response, calls []*JSONRPCCall, byID map[uint64]*JSONRPCCall) ([]*JSONRPCCall, error) {
+	var err error
+	var rpcRess []jsonRPCResponse
+	if len(byID) == 1 {
+		var rpcRes jsonRPCResponse
+		err = json.NewDecoder(res.Body).Decode(&rpcRes)
+		rpcRess = append(rpcRess, rpcRes)
+	} else {
+		err = json.NewDecoder(res.Body).Decode(&rpcRess)
+	}
+	if err != nil {
+		return calls, err
+	}
+
+	settled := make(map[*JSONRPCCall]bool, len(calls))
+	for _, rpcRes := range rpcRess {
+		var call *JSONRPCCall
+		if rpcRes.ID != nil {
+			call = byID[*rpcRes.ID]
+		} else if len(calls) == 1 {
+			call = calls[0]
+		}
+		if call == nil || settled[call] {
+			continue
+		}
+		settled[call] = true
+		call.Error = nil
+
+		if rpcRes.Error != nil {
+			call.Error = rpcRes.Error
+			continue
+		}
+
+		if call.Result != nil {
+			call.Error = json.Unmarshal(rpcRes.Result, call.Result)
+		}
+	}
+
+	var retries []*JSONRPCCall
+	var lastErr error
+	for _, call := range calls {
+		if !settled[call] {
+			call.Error = ErrMissingJSONRPCResult
+		}
+
+		var rpcErr *JSONRPCError
+		if call.Error == ErrMissingJSONRPCResult || (errors.As(call.Error, &rpcErr) && rpcErr.Retryable()) {
+			retries = append(retries, call)
+			lastErr = call.Error
+		}
+	}
+
+	return retries, lastErr
+}
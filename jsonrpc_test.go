@@ -0,0 +1,102 @@
+package retryablehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newJSONRPCServer creates a test server which answers requests and batches by calling provided function for each request.
+func newJSONRPCServer(t *testing.T, fn func(method string) (interface{}, *JSONRPCError)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request failed, %s", err.Error())
+		}
+
+		var reqs []jsonRPCRequest
+		batch := body[0] == '['
+		if batch {
+			json.Unmarshal(body, &reqs)
+		} else {
+			reqs = make([]jsonRPCRequest, 1)
+			json.Unmarshal(body, &reqs[0])
+		}
+
+		ress := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			result, rpcErr := fn(req.Method)
+			ress[i] = map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			if rpcErr != nil {
+				ress[i]["error"] = rpcErr
+			} else {
+				ress[i]["result"] = result
+			}
+		}
+
+		if batch {
+			json.NewEncoder(w).Encode(ress)
+		} else {
+			json.NewEncoder(w).Encode(ress[0])
+		}
+	}))
+}
+
+// Batch method should re-send only calls failing with retryable errors and store permanent errors in calls.
+func TestJSONRPCBatch(t *testing.T) {
+	counts := make(map[string]int)
+	s := newJSONRPCServer(t, func(method string) (interface{}, *JSONRPCError) {
+		counts[method]++
+		switch method {
+		case "flaky":
+			if counts[method] == 1 {
+				return nil, &JSONRPCError{Code: JSONRPCInternalError, Message: "internal error"}
+			}
+			return "ok", nil
+		case "unknown":
+			return nil, &JSONRPCError{Code: JSONRPCMethodNotFound, Message: "method not found"}
+		default:
+			return fmt.Sprintf("%s ok", method), nil
+		}
+	})
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var flakyResult, stableResult string
+	calls := []*JSONRPCCall{
+		{Method: "flaky", Result: &flakyResult},
+		{Method: "stable", Result: &stableResult},
+		{Method: "unknown"},
+	}
+
+	rc := NewJSONRPCClient(c, s.URL)
+	if err := rc.Batch(context.Background(), calls); err != nil {
+		t.Fatalf("sending batch failed, %s", err.Error())
+	}
+
+	if calls[0].Error != nil || flakyResult != "ok" {
+		t.Errorf("unexpected flaky call result, %q, %v", flakyResult, calls[0].Error)
+	}
+	if calls[1].Error != nil || stableResult != "stable ok" {
+		t.Errorf("unexpected stable call result, %q, %v", stableResult, calls[1].Error)
+	}
+
+	var rpcErr *JSONRPCError
+	if !errors.As(calls[2].Error, &rpcErr) || rpcErr.Code != JSONRPCMethodNotFound {
+		t.Errorf("unexpected error, %v", calls[2].Error)
+	}
+
+	if counts["flaky"] != 2 || counts["stable"] != 1 || counts["unknown"] != 1 {
+		t.Errorf("unexpected call counts, %v", counts)
+	}
+}
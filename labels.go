@@ -0,0 +1,30 @@
+package retryablehttp
+
+import (
+	"context"
+)
+
+// labelsKey is the context key of request labels.
+type labelsKey struct{}
+
+// ContextWithLabels returns a copy of provided context carrying provided labels, such as operation "create_order", merged over labels it already carries.
+// Labels of requests using the context are attached to their events, audit records, profiler labels and statistics, see Stats.Labels,
+// so that retry metrics can be sliced by business operation rather than only by host and method.
+func ContextWithLabels(ctx context.Context, labels map[string]string) context.Context {
+	merged := make(map[string]string, len(labels))
+	for k, v := range LabelsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, labelsKey{}, merged)
+}
+
+// LabelsFromContext returns labels carried by provided context, the returned map must not be modified.
+func LabelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(labelsKey{}).(map[string]string)
+
+	return labels
+}
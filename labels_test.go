@@ -0,0 +1,95 @@
+package retryablehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ContextWithLabels function should merge provided labels over labels carried by context.
+func TestContextWithLabels(t *testing.T) {
+	ctx := ContextWithLabels(context.Background(), map[string]string{"operation": "list_orders", "team": "billing"})
+	ctx = ContextWithLabels(ctx, map[string]string{"operation": "create_order"})
+
+	labels := LabelsFromContext(ctx)
+	if len(labels) != 2 || labels["operation"] != "create_order" || labels["team"] != "billing" {
+		t.Errorf("unexpected labels, %v", labels)
+	}
+
+	if labels := LabelsFromContext(context.Background()); labels != nil {
+		t.Errorf("unexpected labels, %v", labels)
+	}
+}
+
+// Do method should attach request labels to audit records, events and statistics.
+func TestLabels(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	var records []AuditRecord
+	events := make(chan Event, 10)
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithAuditSink(AuditSinkFunc(func(record AuditRecord) {
+			records = append(records, record)
+		})),
+		WithEventChannel(events, EventPolicyDrop),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	ctx := ContextWithLabels(context.Background(), map[string]string{"operation": "create_order"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	}
+	discard(res)
+
+	if len(records) != 2 {
+		t.Fatalf("unexpected audit record count, %d", len(records))
+	}
+	for _, record := range records {
+		if record.Labels["operation"] != "create_order" {
+			t.Errorf("unexpected audit record labels, %v", record.Labels)
+		}
+	}
+
+	close(events)
+	for event := range events {
+		var labels map[string]string
+		switch e := event.(type) {
+		case AttemptStarted:
+			labels = e.Labels
+		case AttemptFinished:
+			labels = e.Labels
+		case RetryScheduled:
+			labels = e.Labels
+		case GaveUp:
+			labels = e.Labels
+		}
+		if labels["operation"] != "create_order" {
+			t.Errorf("unexpected event labels, %T %v", event, labels)
+		}
+	}
+
+	labelStats, ok := c.Stats().Labels["operation=create_order"]
+	if !ok {
+		t.Fatal("missing label stats")
+	}
+	if labelStats.Attempts != 2 || labelStats.ErrorRate != 1-ewmaWeight {
+		t.Errorf("unexpected label stats, %+v", labelStats)
+	}
+}
@@ -0,0 +1,332 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// limiter errors
+var (
+	ErrInvalidConcurrencyLimit = errors.New("concurrency limits are not valid")
+	ErrInvalidLoadShedding     = errors.New("load shedding limits must not be negative and one of them must be set")
+	ErrOverloaded              = errors.New("client is overloaded")
+	ErrInvalidHostSemaphore    = errors.New("semaphore host must not be empty and size must be greater than zero")
+)
+
+// default adaptive concurrency settings
+const (
+	defaultInitialLimit     = 10
+	defaultMinLimit         = 1
+	defaultMaxLimit         = 1000
+	defaultLatencyTolerance = 2
+	defaultDecreaseRatio    = 0.9
+)
+
+// baselineWeight is the weight of an attempt slower than baseline latency of a host in the rise of the baseline.
+const baselineWeight = 0.05
+
+// AdaptiveConcurrency represents settings of adaptive concurrency limiting, zero values take default values.
+// Limit of a host starts from InitialLimit (10) and stays between MinLimit (1) and MaxLimit (1000).
+// The limit grows additively after successful attempts and is multiplied by DecreaseRatio (0.9) after throttled attempts,
+// responded with 429 or 503 status codes, and after attempts slower than LatencyTolerance (2) times the baseline latency of the host.
+// The baseline follows the lowest latency of the host and rises slowly towards slower latencies, so that a single fast response does not
+// make every later attempt slow.
+type AdaptiveConcurrency struct {
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyTolerance float64
+	DecreaseRatio    float64
+}
+
+// WithAdaptiveConcurrency configures client to limit in-flight attempts per host with an adaptive limit, additive increase and multiplicative decrease.
+// Attempts exceeding the limit wait for a slot before they are sent, until request's context is done.
+// An attempt is in flight until its response is handled. Concurrency is not limited by default.
+func WithAdaptiveConcurrency(cfg AdaptiveConcurrency) Option {
+	return named("WithAdaptiveConcurrency", func(c *Client) error {
+		if cfg.InitialLimit == 0 {
+			cfg.InitialLimit = defaultInitialLimit
+		}
+		if cfg.MinLimit == 0 {
+			cfg.MinLimit = defaultMinLimit
+		}
+		if cfg.MaxLimit == 0 {
+			cfg.MaxLimit = defaultMaxLimit
+		}
+		if cfg.LatencyTolerance == 0 {
+			cfg.LatencyTolerance = defaultLatencyTolerance
+		}
+		if cfg.DecreaseRatio == 0 {
+			cfg.DecreaseRatio = defaultDecreaseRatio
+		}
+
+		if cfg.MinLimit < 1 || cfg.MinLimit > cfg.InitialLimit || cfg.InitialLimit > cfg.MaxLimit ||
+			cfg.LatencyTolerance < 1 || cfg.DecreaseRatio <= 0 || cfg.DecreaseRatio >= 1 {
+			return ErrInvalidConcurrencyLimit
+		}
+
+		c.limiter = &limiter{
+			adaptive: true,
+			cfg:      cfg,
+			hosts:    make(map[string]*hostLimit),
+		}
+
+		return nil
+	})
+}
+
+// WithLoadShedding configures client to reject attempts with ErrOverloaded instead of queuing them for a concurrency slot when
+// provided number of attempts are already waiting for the host, or after waiting provided duration with client's sleeper. Zero values disable the
+// respective limit.
+// Overloaded attempts are not retried and are counted in Stats. It applies to attempts limited by WithAdaptiveConcurrency and host semaphores.
+func WithLoadShedding(maxQueueDepth int, maxQueueWait time.Duration) Option {
+	return named("WithLoadShedding", func(c *Client) error {
+		if maxQueueDepth < 0 || maxQueueWait < 0 || (maxQueueDepth == 0 && maxQueueWait == 0) {
+			return ErrInvalidLoadShedding
+		}
+
+		c.maxQueueDepth = maxQueueDepth
+		c.maxQueueWait = maxQueueWait
+
+		return nil
+	})
+}
+
+// WithHostSemaphore configures client to limit concurrent attempts to provided host to provided number, independent of transport's MaxConnsPerHost,
+// so that retries queue fairly instead of piling onto saturated connections. Host is matched case-insensitively with request's hostname,
+// or with its host and port when it contains a port. Attempts exceeding the limit wait for a slot until request's context is done.
+func WithHostSemaphore(host string, n int) Option {
+	return named("WithHostSemaphore", func(c *Client) error {
+		if host == "" || n <= 0 {
+			return ErrInvalidHostSemaphore
+		}
+
+		c.semaphore = c.semaphore.withLimit(strings.ToLower(host), n)
+
+		return nil
+	})
+}
+
+// WithDefaultHostSemaphore configures client to limit concurrent attempts to provided number for every host without a host semaphore.
+func WithDefaultHostSemaphore(n int) Option {
+	return named("WithDefaultHostSemaphore", func(c *Client) error {
+		if n <= 0 {
+			return ErrInvalidHostSemaphore
+		}
+
+		c.semaphore = c.semaphore.withLimit("", n)
+
+		return nil
+	})
+}
+
+// acquireSlot waits for host semaphore and adaptive concurrency slots of request's host, shedding the attempt when client's queue limits are exceeded.
+func (c *Client) acquireSlot(cl *call, req *http.Request) error {
+	var releases []func(cl *call)
+	for _, l := range []*limiter{c.semaphore, c.limiter} {
+		if l == nil {
+			continue
+		}
+
+		release, err := l.acquire(req.Context(), req.URL.Host, c.maxQueueDepth, c.maxQueueWait, c.sleep)
+		if err != nil {
+			for _, release := range releases {
+				release(cl)
+			}
+			if err == ErrOverloaded {
+				c.stats.shed.add(1)
+				return Permanent(err)
+			}
+
+			return err
+		}
+		releases = append(releases, release)
+	}
+
+	if len(releases) > 0 {
+		cl.release = func(cl *call) {
+			for _, release := range releases {
+				release(cl)
+			}
+		}
+	}
+
+	return nil
+}
+
+// limiter limits in-flight attempts per host, either with adaptive limits or with fixed semaphore limits.
+type limiter struct {
+	adaptive bool
+	cfg      AdaptiveConcurrency
+	// fixed holds fixed limits keyed by host or host and port, the limit keyed by an empty string applies to other hosts.
+	fixed map[string]int
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+// hostLimit holds concurrency state of a host.
+type hostLimit struct {
+	limit    float64
+	inFlight int
+	waiting  int
+	// baseLatency is the baseline latency which slow attempts are detected against.
+	baseLatency time.Duration
+	// released is closed and replaced when a slot is released, waking waiting attempts.
+	released chan struct{}
+}
+
+// acquire waits for a slot of provided host until context is done, it returns a function releasing the slot with provided call's outcome.
+// It returns ErrOverloaded when provided number of attempts are already waiting, or after waiting provided duration with provided sleep function,
+// zero values disable the limits.
+func (l *limiter) acquire(ctx context.Context, host string, maxDepth int, maxWait time.Duration, sleep func(ctx context.Context, d time.Duration)) (func(cl *call), error) {
+	l.mu.Lock()
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimit{
+			limit:    l.initialLimit(host),
+			released: make(chan struct{}),
+		}
+		l.hosts[host] = h
+	}
+	if h.limit == 0 {
+		l.mu.Unlock()
+		return func(cl *call) {}, nil
+	}
+
+	if h.inFlight >= int(h.limit) && maxDepth > 0 && h.waiting >= maxDepth {
+		l.mu.Unlock()
+		return nil, ErrOverloaded
+	}
+
+	var expired chan struct{}
+	for h.inFlight >= int(h.limit) {
+		if maxWait > 0 && expired == nil {
+			expired = make(chan struct{})
+			waitCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			go func() {
+				sleep(waitCtx, maxWait)
+				if waitCtx.Err() == nil {
+					close(expired)
+				}
+			}()
+		}
+
+		released := h.released
+		h.waiting++
+		l.mu.Unlock()
+
+		var err error
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-expired:
+			err = ErrOverloaded
+		case <-released:
+		}
+
+		l.mu.Lock()
+		h.waiting--
+		if err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+	}
+	h.inFlight++
+	l.mu.Unlock()
+
+	return func(cl *call) {
+		l.release(h, cl)
+	}, nil
+}
+
+// release releases a slot of provided host and adjusts its limit with provided call's current attempt.
+func (l *limiter) release(h *hostLimit, cl *call) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h.inFlight--
+	close(h.released)
+	h.released = make(chan struct{})
+
+	if !l.adaptive || cl.res == nil {
+		return
+	}
+
+	if h.baseLatency == 0 || cl.latency < h.baseLatency {
+		h.baseLatency = cl.latency
+	}
+	slow := float64(cl.latency) > l.cfg.LatencyTolerance*float64(h.baseLatency)
+	h.baseLatency += time.Duration(baselineWeight * float64(cl.latency-h.baseLatency))
+
+	throttled := cl.res.StatusCode == http.StatusTooManyRequests || cl.res.StatusCode == http.StatusServiceUnavailable
+	if throttled || slow {
+		h.limit *= l.cfg.DecreaseRatio
+		if h.limit < float64(l.cfg.MinLimit) {
+			h.limit = float64(l.cfg.MinLimit)
+		}
+
+		return
+	}
+
+	h.limit += 1 / h.limit
+	if h.limit > float64(l.cfg.MaxLimit) {
+		h.limit = float64(l.cfg.MaxLimit)
+	}
+}
+
+// withLimit returns a copy of semaphore limiter with provided fixed limit of provided host, a nil limiter is treated as an empty one.
+// Limiters are copied so that clients derived by With do not change limits of their parents.
+func (l *limiter) withLimit(host string, n int) *limiter {
+	copied := &limiter{
+		fixed: map[string]int{host: n},
+		hosts: make(map[string]*hostLimit),
+	}
+	if l != nil {
+		for host, n := range l.fixed {
+			if _, ok := copied.fixed[host]; !ok {
+				copied.fixed[host] = n
+			}
+		}
+	}
+
+	return copied
+}
+
+// initialLimit returns initial limit of provided host and port, zero means attempts are not limited.
+func (l *limiter) initialLimit(hostport string) float64 {
+	if l.adaptive {
+		return float64(l.cfg.InitialLimit)
+	}
+
+	hostport = strings.ToLower(hostport)
+	if n, ok := l.fixed[hostport]; ok {
+		return float64(n)
+	}
+	if u, err := url.Parse("//" + hostport); err == nil {
+		if n, ok := l.fixed[u.Hostname()]; ok {
+			return float64(n)
+		}
+	}
+
+	return float64(l.fixed[""])
+}
+
+// limits returns current limits keyed by host.
+func (l *limiter) limits() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := make(map[string]int, len(l.hosts))
+	for host, h := range l.hosts {
+		limits[host] = int(h.limit)
+	}
+
+	return limits
+}
@@ -0,0 +1,320 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidConcurrencyLimit when invalid adaptive concurrency settings are provided.
+func TestInvalidAdaptiveConcurrencyOptions(t *testing.T) {
+	for _, cfg := range []AdaptiveConcurrency{
+		{MinLimit: 5, InitialLimit: 2},
+		{InitialLimit: 20, MaxLimit: 10},
+		{LatencyTolerance: 0.5},
+		{DecreaseRatio: 1.5},
+	} {
+		if _, err := NewClient(WithAdaptiveConcurrency(cfg)); !errors.Is(err, ErrInvalidConcurrencyLimit) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Do method of a client with adaptive concurrency should shrink host limit after throttled attempts and grow it after successful attempts.
+func TestAdaptiveConcurrencyLimit(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(10),
+		WithBackoff(0),
+		WithAdaptiveConcurrency(AdaptiveConcurrency{InitialLimit: 10, LatencyTolerance: 1000}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+	host := req.URL.Host
+	if limit := c.Stats().Hosts[host].ConcurrencyLimit; limit != 3 {
+		t.Errorf("unexpected limit after throttled attempts, %d", limit)
+	}
+
+	status = http.StatusOK
+	for i := 0; i < 10; i++ {
+		res, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err.Error())
+		}
+		res.Body.Close()
+	}
+	if limit := c.Stats().Hosts[host].ConcurrencyLimit; limit != 5 {
+		t.Errorf("unexpected limit after successful attempts, %d", limit)
+	}
+}
+
+// release method of an adaptive limiter should raise baseline latency of a host, so that a single fast attempt does not pin its limit at the minimum.
+func TestAdaptiveConcurrencyBaseline(t *testing.T) {
+	c, err := NewClient(
+		WithAdaptiveConcurrency(AdaptiveConcurrency{InitialLimit: 10}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 50; i++ {
+		release, err := c.limiter.acquire(context.Background(), "example.com", 0, 0, c.sleep)
+		if err != nil {
+			t.Fatalf("unexpected error, %s", err.Error())
+		}
+
+		latency := 10 * time.Millisecond
+		if i == 0 {
+			latency = time.Millisecond
+		}
+		release(&call{res: &http.Response{StatusCode: http.StatusOK}, latency: latency})
+	}
+
+	c.limiter.mu.Lock()
+	limit := c.limiter.hosts["example.com"].limit
+	c.limiter.mu.Unlock()
+	if limit < 5 {
+		t.Errorf("unexpected limit, %f", limit)
+	}
+}
+
+// Do method of a client with adaptive concurrency should not exceed host limit.
+func TestAdaptiveConcurrencyWait(t *testing.T) {
+	var inFlight, maxInFlight int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithAdaptiveConcurrency(AdaptiveConcurrency{InitialLimit: 2, MaxLimit: 2}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+			if err != nil {
+				t.Errorf("creating http request failed, %s", err.Error())
+				return
+			}
+			if res, err := c.Do(req); err == nil {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Errorf("unexpected maximum in-flight attempts, %d", maxInFlight)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.limiter.acquire(context.Background(), "full", 0, 0, systemClock{}.SleepContext); err != nil {
+			t.Errorf("unexpected error, %s", err.Error())
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.limiter.acquire(ctx, "full", 0, 0, systemClock{}.SleepContext); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// NewClient function should return ErrInvalidLoadShedding when invalid load shedding limits are provided.
+func TestInvalidLoadSheddingOptions(t *testing.T) {
+	for _, opt := range []Option{WithLoadShedding(0, 0), WithLoadShedding(-1, 0), WithLoadShedding(0, -time.Second)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidLoadShedding) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Do method of a client with load shedding should reject requests with ErrOverloaded when queue depth or wait time is exceeded.
+func TestLoadShedding(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithAdaptiveConcurrency(AdaptiveConcurrency{InitialLimit: 1, MaxLimit: 1}),
+		WithLoadShedding(1, 20*time.Millisecond),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	host := req.URL.Host
+
+	if _, err := c.limiter.acquire(context.Background(), host, 0, 0, systemClock{}.SleepContext); err != nil {
+		t.Errorf("unexpected error, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("unexpected error after queue wait, %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.limiter.acquire(ctx, host, 0, 0, systemClock{}.SleepContext)
+	for waiting := 0; waiting == 0; {
+		time.Sleep(time.Millisecond)
+		c.limiter.mu.Lock()
+		waiting = c.limiter.hosts[host].waiting
+		c.limiter.mu.Unlock()
+	}
+
+	start := time.Now()
+	if _, err := c.Do(req); !errors.Is(err, ErrOverloaded) || time.Since(start) > 10*time.Millisecond {
+		t.Errorf("unexpected error after queue depth, %v", err)
+	}
+
+	if reqCount != 0 || c.Stats().Shed != 2 {
+		t.Errorf("unexpected request count %d or shed count %d", reqCount, c.Stats().Shed)
+	}
+}
+
+// Do method of a client with load shedding should wait queue wait time with client's sleeper.
+func TestLoadSheddingSleeper(t *testing.T) {
+	start := time.Now()
+	clock := &fakeClock{now: start}
+	c, err := NewClient(
+		WithClock(clock),
+		WithSleeper(clock),
+		WithHostSemaphore("example.com", 1),
+		WithLoadShedding(0, time.Hour),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if _, err := c.semaphore.acquire(context.Background(), "example.com", 0, 0, c.sleep); err != nil {
+		t.Errorf("unexpected error, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != time.Hour {
+		t.Errorf("unexpected elapsed fake time, %s", elapsed)
+	}
+}
+
+// NewClient function should return ErrInvalidHostSemaphore when empty host or non-positive size is provided.
+func TestInvalidHostSemaphoreOptions(t *testing.T) {
+	for _, opt := range []Option{WithHostSemaphore("", 1), WithHostSemaphore("example.com", 0), WithDefaultHostSemaphore(0)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidHostSemaphore) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Do method of a client with host semaphores should limit concurrent attempts by host, falling back to default semaphore.
+func TestHostSemaphore(t *testing.T) {
+	c, err := NewClient(
+		WithHostSemaphore("API.example.com", 2),
+		WithHostSemaphore("api.example.com:8443", 1),
+		WithDefaultHostSemaphore(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for host, expected := range map[string]float64{
+		"api.example.com":      2,
+		"api.example.com:443":  2,
+		"api.example.com:8443": 1,
+		"other.example.com":    3,
+	} {
+		if limit := c.semaphore.initialLimit(host); limit != expected {
+			t.Errorf("unexpected limit of %s, %v", host, limit)
+		}
+	}
+
+	derived, err := c.With(WithHostSemaphore("api.example.com", 5))
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+	if c.semaphore.initialLimit("api.example.com") != 2 || derived.semaphore.initialLimit("api.example.com") != 5 {
+		t.Error("derived client changed parent's semaphore")
+	}
+
+	var inFlight, maxInFlight int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+			if err != nil {
+				t.Errorf("creating http request failed, %s", err.Error())
+				return
+			}
+			if res, err := c.Do(req); err == nil {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 3 {
+		t.Errorf("unexpected maximum in-flight attempts, %d", maxInFlight)
+	}
+}
@@ -0,0 +1,49 @@
+package retryablehttp
+
+import (
+	"errors"
+)
+
+// locality errors
+var (
+	ErrInvalidLocality = errors.New("locality region must not be empty and local attempts must not be negative")
+)
+
+// locality ranks of endpoints, lower ranks are preferred
+const (
+	localZone = iota
+	localRegion
+	remoteRegion
+)
+
+// WithLocality configures client to prefer endpoints of provided zone, then endpoints of provided region, over endpoints of remote regions when
+// balancing requests with WithEndpoints or an endpoint resolver. Requests fail over to remote regions when every local endpoint is ejected or, when
+// provided number of local attempts is greater than zero, after that many attempts of the request failed on local endpoints. Zone may be empty.
+// Region of the endpoint of an attempt is recorded in its AttemptReport and in host statistics. Endpoints are not ranked by locality by default.
+func WithLocality(region, zone string, localAttempts int) Option {
+	return named("WithLocality", func(c *Client) error {
+		if region == "" || localAttempts < 0 {
+			return ErrInvalidLocality
+		}
+
+		c.localRegion = region
+		c.localZone = zone
+		c.localAttempts = localAttempts
+
+		return nil
+	})
+}
+
+// localityRank returns locality rank of provided endpoint, every endpoint is in the local zone when client has no locality.
+func (c *Client) localityRank(e *endpoint) int {
+	switch {
+	case c.localRegion == "":
+		return localZone
+	case e.region != c.localRegion:
+		return remoteRegion
+	case c.localZone != "" && e.zone == c.localZone:
+		return localZone
+	default:
+		return localRegion
+	}
+}
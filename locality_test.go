@@ -0,0 +1,75 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidLocality when empty region or negative local attempts are provided.
+func TestInvalidLocalityOption(t *testing.T) {
+	if _, err := NewClient(WithLocality("", "a", 0)); !errors.Is(err, ErrInvalidLocality) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithLocality("us-east", "a", -1)); !errors.Is(err, ErrInvalidLocality) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with locality should prefer local endpoints and fail over to remote regions after local attempts fail.
+func TestLocality(t *testing.T) {
+	localFailing := false
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if localFailing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer local.Close()
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer regional.Close()
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer remote.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithEndpointResolver("api.internal", StaticResolver{
+			{URL: remote.URL, Region: "eu-west", Zone: "a"},
+			{URL: regional.URL, Region: "us-east", Zone: "b"},
+			{URL: local.URL, Region: "us-east", Zone: "a"},
+		}, 0),
+		WithLocality("us-east", "a", 1),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	send := func() []*AttemptReport {
+		req, _ := http.NewRequest(http.MethodGet, "http://api.internal/", nil)
+		res, report, err := c.DoWithReport(req)
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+		}
+		discard(res)
+
+		return report.Attempts
+	}
+
+	for i := 0; i < 2; i++ {
+		if attempts := send(); len(attempts) != 1 || attempts[0].Region != "us-east" {
+			t.Errorf("request is not sent to local endpoint, %v", attempts)
+		}
+	}
+	if hosts := c.Stats().Hosts; hosts[local.Listener.Addr().String()].Attempts != 2 {
+		t.Errorf("unexpected host stats, %v", hosts)
+	}
+
+	localFailing = true
+	attempts := send()
+	if len(attempts) != 2 || attempts[0].Region != "us-east" || attempts[1].Region != "eu-west" {
+		t.Errorf("request does not fail over to remote region, %v", attempts)
+	}
+	if hosts := c.Stats().Hosts; hosts[remote.Listener.Addr().String()].Region != "eu-west" {
+		t.Errorf("unexpected host stats, %v", hosts)
+	}
+}
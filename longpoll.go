@@ -0,0 +1,69 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// long polling errors
+var (
+	ErrNilLongPollHandler = errors.New("long polling handler is nil")
+)
+
+// LongPollStats represents cumulative statistics of a long polling session.
+type LongPollStats struct {
+	Requests  int
+	Successes int
+	Failures  int
+}
+
+// LongPoll sends provided request repeatedly until context is done or handler returns an error.
+// Each successful response, including empty ones, is passed to handler and the request is re-issued immediately.
+// Failed requests are retried after backoff duration, LongPoll gives up when consecutive failures reach client's maximum request count.
+// Response bodies are closed after handler returns. Requests with a body must have GetBody function.
+func (c *Client) LongPoll(ctx context.Context, req *http.Request, handler func(res *http.Response) error) (LongPollStats, error) {
+	var stats LongPollStats
+	if handler == nil {
+		return stats, ErrNilLongPollHandler
+	}
+	if p, _ := c.policy(req.Method, req.URL); p != c {
+		return p.LongPoll(ctx, req, handler)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		cycleReq, err := cloneRequest(ctx, req)
+		if err != nil {
+			return stats, err
+		}
+
+		report := &Report{}
+		res, err := c.pipeline(cycleReq, report, nil)
+		for _, a := range report.Attempts {
+			stats.Requests++
+			if a.Err != nil {
+				stats.Failures++
+			}
+		}
+		if err != nil {
+			discard(res)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return stats, ctxErr
+			}
+
+			return stats, err
+		}
+
+		stats.Successes++
+
+		err = handler(res)
+		discard(res)
+		if err != nil {
+			return stats, err
+		}
+	}
+}
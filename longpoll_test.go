@@ -0,0 +1,90 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// LongPoll method should re-issue request immediately after each successful response, retry failed requests and return handler's error with cumulative statistics.
+func TestLongPoll(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	errStop := errors.New("stop")
+	cycles := 0
+	stats, err := c.LongPoll(context.Background(), req, func(res *http.Response) error {
+		cycles++
+		if cycles == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	expected := LongPollStats{Requests: 5, Successes: 3, Failures: 2}
+	if stats != expected {
+		t.Errorf("unexpected statistics, %+v", stats)
+	}
+}
+
+// LongPoll method should give up when consecutive failures reach maximum request count.
+func TestLongPollGiveUp(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	stats, err := c.LongPoll(context.Background(), req, func(res *http.Response) error {
+		return nil
+	})
+
+	var giveUpErr *GiveUpError
+	if !errors.As(err, &giveUpErr) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if giveUpErr.Attempts != 3 || stats.Failures != 3 || stats.Successes != 0 {
+		t.Errorf("unexpected statistics, %+v", stats)
+	}
+}
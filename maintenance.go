@@ -0,0 +1,173 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// maintenance errors
+var (
+	ErrInvalidMaintenance = errors.New("maintenance settings are not valid")
+	ErrMaintenance        = errors.New("host is in maintenance")
+)
+
+// default maintenance settings
+const (
+	defaultMaintenanceThreshold = 3
+	defaultMaintenanceBackoff   = time.Minute
+)
+
+// Maintenance represents settings of maintenance mode detection, zero values take default values.
+// A response signals maintenance when its status code is one of StatusCodes (503) and, when patterns are set, its Header header matches
+// HeaderPattern or its body matches BodyPattern. Up to 64 KiB of a body is matched, the body stays readable.
+// A host responding Threshold (3) consecutive maintenance signals enters maintenance. Attempts to a host in maintenance fail permanently with
+// ErrMaintenance, except a single probe attempt every Backoff (1 minute). The host leaves maintenance when a probe is not responded with a maintenance signal.
+// OnChange is called when a host enters or leaves maintenance.
+type Maintenance struct {
+	StatusCodes   []int
+	Header        string
+	HeaderPattern *regexp.Regexp
+	BodyPattern   *regexp.Regexp
+	Threshold     int
+	Backoff       time.Duration
+	OnChange      func(host string, inMaintenance bool)
+}
+
+// WithMaintenanceDetection configures client to detect sustained maintenance signals of hosts and to stop hammering hosts in maintenance.
+// Maintenance is not detected by default.
+func WithMaintenanceDetection(m Maintenance) Option {
+	return named("WithMaintenanceDetection", func(c *Client) error {
+		if len(m.StatusCodes) == 0 {
+			m.StatusCodes = []int{http.StatusServiceUnavailable}
+		}
+		if m.Threshold == 0 {
+			m.Threshold = defaultMaintenanceThreshold
+		}
+		if m.Backoff == 0 {
+			m.Backoff = defaultMaintenanceBackoff
+		}
+
+		if m.Threshold < 0 || m.Backoff < 0 || (m.HeaderPattern != nil && m.Header == "") {
+			return ErrInvalidMaintenance
+		}
+
+		c.maintenance = &maintenance{
+			cfg:   m,
+			hosts: make(map[string]*hostMaintenance),
+		}
+
+		return nil
+	})
+}
+
+// maintenance holds maintenance state of hosts.
+type maintenance struct {
+	cfg Maintenance
+
+	mu    sync.Mutex
+	hosts map[string]*hostMaintenance
+}
+
+// hostMaintenance holds maintenance state of a host.
+type hostMaintenance struct {
+	signals       int
+	inMaintenance bool
+	nextProbe     time.Time
+}
+
+// admitMaintenance returns ErrMaintenance as permanent error when request's host is in maintenance and it is not time to probe it.
+func (c *Client) admitMaintenance(req *http.Request) error {
+	m := c.maintenance
+	now := c.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.hosts[req.URL.Host]
+	if !ok || !h.inMaintenance {
+		return nil
+	}
+	if now.Before(h.nextProbe) {
+		return Permanent(ErrMaintenance)
+	}
+	h.nextProbe = now.Add(m.cfg.Backoff)
+
+	return nil
+}
+
+// observeMaintenance updates maintenance state of request's host with provided response, calling OnChange when the host enters or leaves maintenance.
+func (c *Client) observeMaintenance(req *http.Request, res *http.Response) {
+	if res == nil {
+		return
+	}
+
+	m := c.maintenance
+	signal := m.signals(res)
+	now := c.clock.Now()
+
+	m.mu.Lock()
+	h, ok := m.hosts[req.URL.Host]
+	if !ok {
+		if !signal {
+			m.mu.Unlock()
+			return
+		}
+		h = &hostMaintenance{}
+		m.hosts[req.URL.Host] = h
+	}
+
+	changed := false
+	if signal {
+		h.signals++
+		if !h.inMaintenance && h.signals >= m.cfg.Threshold {
+			h.inMaintenance = true
+			h.nextProbe = now.Add(m.cfg.Backoff)
+			changed = true
+		}
+	} else {
+		changed = h.inMaintenance
+		delete(m.hosts, req.URL.Host)
+	}
+	inMaintenance := signal
+	m.mu.Unlock()
+
+	if changed && m.cfg.OnChange != nil {
+		c.hook(func() {
+			m.cfg.OnChange(req.URL.Host, inMaintenance)
+		})
+	}
+}
+
+// signals reports whether provided response signals maintenance.
+func (m *maintenance) signals(res *http.Response) bool {
+	matched := false
+	for _, code := range m.cfg.StatusCodes {
+		if res.StatusCode == code {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if m.cfg.HeaderPattern == nil && m.cfg.BodyPattern == nil {
+		return true
+	}
+	if m.cfg.HeaderPattern != nil && m.cfg.HeaderPattern.MatchString(res.Header.Get(m.cfg.Header)) {
+		return true
+	}
+	if m.cfg.BodyPattern == nil || res.Body == nil || res.Body == http.NoBody {
+		return false
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+	res.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+
+	return m.cfg.BodyPattern.Match(body)
+}
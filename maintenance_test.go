@@ -0,0 +1,78 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidMaintenance when invalid maintenance settings are provided.
+func TestInvalidMaintenanceOption(t *testing.T) {
+	if _, err := NewClient(WithMaintenanceDetection(Maintenance{Threshold: -1})); !errors.Is(err, ErrInvalidMaintenance) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithMaintenanceDetection(Maintenance{HeaderPattern: regexp.MustCompile("maintenance")})); !errors.Is(err, ErrInvalidMaintenance) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with maintenance detection should stop sending requests to a host in maintenance, except probes, and notify changes.
+func TestMaintenanceDetection(t *testing.T) {
+	reqCount := 0
+	maintenance := true
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if maintenance {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("down for scheduled maintenance"))
+		}
+	}))
+	defer s.Close()
+
+	var changes []bool
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithClock(clock),
+		WithSleeper(clock),
+		WithMaintenanceDetection(Maintenance{
+			BodyPattern: regexp.MustCompile("maintenance"),
+			Threshold:   2,
+			OnChange: func(host string, inMaintenance bool) {
+				changes = append(changes, inMaintenance)
+			},
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		res, err := c.Get(s.URL)
+		if err == nil {
+			t.Errorf("request %d succeeded", i)
+		}
+		discard(res)
+	}
+	if reqCount != 2 || len(changes) != 1 || !changes[0] {
+		t.Errorf("unexpected request count or changes, %d, %v", reqCount, changes)
+	}
+
+	_, err = c.Get(s.URL)
+	if !errors.Is(err, ErrMaintenance) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	maintenance = false
+	clock.Sleep(time.Minute)
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("probe failed, %v", err)
+	}
+	discard(res)
+	if reqCount != 3 || len(changes) != 2 || changes[1] {
+		t.Errorf("unexpected request count or changes, %d, %v", reqCount, changes)
+	}
+}
@@ -0,0 +1,112 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// mirror errors
+var (
+	ErrInvalidMirror       = errors.New("mirror target must be an absolute url and sample rate must be between zero and one")
+	ErrInvalidMirrorLimits = errors.New("mirror concurrency and timeout must be greater than zero")
+)
+
+// default mirror limits
+const (
+	defaultMirrorConcurrency = 16
+	defaultMirrorTimeout     = 10 * time.Second
+)
+
+// mirrorKey is the context key marking mirrored requests, so that they are not mirrored again.
+type mirrorKey struct{}
+
+// WithMirror configures client to duplicate provided fraction of requests sent with Do and methods built on it to provided target asynchronously,
+// for validating a new backend with real traffic. Mirrored requests keep method, path, query, headers and body of original requests, path is appended to
+// target's path. They are sent once through client's concurrency limits, pacing and rate limits, their responses are discarded and their failures are
+// never returned. Requests with bodies which can not be replayed are not mirrored. At most 16 mirrored requests are in flight, each for at most 10 seconds,
+// see WithMirrorLimits. Requests are not mirrored by default.
+func WithMirror(target string, sampleRate float64) Option {
+	return named("WithMirror", func(c *Client) error {
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w, %s", ErrInvalidMirror, target)
+		}
+		if sampleRate <= 0 || sampleRate > 1 {
+			return ErrInvalidMirror
+		}
+
+		c.mirrorTarget = u
+		c.mirrorRate = sampleRate
+		if c.mirrorInFlight == nil {
+			c.mirrorInFlight = new(int64)
+		}
+		if c.mirrorMax == 0 {
+			c.mirrorMax = defaultMirrorConcurrency
+			c.mirrorTimeout = defaultMirrorTimeout
+		}
+
+		return nil
+	})
+}
+
+// WithMirrorLimits configures the maximum number of requests mirrored with WithMirror in flight and the timeout of each of them,
+// so that a slow mirror target does not pile up goroutines. Sampled requests are dropped while the maximum is reached, they are counted in Stats.
+func WithMirrorLimits(maxInFlight int, timeout time.Duration) Option {
+	return named("WithMirrorLimits", func(c *Client) error {
+		if maxInFlight <= 0 || timeout <= 0 {
+			return ErrInvalidMirrorLimits
+		}
+
+		c.mirrorMax = maxInFlight
+		c.mirrorTimeout = timeout
+
+		return nil
+	})
+}
+
+// mirror sends a copy of provided request to client's mirror target in the background when it is sampled.
+func (c *Client) mirror(req *http.Request) {
+	if req.Context().Value(mirrorKey{}) != nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return
+	}
+	if c.mirrorRate < 1 && c.rand.Float64() >= c.mirrorRate {
+		return
+	}
+
+	if atomic.AddInt64(c.mirrorInFlight, 1) > int64(c.mirrorMax) {
+		atomic.AddInt64(c.mirrorInFlight, -1)
+		c.stats.droppedMirrors.add(1)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.mirrorTimeout)
+	shadow, err := cloneRequest(ContextWithNoRetry(context.WithValue(ctx, mirrorKey{}, true)), req)
+	if err != nil {
+		cancel()
+		atomic.AddInt64(c.mirrorInFlight, -1)
+		return
+	}
+
+	u := *req.URL
+	u.Scheme = c.mirrorTarget.Scheme
+	u.Host = c.mirrorTarget.Host
+	u.Path = strings.TrimSuffix(c.mirrorTarget.Path, "/") + req.URL.Path
+	u.RawPath = ""
+	shadow.URL = &u
+	shadow.Host = ""
+	shadow.Header = req.Header.Clone()
+
+	go func() {
+		defer atomic.AddInt64(c.mirrorInFlight, -1)
+		defer cancel()
+
+		res, _ := c.do(shadow, nil)
+		discard(res)
+	}()
+}
@@ -0,0 +1,108 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidMirror when invalid mirror target or sample rate is provided.
+func TestInvalidMirrorOption(t *testing.T) {
+	if _, err := NewClient(WithMirror("/shadow", 1)); err == nil {
+		t.Error("relative mirror target is accepted")
+	}
+	if _, err := NewClient(WithMirror("http://shadow.example.com", 0)); !errors.Is(err, ErrInvalidMirror) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with a mirror should duplicate requests to the mirror target without surfacing its failures.
+func TestMirror(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	mirrored := make(chan string, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mirrored <- r.Method + " " + r.URL.RequestURI() + " " + string(body)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	c, err := NewClient(
+		WithMirror(shadow.URL+"/shadow", 1),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Post(s.URL+"/users?page=2", "text/plain", bytes.NewReader([]byte("body")))
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+
+	select {
+	case req := <-mirrored:
+		if req != "POST /shadow/users?page=2 body" {
+			t.Errorf("unexpected mirrored request, %s", req)
+		}
+	case <-time.After(time.Second):
+		t.Error("request is not mirrored")
+	}
+}
+
+// Do method of a client with mirror limits should drop samples while too many mirrored requests are in flight and time out mirrored requests.
+func TestMirrorLimits(t *testing.T) {
+	if _, err := NewClient(WithMirrorLimits(0, time.Second)); !errors.Is(err, ErrInvalidMirrorLimits) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var mirrored int32
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mirrored, 1)
+		<-r.Context().Done()
+	}))
+	defer shadow.Close()
+
+	c, err := NewClient(
+		WithMirrorLimits(1, 50*time.Millisecond),
+		WithMirror(shadow.URL, 1),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		res, err := c.Get(s.URL)
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+		}
+		discard(res)
+	}
+	if dropped := c.Stats().DroppedMirrors; dropped != 2 {
+		t.Errorf("unexpected dropped mirror count, %d", dropped)
+	}
+
+	// the hung mirrored request times out and frees its slot
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&mirrored) < 2 && time.Now().Before(deadline) {
+		res, err := c.Get(s.URL)
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+		}
+		discard(res)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&mirrored); n < 2 {
+		t.Errorf("unexpected mirrored request count, %d", n)
+	}
+}
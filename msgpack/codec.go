@@ -0,0 +1,41 @@
+// Package msgpack provides a MessagePack codec for retryablehttp's typed request helpers.
+package msgpack
+
+import (
+	"github.com/ermanimer/retryablehttp"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// content types
+const (
+	ContentType = "application/msgpack"
+)
+
+// alias content types used by some servers for MessagePack
+var aliasContentTypes = []string{"application/x-msgpack", "application/vnd.msgpack"}
+
+// Codec encodes and decodes MessagePack bodies.
+type Codec struct{}
+
+// Register registers codec in provided registry for application/msgpack and its alias content types.
+func Register(r *retryablehttp.CodecRegistry) {
+	r.Register(Codec{})
+	for _, contentType := range aliasContentTypes {
+		r.RegisterAs(contentType, Codec{})
+	}
+}
+
+// ContentType returns application/msgpack.
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+// Marshal encodes provided value as MessagePack.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal decodes MessagePack data into provided value.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
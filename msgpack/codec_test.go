@@ -0,0 +1,64 @@
+package msgpack
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ermanimer/retryablehttp"
+	msgpacklib "github.com/vmihailenco/msgpack/v5"
+)
+
+// greeting represents body of test requests and responses.
+type greeting struct {
+	Name string
+}
+
+// DoCodec method of a client with registered codec should encode request and decode response.
+func TestDoCodec(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != ContentType {
+			t.Errorf("unexpected content type, %s", r.Header.Get("Content-Type"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body failed, %s", err.Error())
+		}
+
+		var in greeting
+		if err := msgpacklib.Unmarshal(body, &in); err != nil {
+			t.Errorf("decoding request body failed, %s", err.Error())
+		}
+
+		out, err := msgpacklib.Marshal(greeting{Name: "hello " + in.Name})
+		if err != nil {
+			t.Errorf("encoding response body failed, %s", err.Error())
+		}
+
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		w.Write(out)
+	}))
+	defer s.Close()
+
+	codecs := retryablehttp.NewCodecRegistry()
+	Register(codecs)
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithCodecRegistry(codecs),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out greeting
+	err = c.DoCodec(context.Background(), http.MethodPost, s.URL, ContentType, greeting{Name: "gopher"}, &out)
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+	}
+	if out.Name != "hello gopher" {
+		t.Errorf("unexpected output, %s", out.Name)
+	}
+}
@@ -0,0 +1,50 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// request mutator errors
+var (
+	ErrNilReqMutator = errors.New("request mutator is nil")
+)
+
+// ReqMutator mutates an attempt of a request in place, such as setting tenant, locale or correlation headers.
+type ReqMutator func(req *http.Request) error
+
+// WithReqMutators configures client to run provided mutators in order on every attempt right before it is sent, mutators of repeated options
+// run after the ones configured earlier. Every attempt is mutated from a copy of the request with its own headers, so that mutations of an attempt
+// are not seen by the next one. Mutation errors fail the attempt and are retried unless they are wrapped with Permanent. Attempts are not mutated by default.
+func WithReqMutators(mutators ...ReqMutator) Option {
+	return named("WithReqMutators", func(c *Client) error {
+		for _, m := range mutators {
+			if m == nil {
+				return ErrNilReqMutator
+			}
+		}
+
+		c.reqMutators = append(c.reqMutators[:len(c.reqMutators):len(c.reqMutators)], mutators...)
+
+		return nil
+	})
+}
+
+// mutate returns a copy of provided attempt request with its own headers, mutated by client's request mutators.
+func (c *Client) mutate(req *http.Request) (*http.Request, error) {
+	mutated := req.WithContext(req.Context())
+	mutated.Header = req.Header.Clone()
+	if mutated.Header == nil {
+		mutated.Header = make(http.Header)
+	}
+
+	for _, m := range c.reqMutators {
+		if err := c.safely(func() error {
+			return m(mutated)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return mutated, nil
+}
@@ -0,0 +1,91 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// NewClient function should return ErrNilReqMutator when nil request mutator is provided.
+func TestNilReqMutatorOption(t *testing.T) {
+	if _, err := NewClient(WithReqMutators(nil)); !errors.Is(err, ErrNilReqMutator) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with request mutators should mutate every attempt in order without leaking mutations into the next attempt.
+func TestReqMutators(t *testing.T) {
+	var tenants, locales []string
+	var counts []int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenants = append(tenants, r.Header.Get("X-Tenant"))
+		locales = append(locales, r.Header.Get("Accept-Language"))
+		counts = append(counts, len(r.Header.Values("X-Hop")))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	attempt := 0
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithReqMutators(func(req *http.Request) error {
+			attempt++
+			req.Header.Set("X-Tenant", "tenant-"+strconv.Itoa(attempt))
+			req.Header.Add("X-Hop", "1")
+
+			return nil
+		}),
+		WithReqMutators(func(req *http.Request) error {
+			req.Header.Set("Accept-Language", req.Header.Get("X-Tenant"))
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, _ := c.Do(req)
+	discard(res)
+	if len(tenants) != 2 || tenants[1] != "tenant-2" || locales[0] != "tenant-1" || counts[1] != 1 {
+		t.Errorf("unexpected headers, %v, %v, %v", tenants, locales, counts)
+	}
+	if req.Header.Get("X-Tenant") != "" {
+		t.Errorf("request is mutated")
+	}
+}
+
+// Do method of a client with request mutators should not send attempts whose mutation fails permanently.
+func TestReqMutatorError(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+	}))
+	defer s.Close()
+
+	errNoTenant := errors.New("no tenant")
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithReqMutators(func(req *http.Request) error {
+			return Permanent(errNoTenant)
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	discard(res)
+	if !errors.Is(err, errNoTenant) || reqCount != 0 {
+		t.Errorf("unexpected error or request count, %v, %d", err, reqCount)
+	}
+}
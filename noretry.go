@@ -0,0 +1,52 @@
+package retryablehttp
+
+import (
+	"context"
+)
+
+// noRetryKey is the context key of disabled retries.
+type noRetryKey struct{}
+
+// ContextWithNoRetry returns a copy of provided context which forces a single attempt for requests using it, regardless of client's maximum request count.
+// It is useful for interactive requests where latency matters more than reliability.
+func ContextWithNoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// noRetry reports whether retries are disabled by provided context.
+func noRetry(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+
+	return disabled
+}
+
+// attemptLimitKey is the context key of attempt limits.
+type attemptLimitKey struct{}
+
+// contextWithAttemptLimit returns a copy of provided context which limits requests using it to provided number of attempts,
+// when it is lower than client's maximum request count.
+func contextWithAttemptLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, attemptLimitKey{}, limit)
+}
+
+// attemptLimit returns attempt limit carried by provided context, it returns zero when the context carries none.
+func attemptLimit(ctx context.Context) int {
+	limit, _ := ctx.Value(attemptLimitKey{}).(int)
+
+	return limit
+}
+
+// retryConditionKey is the context key of retry conditions.
+type retryConditionKey struct{}
+
+// contextWithRetryCondition returns a copy of provided context which replaces client's retry condition with provided one for requests using it.
+func contextWithRetryCondition(ctx context.Context, cond RetryCondition) context.Context {
+	return context.WithValue(ctx, retryConditionKey{}, cond)
+}
+
+// retryConditionFromContext returns retry condition carried by provided context, it reports whether the context carries one.
+func retryConditionFromContext(ctx context.Context) (RetryCondition, bool) {
+	cond, ok := ctx.Value(retryConditionKey{}).(RetryCondition)
+
+	return cond, ok
+}
@@ -0,0 +1,38 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Do method of a client should send a single attempt for requests with no retry context.
+func TestContextWithNoRetry(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequestWithContext(ContextWithNoRetry(context.Background()), http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
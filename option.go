@@ -0,0 +1,170 @@
+package retryablehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// option errors
+var (
+	ErrConflictingOptions = errors.New("options conflict")
+)
+
+// OptionError represents the error of an option, Option is the name of the function creating the option, such as "WithMaxReqCount".
+type OptionError struct {
+	Option string
+	Err    error
+}
+
+// Error returns error message including option name and its error.
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("%s, %s", e.Option, e.Err)
+}
+
+// Unwrap returns option's error.
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// OptionsError represents the errors of every invalid option of a client and the conflicts between its options.
+type OptionsError struct {
+	Errs []error
+}
+
+// Error returns joined error messages.
+func (e *OptionsError) Error() string {
+	messages := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		messages[i] = err.Error()
+	}
+
+	return "invalid options: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether any error of options matches provided target.
+func (e *OptionsError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error of options matching provided target, and if so, sets target to it.
+func (e *OptionsError) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyOptions applies every provided option to provided client and validates consistency of client's options,
+// it returns an OptionsError listing every invalid option and every conflict.
+func applyOptions(c *Client, opts []Option) error {
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			errs = append(errs, optionError(err))
+		}
+	}
+	errs = append(errs, c.conflicts()...)
+
+	if len(errs) > 0 {
+		return &OptionsError{Errs: errs}
+	}
+
+	return nil
+}
+
+// named returns provided option reporting its errors as OptionError with provided name of the function creating it, such as "WithMaxReqCount".
+// Errors of options applied by the option, such as policy options, are reported with provided name.
+func named(name string, opt Option) Option {
+	return func(c *Client) error {
+		err := opt(c)
+		if err == nil {
+			return nil
+		}
+		if optionErr, ok := err.(*OptionError); ok {
+			err = optionErr.Err
+		}
+
+		return &OptionError{Option: name, Err: err}
+	}
+}
+
+// optionError returns provided error of an option as OptionError, errors of options not created by this package are reported as "option".
+func optionError(err error) error {
+	if _, ok := err.(*OptionError); ok {
+		return err
+	}
+
+	return &OptionError{Option: "option", Err: err}
+}
+
+// conflicts returns errors of client's options which are valid on their own but conflict with each other, or have no effect without another option.
+// Options depending on another option are accepted when the other option is configured by a policy of client.
+func (c *Client) conflicts() []error {
+	var errs []error
+	conflict := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w, "+format, append([]interface{}{ErrConflictingOptions}, args...)...))
+	}
+
+	if timeout := c.httpClient.Timeout; timeout > 0 && c.minAttemptTime > timeout {
+		conflict("minimum attempt time %s exceeds http client timeout %s", c.minAttemptTime, timeout)
+	}
+	if c.maxBackoff > 0 && c.maxTotalBackoff > 0 && c.maxBackoff > c.maxTotalBackoff {
+		conflict("maximum backoff %s exceeds maximum total backoff %s", c.maxBackoff, c.maxTotalBackoff)
+	}
+	if c.redirectAuth != nil && c.redirectPolicy == RedirectAccept {
+		conflict("WithRedirectAuth has no effect with RedirectAccept, redirects are not followed")
+	}
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok && c.expectContinue && t.ExpectContinueTimeout == 0 {
+		conflict("WithExpectContinue requires a transport with ExpectContinueTimeout")
+	}
+
+	if !c.configured(func(s *settings) bool { return s.endpoints != nil }) {
+		for _, o := range []struct {
+			name string
+			set  bool
+		}{
+			{"WithEndpointEjection", c.ejectionFailures != 0},
+			{"WithAffinity", c.affinity != nil},
+			{"WithLocality", c.localRegion != ""},
+			{"WithEndpointSelection", c.endpointSelection != SelectRoundRobin},
+			{"WithFailoverPolicy", c.failoverPolicy != nil},
+		} {
+			if o.set {
+				conflict("%s requires WithEndpoints or WithEndpointResolver", o.name)
+			}
+		}
+	}
+	if (c.maxQueueDepth > 0 || c.maxQueueWait > 0) && !c.configured(func(s *settings) bool { return s.limiter != nil || s.semaphore != nil }) {
+		conflict("WithLoadShedding requires WithAdaptiveConcurrency or WithHostSemaphore")
+	}
+	if c.mirrorMax != 0 && !c.configured(func(s *settings) bool { return s.mirrorTarget != nil }) {
+		conflict("WithMirrorLimits requires WithMirror")
+	}
+
+	return errs
+}
+
+// configured reports whether provided check holds for client's settings or for settings of any of client's policies.
+func (c *Client) configured(check func(s *settings) bool) bool {
+	if check(&c.settings) {
+		return true
+	}
+	for _, p := range c.policies {
+		if check(p.settings) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NewClient function should apply every option and return an error listing every invalid option with its name.
+func TestOptionsError(t *testing.T) {
+	_, err := NewClient(
+		WithMaxReqCount(0),
+		WithBackoff(time.Second),
+		WithResHandler(nil),
+	)
+	if !errors.Is(err, ErrInvalidMaxReqCount) || !errors.Is(err, ErrNilResHandler) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	var optionsErr *OptionsError
+	if !errors.As(err, &optionsErr) || len(optionsErr.Errs) != 2 {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	var optionErr *OptionError
+	if !errors.As(optionsErr.Errs[1], &optionErr) || optionErr.Option != "WithResHandler" {
+		t.Errorf("unexpected option error, %v", optionsErr.Errs[1])
+	}
+	if !strings.Contains(err.Error(), "WithMaxReqCount, maximum request count is not valid") {
+		t.Errorf("unexpected error message, %s", err.Error())
+	}
+}
+
+// NewClient function should name errors of nested options after the outer option and errors of custom options as "option".
+func TestOptionErrorNames(t *testing.T) {
+	errCustom := errors.New("custom")
+	_, err := NewClient(
+		WithHostPolicy("example.com", WithMaxReqCount(0)),
+		func(c *Client) error { return errCustom },
+	)
+
+	var optionsErr *OptionsError
+	if !errors.As(err, &optionsErr) || len(optionsErr.Errs) != 2 {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	for i, expected := range []string{"WithHostPolicy, maximum request count is not valid", "option, custom"} {
+		if optionsErr.Errs[i].Error() != expected {
+			t.Errorf("unexpected error message, %s", optionsErr.Errs[i].Error())
+		}
+	}
+	if !errors.Is(err, ErrInvalidMaxReqCount) || !errors.Is(err, errCustom) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// NewClient function should return ErrConflictingOptions when options are valid on their own but conflict with each other.
+func TestConflictingOptions(t *testing.T) {
+	_, err := NewClient(
+		WithMinAttemptTime(2*time.Second),
+		WithHTTPClient(&http.Client{Timeout: time.Second}),
+	)
+	if !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	_, err = NewClient(
+		WithMinAttemptTime(time.Second),
+		WithHTTPClient(&http.Client{Timeout: 2 * time.Second}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+}
+
+// NewClient function and With method should report every option which has no effect without another option.
+func TestDependentOptions(t *testing.T) {
+	_, err := NewClient(
+		WithAffinity(func(req *http.Request) string { return "" }),
+		WithLoadShedding(1, 0),
+		WithMirrorLimits(1, time.Second),
+		WithRedirectPolicy(RedirectAccept, 0),
+		WithRedirectAuth(func(req *http.Request) error { return nil }),
+	)
+	var optionsErr *OptionsError
+	if !errors.As(err, &optionsErr) || len(optionsErr.Errs) != 4 || !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	c, err := NewClient(
+		WithLoadShedding(1, 0),
+		WithHostPolicy("example.com", WithHostSemaphore("example.com", 1)),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	if _, err := c.With(WithExpectContinue(0), WithHTTPClient(&http.Client{Transport: &http.Transport{}})); !errors.Is(err, ErrConflictingOptions) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := c.With(WithEndpoints("api.internal", "http://127.0.0.1:8080"), WithAffinity(func(req *http.Request) string { return "" })); err != nil {
+		t.Errorf("deriving client failed, %s", err.Error())
+	}
+}
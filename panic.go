@@ -0,0 +1,77 @@
+package retryablehttp
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// panic errors
+var (
+	ErrInvalidPanicPolicy = errors.New("panic policy is not valid")
+)
+
+// PanicPolicy represents how panics of user supplied handlers and hooks are treated.
+type PanicPolicy int
+
+// panic policies
+const (
+	// PanicPropagate does not recover panics, it is the default.
+	PanicPropagate PanicPolicy = iota
+	// PanicRetry recovers panics and retries attempts failed by them.
+	PanicRetry
+	// PanicFail recovers panics and fails requests permanently.
+	PanicFail
+)
+
+// PanicError represents a recovered panic of a user supplied handler or hook, Stack holds the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns error message including panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic, %v", e.Value)
+}
+
+// WithPanicRecovery configures client to recover panics of response handlers, response validators and backoff policies as *PanicError
+// failing the attempt, which is retried or fails permanently according to provided policy. Panics of audit sinks and slow request callbacks
+// are recovered and dropped. Panics are propagated by default.
+func WithPanicRecovery(policy PanicPolicy) Option {
+	return named("WithPanicRecovery", func(c *Client) error {
+		if policy < PanicPropagate || policy > PanicFail {
+			return ErrInvalidPanicPolicy
+		}
+
+		c.panicPolicy = policy
+
+		return nil
+	})
+}
+
+// safely calls provided function, converting its panic to an error according to client's panic policy.
+func (c *Client) safely(fn func() error) (err error) {
+	if c.panicPolicy == PanicPropagate {
+		return fn()
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+			if c.panicPolicy == PanicFail {
+				err = Permanent(err)
+			}
+		}
+	}()
+
+	return fn()
+}
+
+// hook calls provided hook function, dropping its panic when client recovers panics.
+func (c *Client) hook(fn func()) {
+	c.safely(func() error {
+		fn()
+		return nil
+	})
+}
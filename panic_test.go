@@ -0,0 +1,87 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidPanicPolicy when unknown panic policy is provided.
+func TestInvalidPanicPolicyOption(t *testing.T) {
+	_, err := NewClient(
+		WithPanicRecovery(PanicPolicy(3)),
+	)
+	if !errors.Is(err, ErrInvalidPanicPolicy) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with panic recovery should convert handler panics to errors, retrying or failing according to panic policy.
+func TestPanicRecovery(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	for policy, expectedAttempts := range map[PanicPolicy]int{PanicRetry: 3, PanicFail: 1} {
+		attempts := 0
+		c, err := NewClient(
+			WithMaxReqCount(3),
+			WithBackoff(0),
+			WithPanicRecovery(policy),
+			WithResHandler(func(res *http.Response) error {
+				attempts++
+				panic("handler failed")
+			}),
+			WithSlowThreshold(time.Nanosecond, func(slow SlowRequest) {
+				panic("callback failed")
+			}),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		_, err = c.Do(req)
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) || panicErr.Value != "handler failed" || len(panicErr.Stack) == 0 {
+			t.Errorf("unexpected error, %v", err)
+		}
+		if attempts != expectedAttempts {
+			t.Errorf("unexpected attempt count, %d", attempts)
+		}
+	}
+}
+
+// Do method of a client with panic recovery should fail when backoff policy panics.
+func TestBackoffPolicyPanicRecovery(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithPanicRecovery(PanicRetry),
+		WithBackoffPolicy(backoffFunc(func(attempt int) time.Duration {
+			panic("backoff failed")
+		})),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	var panicErr *PanicError
+	if _, err := c.Do(req); !errors.As(err, &panicErr) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
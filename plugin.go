@@ -0,0 +1,58 @@
+package retryablehttp
+
+import (
+	"errors"
+)
+
+// plugin errors
+var (
+	ErrNilPlugin = errors.New("plugin is nil")
+)
+
+// Plugin represents an integration bundling options of a client, such as hooks, middleware and metrics, as a single attachable unit.
+// Install configures provided client, typically by applying options to it.
+type Plugin interface {
+	Install(c *Client) error
+}
+
+// PluginFunc is an adapter to use a function as plugin.
+type PluginFunc func(c *Client) error
+
+// Install calls f(c).
+func (f PluginFunc) Install(c *Client) error {
+	return f(c)
+}
+
+// OptionsPlugin returns a plugin applying provided options in order.
+func OptionsPlugin(opts ...Option) Plugin {
+	return PluginFunc(func(c *Client) error {
+		for _, opt := range opts {
+			if err := opt(c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// WithPlugins configures client by installing provided plugins in order.
+func WithPlugins(plugins ...Plugin) Option {
+	return named("WithPlugins", func(c *Client) error {
+		for _, p := range plugins {
+			if p == nil {
+				return ErrNilPlugin
+			}
+			if err := p.Install(c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Use returns a clone of client with provided plugins installed in order, client itself is not modified.
+func (c *Client) Use(plugins ...Plugin) (*Client, error) {
+	return c.With(WithPlugins(plugins...))
+}
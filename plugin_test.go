@@ -0,0 +1,70 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrNilPlugin when nil plugin is provided and errors of plugins failing to install.
+func TestInvalidPluginOptions(t *testing.T) {
+	if _, err := NewClient(WithPlugins(nil)); !errors.Is(err, ErrNilPlugin) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := NewClient(WithPlugins(OptionsPlugin(WithMaxReqCount(0)))); !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Use method of a client should return a clone with provided plugins installed in order, leaving the client unchanged.
+func TestUse(t *testing.T) {
+	var tenants []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenants = append(tenants, r.Header.Get("X-Tenant"))
+	}))
+	defer s.Close()
+
+	var installed []string
+	tenant := PluginFunc(func(c *Client) error {
+		installed = append(installed, "tenant")
+
+		return WithReqMutators(func(req *http.Request) error {
+			req.Header.Set("X-Tenant", "acme")
+
+			return nil
+		})(c)
+	})
+	retries := PluginFunc(func(c *Client) error {
+		installed = append(installed, "retries")
+
+		return OptionsPlugin(WithMaxReqCount(3), WithBackoff(0)).Install(c)
+	})
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+	extended, err := c.Use(tenant, retries)
+	if err != nil {
+		t.Errorf("using plugins failed, %s", err.Error())
+	}
+	if len(installed) != 2 || installed[0] != "tenant" || extended.maxReqCount != 3 {
+		t.Errorf("unexpected installed plugins, %v, %d", installed, extended.maxReqCount)
+	}
+
+	res, _ := extended.Get(s.URL)
+	discard(res)
+	res, _ = c.Get(s.URL)
+	discard(res)
+	if len(tenants) != 2 || tenants[0] != "acme" || tenants[1] != "" {
+		t.Errorf("unexpected tenants, %v", tenants)
+	}
+
+	failing := PluginFunc(func(c *Client) error {
+		return errors.New("exporter is not configured")
+	})
+	if _, err := c.Use(failing); err == nil {
+		t.Errorf("failing plugin is installed")
+	}
+}
@@ -0,0 +1,262 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// policy errors
+var (
+	ErrInvalidHostPattern    = errors.New("host pattern is not valid")
+	ErrInvalidPathPattern    = errors.New("path pattern is not valid")
+	ErrInvalidRetryCondition = errors.New("retry condition is not valid")
+	ErrBackgroundPolicy      = errors.New("options starting background work can not be used in policies")
+)
+
+// policy represents options applied to requests whose method, host or path matches, rule describes the match.
+// Settings are the settings its options configure on a client with default options, they are used for checking conflicts of client's options.
+type policy struct {
+	rule     string
+	match    func(method string, u *url.URL) bool
+	opts     []Option
+	settings *settings
+}
+
+// WithHostPolicy configures client to apply provided options, such as maximum request count and backoff, to requests whose host matches provided pattern.
+// Patterns are matched case insensitively against host name, or against host and port when pattern has a port. "*" matches any part of a name,
+// such as "*.example.com". Policies are matched in configuration order and the first matching policy applies on top of client's options.
+// Requests matching a policy share client's statistics. Options of a policy are applied once per client, so that state of options such as
+// WithRetryRateLimit is shared by requests matching the policy. Options starting background work, such as WithConfigProvider, can not be used in policies.
+func WithHostPolicy(hostPattern string, opts ...Option) Option {
+	return named("WithHostPolicy", func(c *Client) error {
+		hostPattern = strings.ToLower(hostPattern)
+		if _, err := path.Match(hostPattern, ""); hostPattern == "" || err != nil {
+			return ErrInvalidHostPattern
+		}
+
+		match := func(method string, u *url.URL) bool {
+			host := u.Hostname()
+			if strings.Contains(hostPattern, ":") {
+				host = u.Host
+			}
+
+			matched, _ := path.Match(hostPattern, strings.ToLower(host))
+
+			return matched
+		}
+
+		return c.addPolicy(policy{rule: "host " + hostPattern, match: match, opts: opts})
+	})
+}
+
+// WithPathPolicy configures client to apply provided options to requests whose url path matches provided pattern, such as "/v1/charges/*".
+// "*" matches a single path segment. Path policies are matched together with host policies in configuration order, the first matching policy applies.
+// The matched policy is exposed in reports.
+func WithPathPolicy(pathPattern string, opts ...Option) Option {
+	return named("WithPathPolicy", func(c *Client) error {
+		if _, err := path.Match(pathPattern, ""); !strings.HasPrefix(pathPattern, "/") || err != nil {
+			return ErrInvalidPathPattern
+		}
+
+		match := func(method string, u *url.URL) bool {
+			p := u.Path
+			if p == "" {
+				p = "/"
+			}
+
+			matched, _ := path.Match(pathPattern, p)
+
+			return matched
+		}
+
+		return c.addPolicy(policy{rule: "path " + pathPattern, match: match, opts: opts})
+	})
+}
+
+// addPolicy validates options of provided policy and adds it to client's policies.
+func (c *Client) addPolicy(p policy) error {
+	probe := &Client{}
+	probe.init()
+	defer probe.Close()
+	for _, opt := range p.opts {
+		if err := opt(probe); err != nil {
+			return err
+		}
+	}
+	if len(probe.pending) > 0 {
+		return ErrBackgroundPolicy
+	}
+	p.settings = &probe.settings
+
+	c.policies = append(c.policies, p)
+
+	return nil
+}
+
+// policy returns a client derived with options of the first policy matching provided method and url and the policy's rule,
+// it returns client itself and an empty rule when no policy matches.
+func (c *Client) policy(method string, u *url.URL) (*Client, string) {
+	c.init()
+
+	if len(c.policies) == 0 || u == nil {
+		return c, ""
+	}
+
+	for i, p := range c.policies {
+		if p.match(method, u) {
+			return c.derived(i, p.opts), p.rule
+		}
+	}
+
+	return c, ""
+}
+
+// policyURL is like policy for a raw url, it returns client itself when url cannot be parsed.
+func (c *Client) policyURL(method, rawURL string) *Client {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c
+	}
+
+	p, _ := c.policy(method, u)
+
+	return p
+}
+
+// derived returns client derived with provided options of policy with provided index, deriving it once until client's settings change.
+func (c *Client) derived(i int, opts []Option) *Client {
+	c.mu.RLock()
+	d := c.policyClients[i]
+	c.mu.RUnlock()
+	if d != nil {
+		return d
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d := c.policyClients[i]; d != nil {
+		return d
+	}
+	d = c.derive(opts)
+	if c.policyClients == nil {
+		c.policyClients = make(map[int]*Client)
+	}
+	c.policyClients[i] = d
+
+	return d
+}
+
+// derive returns a copy of client sharing its statistics with provided options applied, options are validated when policies are configured.
+// Callers must hold client's lock.
+func (c *Client) derive(opts []Option) *Client {
+	d := &Client{settings: c.settings}
+	d.initOnce.Do(func() {})
+	d.stats = c.stats
+	d.policies = nil
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// RetryCondition represents which failures of an attempt are retried.
+type RetryCondition int
+
+// retry conditions
+const (
+	// RetryOnAnyFailure retries every failure which is not permanent, it is the default.
+	RetryOnAnyFailure RetryCondition = iota
+	// RetryOnConnectionFailure retries only failures happening before request headers are written, such as dial failures,
+	// so that non-idempotent requests are never processed twice.
+	RetryOnConnectionFailure
+)
+
+// Policy represents a declarative retry policy.
+// Zero MaxReqCount and nil Backoff keep client's settings.
+type Policy struct {
+	MaxReqCount int
+	Backoff     BackoffPolicy
+	RetryOn     RetryCondition
+}
+
+// options converts policy to options.
+func (p Policy) options() []Option {
+	var opts []Option
+	if p.MaxReqCount != 0 {
+		opts = append(opts, WithMaxReqCount(p.MaxReqCount))
+	}
+	if p.Backoff != nil {
+		opts = append(opts, WithBackoffPolicy(p.Backoff))
+	}
+	opts = append(opts, withRetryCondition(p.RetryOn))
+
+	return opts
+}
+
+// WithMethodPolicy configures client to apply provided policies to requests by http method, such as
+// retrying GET and HEAD requests aggressively while retrying POST and PATCH requests only on connection failures.
+// Method policies are matched together with host and path policies in configuration order, the first matching policy applies.
+func WithMethodPolicy(policies map[string]Policy) Option {
+	return named("WithMethodPolicy", func(c *Client) error {
+		methods := make([]string, 0, len(policies))
+		for method := range policies {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			method := method
+			match := func(m string, u *url.URL) bool {
+				return strings.EqualFold(m, method)
+			}
+
+			p := policy{rule: "method " + strings.ToUpper(method), match: match, opts: policies[method].options()}
+			if err := c.addPolicy(p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// withRetryCondition configures client's retry condition.
+func withRetryCondition(cond RetryCondition) Option {
+	return func(c *Client) error {
+		if cond != RetryOnAnyFailure && cond != RetryOnConnectionFailure {
+			return ErrInvalidRetryCondition
+		}
+
+		c.retryOn = cond
+
+		return nil
+	}
+}
+
+// traceWrite returns a shallow copy of provided request which records in provided progress when request headers are written.
+func traceWrite(p *progress, req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		WroteHeaders: func() {
+			atomic.StoreInt32(&p.wrote, 1)
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// retryable reports whether failure of call's current attempt may be retried according to retry condition the call started with.
+func (c *Client) retryable(cl *call) bool {
+	if cl.retryOn == RetryOnConnectionFailure {
+		return cl.res == nil && !cl.progress.written()
+	}
+
+	return true
+}
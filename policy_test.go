@@ -0,0 +1,201 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// NewClient function should return errors of invalid host patterns and policy options.
+func TestInvalidHostPolicyOption(t *testing.T) {
+	for _, pattern := range []string{"", "[a-"} {
+		_, err := NewClient(
+			WithHostPolicy(pattern),
+		)
+		if !errors.Is(err, ErrInvalidHostPattern) {
+			t.Errorf("unexpected error for %q, %v", pattern, err)
+		}
+	}
+
+	_, err := NewClient(
+		WithHostPolicy("*.example.com", WithMaxReqCount(0)),
+	)
+	if !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with host policies should apply options of the first policy matching request's host.
+func TestHostPolicy(t *testing.T) {
+	attempts := make(map[string]int)
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts[req.URL.Host]++
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMaxReqCount(2),
+		WithHostPolicy("*.Example.com", WithMaxReqCount(3)),
+		WithHostPolicy("api.example.com", WithMaxReqCount(5)),
+		WithHostPolicy("svc.internal:8443", WithMaxReqCount(4)),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	for _, rawURL := range []string{"http://api.example.com/v1", "http://other.com", "http://svc.internal:8443", "http://svc.internal:9443"} {
+		req, err := http.NewRequest(http.MethodGet, rawURL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		c.Do(req)
+	}
+
+	expected := map[string]int{"api.example.com": 3, "other.com": 2, "svc.internal:8443": 4, "svc.internal:9443": 2}
+	for host, n := range expected {
+		if attempts[host] != n {
+			t.Errorf("unexpected attempt count for %s, %d", host, attempts[host])
+		}
+	}
+	if hosts := c.Stats().Hosts; hosts["api.example.com"].Attempts != 3 {
+		t.Errorf("policy attempts are not counted in client's statistics, %+v", hosts)
+	}
+}
+
+// Do method of a client with a host policy should share state of policy options, such as retry rate limits, between requests.
+func TestHostPolicyState(t *testing.T) {
+	attempts := 0
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithHostPolicy("example.com", WithMaxReqCount(5), WithRetryRateLimit(0.0001, 1)),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 5; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		c.Do(req)
+	}
+
+	// a single retry is allowed by the burst of the shared limit
+	if attempts != 6 {
+		t.Errorf("unexpected attempt count, %d", attempts)
+	}
+
+	if err := c.SetMaxReqCount(2); err != nil {
+		t.Errorf("setting maximum request count failed, %s", err.Error())
+	}
+	if p, _ := c.policy(http.MethodGet, &url.URL{Host: "example.com"}); p.maxReqCount != 5 {
+		t.Errorf("unexpected maximum request count of policy, %d", p.maxReqCount)
+	}
+}
+
+// DoWithReport method of a client with path policies should apply options of the matching policy and report it.
+func TestPathPolicy(t *testing.T) {
+	if _, err := NewClient(WithPathPolicy("v1/*")); !errors.Is(err, ErrInvalidPathPattern) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	attempts := make(map[string]int)
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts[req.URL.Path]++
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMaxReqCount(3),
+		WithPathPolicy("/v1/charges/*", WithMaxReqCount(1)),
+		WithPathPolicy("/v1/reports/*", WithMaxReqCount(5)),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	expected := map[string]struct {
+		attempts int
+		policy   string
+	}{
+		"/v1/charges/ch_1":  {1, "path /v1/charges/*"},
+		"/v1/reports/daily": {5, "path /v1/reports/*"},
+		"/v1/customers":     {3, ""},
+	}
+	for p, e := range expected {
+		req, err := http.NewRequest(http.MethodPost, "http://api.example.com"+p, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		_, report, _ := c.DoWithReport(req)
+		if attempts[p] != e.attempts || len(report.Attempts) != e.attempts || report.Policy != e.policy {
+			t.Errorf("unexpected attempt count %d or policy %q for %s", attempts[p], report.Policy, p)
+		}
+	}
+}
+
+// Do method of a client with method policies should retry POST requests only on failures before request is written.
+func TestMethodPolicy(t *testing.T) {
+	_, err := NewClient(
+		WithMethodPolicy(map[string]Policy{"GET": {MaxReqCount: -1}}),
+	)
+	if !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	attempts := make(map[string]int)
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts[req.Method+" "+req.URL.Path]++
+		if req.URL.Path == "/dial" {
+			return nil, errors.New("connection refused")
+		}
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMaxReqCount(2),
+		WithMethodPolicy(map[string]Policy{
+			"get":  {MaxReqCount: 4},
+			"POST": {MaxReqCount: 3, RetryOn: RetryOnConnectionFailure},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	expected := map[string]int{"GET /": 4, "POST /": 1, "POST /dial": 3, "DELETE /": 2}
+	for key := range expected {
+		parts := strings.SplitN(key, " ", 2)
+		req, err := http.NewRequest(parts[0], "http://api.example.com"+parts[1], http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		c.Do(req)
+	}
+
+	for key, n := range expected {
+		if attempts[key] != n {
+			t.Errorf("unexpected attempt count for %s, %d", key, attempts[key])
+		}
+	}
+}
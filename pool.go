@@ -0,0 +1,55 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// bufferClasses are capacities of pooled buffers, buffers grown beyond the largest class are not pooled.
+var bufferClasses = [...]int{4 << 10, 32 << 10, 256 << 10, 1 << 20}
+
+// bufferPools holds pooled buffers of each buffer class.
+var bufferPools [len(bufferClasses)]sync.Pool
+
+// getBuffer returns an empty pooled buffer with capacity of the smallest class fitting provided size hint and a final read.
+func getBuffer(sizeHint int64) *bytes.Buffer {
+	i := 0
+	for i < len(bufferClasses)-1 && int64(bufferClasses[i]) < sizeHint+bytes.MinRead {
+		i++
+	}
+
+	if b, ok := bufferPools[i].Get().(*bytes.Buffer); ok {
+		return b
+	}
+
+	return bytes.NewBuffer(make([]byte, 0, bufferClasses[i]))
+}
+
+// putBuffer resets provided buffer and returns it to the pool of the largest class it fits, oversized buffers are dropped.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > bufferClasses[len(bufferClasses)-1] || b.Cap() < bufferClasses[0] {
+		return
+	}
+
+	i := len(bufferClasses) - 1
+	for bufferClasses[i] > b.Cap() {
+		i--
+	}
+
+	b.Reset()
+	bufferPools[i].Put(b)
+}
+
+// readAll reads provided reader until EOF into a pooled buffer and returns an exactly sized copy of its content.
+// Provided size hint, such as a content length, selects the buffer class, a negative hint selects the smallest class.
+func readAll(r io.Reader, sizeHint int64) ([]byte, error) {
+	b := getBuffer(sizeHint)
+	defer putBuffer(b)
+
+	_, err := b.ReadFrom(r)
+	data := make([]byte, b.Len())
+	copy(data, b.Bytes())
+
+	return data, err
+}
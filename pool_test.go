@@ -0,0 +1,51 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// getBuffer function should return buffers of the smallest class fitting size hint and putBuffer function should drop oversized buffers.
+func TestBufferPool(t *testing.T) {
+	for hint, expected := range map[int64]int{-1: 4 << 10, 10 << 10: 32 << 10, 300 << 10: 1 << 20, 10 << 20: 1 << 20} {
+		b := getBuffer(hint)
+		if b.Len() != 0 || b.Cap() < expected {
+			t.Errorf("unexpected buffer for size hint %d, length %d, capacity %d", hint, b.Len(), b.Cap())
+		}
+		putBuffer(b)
+	}
+
+	oversized := bytes.NewBuffer(make([]byte, 0, 2<<20))
+	putBuffer(oversized)
+	for i := 0; i < 10; i++ {
+		if getBuffer(1<<20) == oversized {
+			t.Error("oversized buffer is pooled")
+		}
+	}
+}
+
+// readAll function should return an exactly sized copy not sharing memory with pooled buffers.
+func TestReadAll(t *testing.T) {
+	first, err := readAll(strings.NewReader("first"), 5)
+	if err != nil {
+		t.Errorf("reading failed, %s", err.Error())
+	}
+	second, err := readAll(strings.NewReader("second"), 6)
+	if err != nil {
+		t.Errorf("reading failed, %s", err.Error())
+	}
+
+	if string(first) != "first" || string(second) != "second" || cap(first) != 5 {
+		t.Errorf("unexpected content, %q, %q", first, second)
+	}
+}
+
+// BenchmarkReadAll measures allocations of reading bodies through pooled buffers.
+func BenchmarkReadAll(b *testing.B) {
+	body := strings.Repeat("x", 100<<10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		readAll(strings.NewReader(body), int64(len(body)))
+	}
+}
@@ -0,0 +1,139 @@
+package retryablehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// response preset errors
+var (
+	ErrInvalidResponsePreset = errors.New("response preset is not valid")
+)
+
+// ResponsePreset represents a built-in response handler.
+type ResponsePreset int
+
+// response presets
+const (
+	// Accept2xx accepts responses with 2xx status codes, it is the default response handler.
+	Accept2xx ResponsePreset = iota
+	// Accept2xx3xx accepts responses with 2xx and 3xx status codes.
+	Accept2xx3xx
+	// JSONAPIErrors accepts responses with 2xx status codes and returns *ProblemError for RFC 7807 problem details of other responses.
+	// Problems of client errors other than 408, 425 and 429 are returned as permanent errors, since retrying them can not succeed.
+	JSONAPIErrors
+	// HeadOnly accepts responses with 2xx status codes judging them by status line and headers only, bodies of accepted responses are drained,
+	// closed and replaced with http.NoBody, so that existence checks do not need to close them.
+	HeadOnly
+)
+
+// WithResponsePreset configures client's response handler to provided built-in response handler, see WithResHandler.
+func WithResponsePreset(preset ResponsePreset) Option {
+	return named("WithResponsePreset", func(c *Client) error {
+		var handler func(res *http.Response) error
+		switch preset {
+		case Accept2xx:
+			handler = defaultResHandler
+		case Accept2xx3xx:
+			handler = accept2xx3xx
+		case JSONAPIErrors:
+			handler = jsonAPIErrors
+		case HeadOnly:
+			handler = headOnly
+		default:
+			return ErrInvalidResponsePreset
+		}
+
+		c.resHandler = handler
+
+		return nil
+	})
+}
+
+// ProblemError represents RFC 7807 problem details of an unsuccessful response, it wraps ErrUnsuccessfulStatusCode.
+type ProblemError struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// Error returns error message including status and title and detail of the problem.
+func (e *ProblemError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s, %d %s", ErrUnsuccessfulStatusCode, e.Status, e.Title)
+	}
+
+	return fmt.Sprintf("%s, %d %s: %s", ErrUnsuccessfulStatusCode, e.Status, e.Title, e.Detail)
+}
+
+// Unwrap returns ErrUnsuccessfulStatusCode.
+func (e *ProblemError) Unwrap() error {
+	return ErrUnsuccessfulStatusCode
+}
+
+// accept2xx3xx accepts responses with 2xx and 3xx status codes.
+func accept2xx3xx(res *http.Response) error {
+	if res == nil {
+		return ErrNilRes
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 399 {
+		return ErrUnsuccessfulStatusCode
+	}
+
+	return nil
+}
+
+// jsonAPIErrors accepts responses with 2xx status codes and parses problem details of other responses, bodies stay readable.
+func jsonAPIErrors(res *http.Response) error {
+	if err := defaultResHandler(res); err == nil || res == nil {
+		return err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/problem+json" {
+		return ErrUnsuccessfulStatusCode
+	}
+
+	body, err := PeekBody(res, maxErrorBodyBytes)
+	if err != nil {
+		return ErrUnsuccessfulStatusCode
+	}
+
+	problem := &ProblemError{}
+	if err := json.Unmarshal(body, problem); err != nil {
+		return ErrUnsuccessfulStatusCode
+	}
+	if problem.Status == 0 {
+		problem.Status = res.StatusCode
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(res.StatusCode)
+	}
+
+	switch code := res.StatusCode; {
+	case code == http.StatusRequestTimeout || code == http.StatusTooEarly || code == http.StatusTooManyRequests:
+		return problem
+	case code >= 400 && code <= 499:
+		return Permanent(problem)
+	}
+
+	return problem
+}
+
+// headOnly accepts responses with 2xx status codes, replacing bodies of accepted responses with http.NoBody.
+func headOnly(res *http.Response) error {
+	if err := defaultResHandler(res); err != nil {
+		return err
+	}
+
+	discard(res)
+	res.Body = http.NoBody
+
+	return nil
+}
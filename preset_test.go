@@ -0,0 +1,103 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidResponsePreset when unknown response preset is provided.
+func TestInvalidResponsePresetOption(t *testing.T) {
+	if _, err := NewClient(WithResponsePreset(ResponsePreset(-1))); !errors.Is(err, ErrInvalidResponsePreset) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Response presets should accept responses by their status codes.
+func TestResponsePresets(t *testing.T) {
+	tests := []struct {
+		preset   ResponsePreset
+		status   int
+		accepted bool
+	}{
+		{Accept2xx, http.StatusOK, true},
+		{Accept2xx, http.StatusNotModified, false},
+		{Accept2xx3xx, http.StatusNotModified, true},
+		{Accept2xx3xx, http.StatusNotFound, false},
+		{HeadOnly, http.StatusNoContent, true},
+		{HeadOnly, http.StatusInternalServerError, false},
+	}
+
+	for _, test := range tests {
+		c, err := NewClient(
+			WithResponsePreset(test.preset),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		err = c.resHandlerFunc()(&http.Response{StatusCode: test.status, Body: http.NoBody})
+		if (err == nil) != test.accepted {
+			t.Errorf("unexpected error of preset %d for status %d, %v", test.preset, test.status, err)
+		}
+	}
+}
+
+// Do method of a client with JSONAPIErrors preset should return problem details of unsuccessful responses, not retrying client errors.
+func TestJSONAPIErrorsPreset(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"type":"https://example.com/probs/out-of-credit","title":"Out of credit","detail":"balance is 30"}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithResponsePreset(JSONAPIErrors),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	var problem *ProblemError
+	if !errors.As(err, &problem) || !errors.Is(err, ErrUnsuccessfulStatusCode) || !IsPermanent(err) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if problem.Title != "Out of credit" || problem.Status != http.StatusUnprocessableEntity || problem.Detail != "balance is 30" || reqCount != 1 {
+		t.Errorf("unexpected problem or request count, %+v, %d", problem, reqCount)
+	}
+	if res != nil {
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if len(body) == 0 {
+			t.Errorf("body is not readable")
+		}
+	}
+}
+
+// Do method of a client with HeadOnly preset should replace bodies of accepted responses.
+func TestHeadOnlyPreset(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithResponsePreset(HeadOnly),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil || res.Body != http.NoBody {
+		t.Errorf("unexpected response, %v, %v", res, err)
+	}
+}
@@ -0,0 +1,35 @@
+package retryablehttp
+
+import (
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+)
+
+// WithProfilerLabels configures whether the goroutine executing each attempt is tagged with host, method and attempt pprof labels.
+// Labels are also set on attempt request's context, they are removed when the attempt finishes. Profiler labels are disabled by default.
+func WithProfilerLabels(enabled bool) Option {
+	return named("WithProfilerLabels", func(c *Client) error {
+		c.profilerLabels = enabled
+
+		return nil
+	})
+}
+
+// labelAttempt tags current goroutine and returns a copy of provided attempt request carrying pprof labels of call's current attempt.
+func (c *Client) labelAttempt(cl *call, req *http.Request) *http.Request {
+	labels := make([]string, 0, 6+2*len(cl.labels))
+	labels = append(labels, "host", req.URL.Host, "method", req.Method, "attempt", strconv.Itoa(cl.attempt))
+	for k, v := range cl.labels {
+		labels = append(labels, k, v)
+	}
+	ctx := pprof.WithLabels(req.Context(), pprof.Labels(labels...))
+	pprof.SetGoroutineLabels(ctx)
+
+	return req.WithContext(ctx)
+}
+
+// unlabelAttempt restores goroutine labels of call's context after an attempt.
+func (c *Client) unlabelAttempt(cl *call) {
+	pprof.SetGoroutineLabels(cl.ctx)
+}
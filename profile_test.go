@@ -0,0 +1,50 @@
+package retryablehttp
+
+import (
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// roundTripperFunc is an http.RoundTripper implemented by a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls the function.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Do method of a client with profiler labels should label every attempt with host, method and attempt number.
+func TestProfilerLabels(t *testing.T) {
+	var labels []string
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		host, _ := pprof.Label(req.Context(), "host")
+		method, _ := pprof.Label(req.Context(), "method")
+		attempt, _ := pprof.Label(req.Context(), "attempt")
+		labels = append(labels, strings.Join([]string{host, method, attempt}, " "))
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithMaxReqCount(2),
+		WithProfilerLabels(true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/path", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if len(labels) != 2 || labels[0] != "example.com POST 1" || labels[1] != "example.com POST 2" {
+		t.Errorf("unexpected labels, %v", labels)
+	}
+}
@@ -0,0 +1,108 @@
+// Package protobuf provides a protocol buffers codec for retryablehttp's typed request helpers.
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ermanimer/retryablehttp"
+	"google.golang.org/protobuf/proto"
+)
+
+// errors
+var (
+	ErrNotProtoMessage = errors.New("value is not a proto message")
+	ErrMessageTooLarge = errors.New("proto message is too large")
+	ErrInvalidMaxSize  = errors.New("maximum message size is not valid")
+)
+
+// content types
+const (
+	ContentType = "application/x-protobuf"
+)
+
+// alias content types used by some servers for protocol buffers
+var aliasContentTypes = []string{"application/protobuf", "application/vnd.google.protobuf"}
+
+// default options
+const (
+	defaultMaxSize = 4 << 20
+)
+
+// Codec encodes and decodes protocol buffers messages.
+type Codec struct {
+	maxSize int
+}
+
+// Option configures codec options.
+type Option func(c *Codec) error
+
+// WithMaxSize configures maximum size of encoded messages in bytes, both for marshaling and unmarshaling.
+// Default maximum size is 4 MiB.
+func WithMaxSize(maxSize int) Option {
+	return func(c *Codec) error {
+		if maxSize < 1 {
+			return ErrInvalidMaxSize
+		}
+
+		c.maxSize = maxSize
+
+		return nil
+	}
+}
+
+// NewCodec creates and returns new protocol buffers codec.
+func NewCodec(opts ...Option) (*Codec, error) {
+	c := &Codec{
+		maxSize: defaultMaxSize,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Register registers provided codec in provided registry for application/x-protobuf and its alias content types.
+func Register(r *retryablehttp.CodecRegistry, c *Codec) {
+	r.Register(c)
+	for _, contentType := range aliasContentTypes {
+		r.RegisterAs(contentType, c)
+	}
+}
+
+// ContentType returns application/x-protobuf.
+func (c *Codec) ContentType() string {
+	return ContentType
+}
+
+// Marshal encodes provided proto message.
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w, %T", ErrNotProtoMessage, v)
+	}
+
+	if size := proto.Size(m); size > c.maxSize {
+		return nil, fmt.Errorf("%w, %d bytes", ErrMessageTooLarge, size)
+	}
+
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes provided data into provided proto message.
+func (c *Codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w, %T", ErrNotProtoMessage, v)
+	}
+
+	if len(data) > c.maxSize {
+		return fmt.Errorf("%w, %d bytes", ErrMessageTooLarge, len(data))
+	}
+
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,96 @@
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ermanimer/retryablehttp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// NewCodec function should return ErrInvalidMaxSize when zero or negative maximum size is provided.
+func TestInvalidMaxSizeOption(t *testing.T) {
+	_, err := NewCodec(
+		WithMaxSize(0),
+	)
+	if err != ErrInvalidMaxSize {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Codec should reject values which are not proto messages and messages larger than maximum size.
+func TestCodecErrors(t *testing.T) {
+	c, err := NewCodec(
+		WithMaxSize(4),
+	)
+	if err != nil {
+		t.Errorf("creating codec failed, %s", err.Error())
+	}
+
+	if _, err := c.Marshal("gopher"); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := c.Marshal(wrapperspb.String("gopher")); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := c.Unmarshal(make([]byte, 5), &wrapperspb.StringValue{}); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// DoCodec method of a client with registered protobuf codec should encode request and decode response with alias content type.
+func TestDoCodec(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != ContentType {
+			t.Errorf("unexpected content type, %s", r.Header.Get("Content-Type"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body failed, %s", err.Error())
+		}
+
+		in := &wrapperspb.StringValue{}
+		if err := proto.Unmarshal(body, in); err != nil {
+			t.Errorf("decoding request body failed, %s", err.Error())
+		}
+
+		out, err := proto.Marshal(wrapperspb.String("hello " + in.GetValue()))
+		if err != nil {
+			t.Errorf("encoding response body failed, %s", err.Error())
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.google.protobuf")
+		w.Write(out)
+	}))
+	defer s.Close()
+
+	codec, err := NewCodec()
+	if err != nil {
+		t.Errorf("creating codec failed, %s", err.Error())
+	}
+
+	codecs := retryablehttp.NewCodecRegistry(retryablehttp.JSONCodec{})
+	Register(codecs, codec)
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithCodecRegistry(codecs),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	out := &wrapperspb.StringValue{}
+	err = c.DoCodec(context.Background(), http.MethodPost, s.URL, ContentType, wrapperspb.String("gopher"), out)
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+	}
+	if out.GetValue() != "hello gopher" {
+		t.Errorf("unexpected output, %s", out.GetValue())
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/ermanimer/retryablehttp/protobuf
+
+go 1.23
+
+require github.com/ermanimer/retryablehttp v0.0.0
+
+require google.golang.org/protobuf v1.36.9
+
+replace github.com/ermanimer/retryablehttp => ../
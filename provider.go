@@ -0,0 +1,124 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+)
+
+// config provider errors
+var (
+	ErrNilConfigProvider     = errors.New("config provider is nil")
+	ErrNilConfigErrorHandler = errors.New("config error handler is nil")
+)
+
+// ConfigProvider represents a source of configurations which can change at runtime, such as feature flags or config maps.
+// Watch returns a channel publishing new configurations, it may return nil when configuration never changes.
+type ConfigProvider interface {
+	Current() Config
+	Watch() <-chan Config
+}
+
+// WithConfigProvider configures client to take maximum request count, backoff, jitter, timeout and retryable status codes from provided provider.
+// Current configuration is applied when client is created, it must be valid. Configurations published later are swapped in atomically,
+// requests in flight keep the maximum request count and backoff they started with. Invalid published configurations are ignored and reported to
+// the handler configured with WithConfigErrorHandler. Http client and response handler configured before this option are used as bases for timeout
+// and for configurations without retryable status codes. Watching starts once client is constructed and stops when provider's channel is closed or
+// client is closed, clients derived by Clone do not watch.
+func WithConfigProvider(p ConfigProvider) Option {
+	return named("WithConfigProvider", func(c *Client) error {
+		if p == nil {
+			return ErrNilConfigProvider
+		}
+
+		cfg := p.Current()
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		base := settings{httpClient: c.httpClient, resHandler: c.resHandler}
+		for _, opt := range base.configOptions(cfg) {
+			if err := opt(c); err != nil {
+				return err
+			}
+		}
+
+		c.background(func(ctx context.Context) {
+			if ch := p.Watch(); ch != nil {
+				c.watchConfig(ctx, ch, base)
+			}
+		})
+
+		return nil
+	})
+}
+
+// WithConfigErrorHandler configures client to call provided handler with errors of invalid configurations published by its config provider.
+// Invalid configurations are ignored silently by default.
+func WithConfigErrorHandler(handler func(err error)) Option {
+	return named("WithConfigErrorHandler", func(c *Client) error {
+		if handler == nil {
+			return ErrNilConfigErrorHandler
+		}
+
+		c.configErrorHandler = handler
+
+		return nil
+	})
+}
+
+// watchConfig applies configurations received from provided channel until it is closed or provided context is done.
+func (c *Client) watchConfig(ctx context.Context, ch <-chan Config, base settings) {
+	c.mu.RLock()
+	handler := c.configErrorHandler
+	c.mu.RUnlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfg, ok := <-ch:
+			if !ok || ctx.Err() != nil {
+				return
+			}
+
+			if err := cfg.Validate(); err != nil {
+				if handler != nil {
+					c.hook(func() {
+						handler(err)
+					})
+				}
+				continue
+			}
+
+			c.set(base.configOptions(cfg)...)
+		}
+	}
+}
+
+// configOptions converts provided configuration to options replacing all provider controlled settings, unset values take defaults or base settings.
+func (base settings) configOptions(cfg Config) []Option {
+	maxReqCount := cfg.MaxReqCount
+	if maxReqCount == 0 {
+		maxReqCount = defaultMaxReqCount
+	}
+
+	resHandler := base.resHandler
+	if len(cfg.RetryableStatusCodes) > 0 {
+		resHandler = statusCodeHandler(cfg.RetryableStatusCodes)
+	}
+
+	httpClient := base.httpClient
+	if cfg.Timeout > 0 {
+		timeoutClient := *base.httpClient
+		timeoutClient.Timeout = cfg.Timeout
+		httpClient = &timeoutClient
+	}
+
+	return []Option{
+		WithMaxReqCount(maxReqCount),
+		WithBackoff(cfg.Backoff),
+		WithJitter(cfg.Jitter),
+		WithResHandler(resHandler),
+		WithHTTPClient(httpClient),
+	}
+}
@@ -0,0 +1,172 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// channelConfigProvider is a config provider publishing configurations sent to its channel.
+type channelConfigProvider struct {
+	current Config
+	ch      chan Config
+	watches int32
+}
+
+// Current returns initial configuration.
+func (p *channelConfigProvider) Current() Config {
+	return p.current
+}
+
+// Watch returns provider's channel, counting calls.
+func (p *channelConfigProvider) Watch() <-chan Config {
+	atomic.AddInt32(&p.watches, 1)
+
+	return p.ch
+}
+
+// NewClient function should return validation error of provider's current configuration.
+func TestInvalidConfigProviderOption(t *testing.T) {
+	_, err := NewClient(
+		WithConfigProvider(nil),
+	)
+	if !errors.Is(err, ErrNilConfigProvider) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	_, err = NewClient(
+		WithConfigProvider(&channelConfigProvider{current: Config{MaxReqCount: -1}}),
+	)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with config provider should use configurations published by the provider.
+func TestConfigProvider(t *testing.T) {
+	var mu sync.Mutex
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		reqCount++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	p := &channelConfigProvider{current: Config{MaxReqCount: 2}, ch: make(chan Config)}
+	defer close(p.ch)
+
+	var configErr error
+	c, err := NewClient(
+		WithConfigProvider(p),
+		WithConfigErrorHandler(func(err error) {
+			mu.Lock()
+			configErr = err
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	send := func() int {
+		mu.Lock()
+		reqCount = 0
+		mu.Unlock()
+
+		req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+		c.Do(req)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return reqCount
+	}
+
+	if n := send(); n != 2 {
+		t.Errorf("unexpected request count, %d", n)
+	}
+
+	p.ch <- Config{MaxReqCount: 4, Timeout: time.Second}
+	p.ch <- Config{MaxReqCount: -1}
+	p.ch <- Config{MaxReqCount: 5, RetryableStatusCodes: []int{http.StatusTooManyRequests}}
+	p.ch <- Config{MaxReqCount: 3}
+
+	// unbuffered sends above return when the watcher received the last configuration, wait until it is applied.
+	deadline := time.Now().Add(time.Second)
+	for {
+		maxReqCount, _, _ := c.retrySettings()
+		if maxReqCount == 3 && c.currentHTTPClient() == http.DefaultClient || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := send(); n != 3 {
+		t.Errorf("unexpected request count, %d", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !errors.Is(configErr, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected config error, %v", configErr)
+	}
+}
+
+// Config provider of a client should be watched only after the client is created successfully and until the client is closed.
+func TestConfigProviderLifecycle(t *testing.T) {
+	p := &channelConfigProvider{ch: make(chan Config)}
+
+	_, err := NewClient(
+		WithConfigProvider(p),
+		WithMaxReqCount(0),
+	)
+	if !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	_, err = NewClient(
+		WithHostPolicy("example.com", WithConfigProvider(p)),
+	)
+	if !errors.Is(err, ErrBackgroundPolicy) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if watches := atomic.LoadInt32(&p.watches); watches != 0 {
+		t.Errorf("unexpected watch count, %d", watches)
+	}
+
+	c, err := NewClient(
+		WithConfigProvider(p),
+	)
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	p.ch <- Config{MaxReqCount: 2}
+	deadline := time.Now().Add(time.Second)
+	for {
+		maxReqCount, _, _ := c.retrySettings()
+		if maxReqCount == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Close()
+
+	select {
+	case p.ch <- Config{MaxReqCount: 3}:
+	case <-time.After(50 * time.Millisecond):
+	}
+	time.Sleep(10 * time.Millisecond)
+	if maxReqCount, _, _ := c.retrySettings(); maxReqCount != 2 {
+		t.Errorf("closed client applied a configuration, maximum request count %d", maxReqCount)
+	}
+}
@@ -0,0 +1,154 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// quota pacing errors
+var (
+	ErrInvalidQuotaPacing = errors.New("quota pacing headers must not be empty")
+)
+
+// default quota pacing headers
+const (
+	defaultQuotaRemainingHeader = "X-RateLimit-Remaining"
+	defaultQuotaResetHeader     = "X-RateLimit-Reset"
+)
+
+// unixResetThreshold is the smallest reset header value treated as a Unix timestamp instead of a number of seconds.
+const unixResetThreshold = 1e9
+
+// QuotaPacing represents settings of quota pacing, zero values take default values.
+// RemainingHeader (X-RateLimit-Remaining) holds the number of requests remaining in the current quota window and ResetHeader (X-RateLimit-Reset)
+// holds the seconds until the window resets, or the Unix time of the reset when it is at least 1e9. Quotas are tracked per Key of requests,
+// which is request's host by default.
+type QuotaPacing struct {
+	RemainingHeader string
+	ResetHeader     string
+	Key             func(req *http.Request) string
+}
+
+// WithQuotaPacing configures client to track remaining quota of hosts, or of provided keys, from rate limit headers of responses and to delay attempts,
+// first attempts included, so that remaining requests are spread evenly until the quota window resets instead of bursting into 429 responses.
+// Attempts wait until the reset when no quota remains. Waits stop when request's context is done. Attempts are not paced by default.
+func WithQuotaPacing(cfg QuotaPacing) Option {
+	return named("WithQuotaPacing", func(c *Client) error {
+		if cfg.RemainingHeader == "" {
+			cfg.RemainingHeader = defaultQuotaRemainingHeader
+		}
+		if cfg.ResetHeader == "" {
+			cfg.ResetHeader = defaultQuotaResetHeader
+		}
+		if strings.TrimSpace(cfg.RemainingHeader) == "" || strings.TrimSpace(cfg.ResetHeader) == "" {
+			return ErrInvalidQuotaPacing
+		}
+
+		c.quota = &quotaPacer{
+			cfg:    cfg,
+			quotas: make(map[string]*quota),
+		}
+
+		return nil
+	})
+}
+
+// quotaPacer holds quotas of keys.
+type quotaPacer struct {
+	cfg QuotaPacing
+
+	mu     sync.Mutex
+	quotas map[string]*quota
+}
+
+// quota holds the remaining quota of a key and the time its next attempt is scheduled at.
+type quota struct {
+	remaining int
+	reset     time.Time
+	next      time.Time
+}
+
+// key returns quota key of provided request.
+func (p *quotaPacer) key(req *http.Request) string {
+	if p.cfg.Key != nil {
+		return p.cfg.Key(req)
+	}
+
+	return req.URL.Host
+}
+
+// paceQuota waits until quota of provided request allows another attempt, it returns context's error when request's context is done while waiting.
+func (c *Client) paceQuota(req *http.Request) error {
+	p := c.quota
+	key := p.key(req)
+	now := c.clock.Now()
+
+	p.mu.Lock()
+	q, ok := p.quotas[key]
+	if !ok || !now.Before(q.reset) {
+		if ok {
+			delete(p.quotas, key)
+		}
+		p.mu.Unlock()
+		return nil
+	}
+
+	start := now
+	if q.next.After(start) {
+		start = q.next
+	}
+	if q.remaining <= 0 {
+		start = q.reset
+		q.next = q.reset
+	} else {
+		q.next = start.Add(q.reset.Sub(start) / time.Duration(q.remaining+1))
+		q.remaining--
+	}
+	p.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		c.sleep(req.Context(), wait)
+	}
+
+	return req.Context().Err()
+}
+
+// observeQuota updates quota of provided request from rate limit headers of provided response.
+func (c *Client) observeQuota(req *http.Request, res *http.Response) {
+	if res == nil {
+		return
+	}
+
+	p := c.quota
+	remaining, err := strconv.Atoi(strings.TrimSpace(res.Header.Get(p.cfg.RemainingHeader)))
+	if err != nil || remaining < 0 {
+		return
+	}
+	reset, err := strconv.ParseFloat(strings.TrimSpace(res.Header.Get(p.cfg.ResetHeader)), 64)
+	if err != nil || reset < 0 {
+		return
+	}
+
+	now := c.clock.Now()
+	resetAt := now.Add(time.Duration(reset * float64(time.Second)))
+	if reset >= unixResetThreshold {
+		resetAt = time.Unix(0, int64(reset*float64(time.Second)))
+	}
+
+	key := p.key(req)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	q, ok := p.quotas[key]
+	if !ok {
+		q = &quota{}
+		p.quotas[key] = q
+	}
+	q.remaining = remaining
+	q.reset = resetAt
+}
@@ -0,0 +1,63 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidQuotaPacing when blank quota headers are provided.
+func TestInvalidQuotaPacingOption(t *testing.T) {
+	if _, err := NewClient(WithQuotaPacing(QuotaPacing{RemainingHeader: " "})); !errors.Is(err, ErrInvalidQuotaPacing) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with quota pacing should spread attempts across the quota window and wait for the reset when no quota remains.
+func TestQuotaPacing(t *testing.T) {
+	clock := &fakeClock{}
+	var sent []time.Duration
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		elapsed := clock.Now().Sub(time.Time{})
+		sent = append(sent, elapsed)
+
+		remaining := 4 - len(sent)
+		if remaining < 0 {
+			remaining = 0
+		}
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int((4*time.Second-elapsed)/time.Second)))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithClock(clock),
+		WithSleeper(clock),
+		WithQuotaPacing(QuotaPacing{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 5; i++ {
+		res, err := c.Get(s.URL)
+		if err != nil {
+			t.Errorf("request %d failed, %v", i, err)
+		}
+		discard(res)
+	}
+
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	if len(sent) != len(expected) {
+		t.Fatalf("unexpected request count, %d", len(sent))
+	}
+	for i := range expected {
+		if sent[i] != expected[i] {
+			t.Errorf("unexpected send times, %v", sent)
+			break
+		}
+	}
+}
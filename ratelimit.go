@@ -0,0 +1,76 @@
+package retryablehttp
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// retry rate limit errors
+var (
+	ErrInvalidRetryRate = errors.New("retry rate and burst must be greater than zero")
+)
+
+// WithRetryRateLimit configures client to limit retries, not first attempts, to provided rate per second with provided burst,
+// so that retry traffic of a process is capped during an outage of a dependency. Requests give up when no retry is available.
+// The limit is shared by clones and policy variants of client, and a limit configured in a policy is shared by requests matching the policy.
+// Retries and limited retries are counted in Stats. Retries are not limited by default.
+func WithRetryRateLimit(rate float64, burst int) Option {
+	return named("WithRetryRateLimit", func(c *Client) error {
+		if rate <= 0 || burst <= 0 {
+			return ErrInvalidRetryRate
+		}
+
+		c.retryLimiter = &tokenBucket{
+			rate:   rate,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+
+		return nil
+	})
+}
+
+// tokenBucket is a token bucket rate limiter.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	started bool
+}
+
+// allow takes a token at provided time, it reports whether a token was available.
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.started {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.started = true
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// allowRetry reports whether client's retry rate limit allows another retry, counting retries and limited retries.
+func (c *Client) allowRetry() bool {
+	if c.retryLimiter != nil && !c.retryLimiter.allow(c.clock.Now()) {
+		c.stats.limitedRetries.add(1)
+		return false
+	}
+	c.stats.retries.add(1)
+
+	return true
+}
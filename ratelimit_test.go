@@ -0,0 +1,63 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidRetryRate when non-positive retry rate or burst is provided.
+func TestInvalidRetryRateLimitOptions(t *testing.T) {
+	for _, opt := range []Option{WithRetryRateLimit(0, 1), WithRetryRateLimit(1, 0)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidRetryRate) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Do method of a client with retry rate limit should give up when retries exceed the rate and allow them again after tokens refill.
+func TestRetryRateLimit(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithRetryRateLimit(1, 3),
+		WithClock(clock),
+		WithSleeper(clock),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		c.Do(req)
+	}
+	if reqCount != 6 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+
+	clock.Sleep(2 * time.Second)
+	c.Do(req)
+	if reqCount != 9 {
+		t.Errorf("unexpected request count after refill, %d", reqCount)
+	}
+
+	stats := c.Stats()
+	if stats.Retries != 5 || stats.LimitedRetries != 2 {
+		t.Errorf("unexpected retry counts, %d, %d", stats.Retries, stats.LimitedRetries)
+	}
+}
@@ -0,0 +1,151 @@
+package retryablehttp
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// redactedValue replaces values of sensitive headers and query parameters.
+const redactedValue = "REDACTED"
+
+// RedactionPolicy represents sensitive headers and query parameters whose values are redacted in debug dumps, curl commands and error messages.
+// Header names are case insensitive, query parameter names are case sensitive.
+// Redact is optional, when set it is called for every header and query parameter value after list based redaction, and its result is used.
+type RedactionPolicy struct {
+	Headers     []string
+	QueryParams []string
+	Redact      func(name, value string) string
+}
+
+// DefaultRedactionPolicy is the redaction policy used by clients unless configured otherwise.
+var DefaultRedactionPolicy = RedactionPolicy{
+	Headers:     []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+	QueryParams: []string{"api_key", "apikey", "access_token"},
+}
+
+// WithRedactionPolicy configures client's redaction policy.
+// Default redaction policy is DefaultRedactionPolicy.
+func WithRedactionPolicy(policy RedactionPolicy) Option {
+	return named("WithRedactionPolicy", func(c *Client) error {
+		c.redactor = newRedactor(policy)
+
+		return nil
+	})
+}
+
+// redactor applies a redaction policy.
+type redactor struct {
+	headers map[string]bool
+	params  map[string]bool
+	redact  func(name, value string) string
+}
+
+// defaultRedactor applies DefaultRedactionPolicy.
+var defaultRedactor = newRedactor(DefaultRedactionPolicy)
+
+// newRedactor creates and returns new redactor applying provided policy.
+func newRedactor(policy RedactionPolicy) *redactor {
+	r := &redactor{
+		headers: make(map[string]bool, len(policy.Headers)),
+		params:  make(map[string]bool, len(policy.QueryParams)),
+		redact:  policy.Redact,
+	}
+
+	for _, name := range policy.Headers {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range policy.QueryParams {
+		r.params[name] = true
+	}
+
+	return r
+}
+
+// value returns redacted value of a header or query parameter.
+func (r *redactor) value(name, value string, sensitive bool) string {
+	if sensitive {
+		value = redactedValue
+	}
+
+	if r.redact != nil {
+		value = r.redact(name, value)
+	}
+
+	return value
+}
+
+// header returns a redacted copy of provided header.
+func (r *redactor) header(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		sensitive := r.headers[http.CanonicalHeaderKey(name)]
+		redactedValues := make([]string, len(values))
+		for i, value := range values {
+			redactedValues[i] = r.value(name, value, sensitive)
+		}
+		redacted[name] = redactedValues
+	}
+
+	return redacted
+}
+
+// url returns a redacted copy of provided url.
+func (r *redactor) url(u *url.URL) *url.URL {
+	redacted := *u
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			redacted.User = url.UserPassword(u.User.Username(), redactedValue)
+		}
+	}
+
+	if u.RawQuery == "" {
+		return &redacted
+	}
+
+	query := u.Query()
+	for name, values := range query {
+		for i, value := range values {
+			values[i] = r.value(name, value, r.params[name])
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	return &redacted
+}
+
+// urlString returns redacted form of provided url string, it returns provided string when it cannot be parsed.
+func (r *redactor) urlString(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return r.url(u).String()
+}
+
+// request returns a shallow copy of provided request with redacted header and url.
+func (r *redactor) request(req *http.Request) *http.Request {
+	redacted := req.Clone(req.Context())
+	redacted.Header = r.header(req.Header)
+	redacted.URL = r.url(req.URL)
+
+	return redacted
+}
+
+// response returns a shallow copy of provided response with redacted header.
+func (r *redactor) response(res *http.Response) *http.Response {
+	redacted := *res
+	redacted.Header = r.header(res.Header)
+
+	return &redacted
+}
+
+// error returns provided error with redacted url when it is a *url.Error.
+func (r *redactor) error(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	return &url.Error{Op: urlErr.Op, URL: r.urlString(urlErr.URL), Err: urlErr.Err}
+}
@@ -0,0 +1,78 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Do method should redact sensitive query parameters in error messages.
+func TestRedactErrorURL(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"?api_key=secret&page=1", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, err = c.Do(req)
+	if err == nil {
+		t.Fatal("unexpected nil error")
+	}
+	if strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "page=1") {
+		t.Errorf("unexpected error, %s", err)
+	}
+}
+
+// Do method of a client with custom redaction policy should redact configured headers and apply custom redactor in debug dumps.
+func TestRedactionPolicy(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	var buf bytes.Buffer
+	c, err := NewClient(
+		WithDebugDump(&buf, false),
+		WithRedactionPolicy(RedactionPolicy{
+			Headers: []string{"x-tenant-token", "set-cookie"},
+			Redact: func(name, value string) string {
+				if name == "email" {
+					return "***"
+				}
+
+				return value
+			},
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"?email=gopher@example.com", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req.Header.Set("X-Tenant-Token", "secret")
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("doing http request failed, %s", err.Error())
+	}
+
+	dump := buf.String()
+	if strings.Contains(dump, "secret") || strings.Contains(dump, "gopher@example.com") {
+		t.Errorf("dump is not redacted, %s", dump)
+	}
+	if !strings.Contains(dump, "X-Tenant-Token: REDACTED") {
+		t.Errorf("unexpected dump, %s", dump)
+	}
+}
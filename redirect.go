@@ -0,0 +1,174 @@
+package retryablehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// redirect errors
+var (
+	ErrInvalidRedirectPolicy = errors.New("redirect policy is not valid")
+	ErrNilRedirectAuth       = errors.New("redirect auth function is nil")
+	ErrTooManyRedirects      = errors.New("too many redirects")
+)
+
+// defaultMaxRedirects is the maximum number of redirects followed in the retry loop when it is not configured, it is the same as http.Client.
+const defaultMaxRedirects = 10
+
+// RedirectPolicy represents how redirect responses are handled.
+type RedirectPolicy int
+
+// redirect policies
+const (
+	// RedirectFollow lets the http client follow redirects within a single attempt, it is the default.
+	RedirectFollow RedirectPolicy = iota
+	// RedirectAccept returns 3xx responses as successful responses without following them.
+	RedirectAccept
+	// RedirectInLoop follows redirects in the retry loop, every hop is an attempt of its own which does not count toward maximum request count.
+	// Failed attempts are retried from the latest hop instead of the original url.
+	RedirectInLoop
+)
+
+// WithRedirectPolicy configures how client handles redirect responses. Provided number of hops limits redirects followed with RedirectInLoop,
+// zero means 10 hops, a request exceeding the limit fails with ErrTooManyRedirects. Redirects are followed by the http client by default.
+func WithRedirectPolicy(policy RedirectPolicy, maxHops int) Option {
+	return named("WithRedirectPolicy", func(c *Client) error {
+		if (policy != RedirectFollow && policy != RedirectAccept && policy != RedirectInLoop) || maxHops < 0 {
+			return ErrInvalidRedirectPolicy
+		}
+		if maxHops == 0 {
+			maxHops = defaultMaxRedirects
+		}
+
+		c.redirectPolicy = policy
+		c.maxRedirects = maxHops
+
+		return nil
+	})
+}
+
+// WithRedirectAuth configures a function which re-applies authentication or signing to requests redirected to a host other than the original one.
+// Authorization and Cookie headers are removed from such requests, like http.Client does, before provided function is called.
+// It applies both to redirects followed by the http client and to redirects followed in the retry loop.
+func WithRedirectAuth(auth func(req *http.Request) error) Option {
+	return named("WithRedirectAuth", func(c *Client) error {
+		if auth == nil {
+			return ErrNilRedirectAuth
+		}
+
+		c.redirectAuth = auth
+
+		return nil
+	})
+}
+
+// redirectClient returns a copy of provided http client which stops at redirect responses or re-applies authentication on cross-host redirects.
+func (c *Client) redirectClient(httpClient *http.Client) *http.Client {
+	copied := *httpClient
+	if c.redirectPolicy != RedirectFollow {
+		copied.CheckRedirect = stopRedirect
+		return &copied
+	}
+
+	check := httpClient.CheckRedirect
+	copied.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if check != nil {
+			if err := check(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= defaultMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", defaultMaxRedirects)
+		}
+
+		if !sameHost(req, via[0]) {
+			return c.reauthorize(req)
+		}
+
+		return nil
+	}
+
+	return &copied
+}
+
+// stopRedirect is a redirect check which makes the http client return redirect responses.
+func stopRedirect(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// isRedirect reports whether provided response is a redirect which can be followed.
+func isRedirect(res *http.Response) bool {
+	switch res.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return res.Header.Get("Location") != ""
+	}
+
+	return false
+}
+
+// redirect returns the request of the next hop of provided redirect response, it returns nil when response is not a redirect which can be followed.
+// Hops are counted in call state, the body of a followed response is discarded.
+func (c *Client) redirect(cl *call, orig, req *http.Request, res *http.Response) (*http.Request, error) {
+	if !isRedirect(res) {
+		return nil, nil
+	}
+
+	preserveBody := res.StatusCode == http.StatusTemporaryRedirect || res.StatusCode == http.StatusPermanentRedirect
+	if preserveBody && req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil, nil
+	}
+
+	if cl.hops >= c.maxRedirects {
+		return nil, ErrTooManyRedirects
+	}
+
+	u, err := req.URL.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+
+	next := req.WithContext(req.Context())
+	next.URL = u
+	next.Host = ""
+	next.Header = req.Header.Clone()
+	if !preserveBody && (res.StatusCode == http.StatusSeeOther || req.Method == http.MethodPost) {
+		if req.Method != http.MethodHead {
+			next.Method = http.MethodGet
+		}
+		next.Body = nil
+		next.GetBody = nil
+		next.ContentLength = 0
+		next.Header.Del("Content-Type")
+		next.Header.Del("Content-Length")
+	}
+
+	if !sameHost(next, orig) {
+		if err := c.reauthorize(next); err != nil {
+			return nil, err
+		}
+	}
+
+	discard(res)
+	cl.hops++
+	cl.redirected = true
+
+	return next, nil
+}
+
+// reauthorize removes credentials from provided cross-host redirect request and re-applies them with client's redirect auth function when it is set.
+func (c *Client) reauthorize(req *http.Request) error {
+	req.Header.Del("Authorization")
+	req.Header.Del("Www-Authenticate")
+	req.Header.Del("Cookie")
+	if c.redirectAuth == nil {
+		return nil
+	}
+
+	return c.redirectAuth(req)
+}
+
+// sameHost reports whether provided requests are sent to the same host.
+func sameHost(a, b *http.Request) bool {
+	return strings.EqualFold(a.URL.Host, b.URL.Host)
+}
@@ -0,0 +1,169 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// NewClient function should return ErrInvalidRedirectPolicy and ErrNilRedirectAuth when invalid redirect options are provided.
+func TestInvalidRedirectOptions(t *testing.T) {
+	for _, opt := range []Option{WithRedirectPolicy(RedirectPolicy(-1), 0), WithRedirectPolicy(RedirectInLoop, -1)} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidRedirectPolicy) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+	if _, err := NewClient(WithRedirectAuth(nil)); !errors.Is(err, ErrNilRedirectAuth) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client accepting redirects should return redirect responses as successful responses without following them.
+func TestRedirectAccept(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			t.Errorf("redirect is followed, %s", r.URL.Path)
+		}
+		http.Redirect(w, r, "/moved", http.StatusFound)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithRedirectPolicy(RedirectAccept, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusFound || res.Header.Get("Location") != "/moved" {
+		t.Errorf("unexpected response, %d", res.StatusCode)
+	}
+}
+
+// Do method of a client following redirects in the retry loop should account hops separately and retry failures from the latest hop.
+func TestRedirectInLoop(t *testing.T) {
+	var movedCount int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+		case "/moved":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPut || string(body) != "body" {
+				t.Errorf("unexpected redirected request, %s, %q", r.Method, body)
+			}
+			if atomic.AddInt32(&movedCount, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		default:
+			t.Errorf("unexpected path, %s", r.URL.Path)
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(0),
+		WithRedirectPolicy(RedirectInLoop, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.URL, strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+	}
+
+	res, report, err := c.DoWithReport(req)
+	if err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+		return
+	}
+	res.Body.Close()
+
+	var outcomes []Outcome
+	for _, attempt := range report.Attempts {
+		outcomes = append(outcomes, attempt.Outcome)
+	}
+	if len(outcomes) != 3 || outcomes[0] != OutcomeRedirect || outcomes[1] != OutcomeRetry || outcomes[2] != OutcomeSuccess {
+		t.Errorf("unexpected outcomes, %v", outcomes)
+	}
+}
+
+// Do method of a client following redirects in the retry loop should stop with ErrTooManyRedirects after maximum number of hops.
+func TestTooManyRedirects(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithRedirectPolicy(RedirectInLoop, 3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if res != nil {
+		res.Body.Close()
+	}
+}
+
+// Do method of a client with redirect auth should remove credentials from cross-host redirects and re-apply them with provided function.
+func TestRedirectAuth(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer target" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer target.Close()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer origin.Close()
+
+	for _, policy := range []RedirectPolicy{RedirectFollow, RedirectInLoop} {
+		c, err := NewClient(
+			WithRedirectPolicy(policy, 0),
+			WithRedirectAuth(func(req *http.Request) error {
+				if req.Header.Get("Authorization") != "" {
+					t.Errorf("credentials are not removed")
+				}
+				req.Header.Set("Authorization", "Bearer target")
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		req, err := http.NewRequest(http.MethodGet, origin.URL, nil)
+		if err != nil {
+			t.Errorf("creating request failed, %s", err.Error())
+		}
+		req.Header.Set("Authorization", "Bearer origin")
+
+		res, err := c.Do(req)
+		if err != nil {
+			t.Errorf("sending request failed, %s", err.Error())
+			continue
+		}
+		res.Body.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package retryablehttp
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// registry errors
+var (
+	ErrEmptyClientName = errors.New("client name is empty")
+	ErrNilClient       = errors.New("client is nil")
+)
+
+// Registry represents a set of clients keyed by name, it is safe for concurrent use.
+// It lets large codebases manage differently tuned clients centrally.
+type Registry struct {
+	mu       sync.RWMutex
+	clients  map[string]*Client
+	fallback *Client
+}
+
+// NewRegistry creates and returns new registry which returns provided fallback client for unknown names.
+// When fallback is nil, package level default client is returned for unknown names.
+func NewRegistry(fallback *Client) *Registry {
+	return &Registry{
+		clients:  make(map[string]*Client),
+		fallback: fallback,
+	}
+}
+
+// Register registers provided client with provided name, replacing any client previously registered with the same name.
+func (r *Registry) Register(name string, c *Client) error {
+	if name == "" {
+		return ErrEmptyClientName
+	}
+	if c == nil {
+		return ErrNilClient
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[name] = c
+
+	return nil
+}
+
+// Create creates a client with provided options and registers it with provided name.
+func (r *Registry) Create(name string, opts ...Option) (*Client, error) {
+	if name == "" {
+		return nil, ErrEmptyClientName
+	}
+
+	c, err := NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, r.Register(name, c)
+}
+
+// CreateFromConfig creates a client from provided configuration and options and registers it with provided name.
+func (r *Registry) CreateFromConfig(name string, cfg Config, opts ...Option) (*Client, error) {
+	if name == "" {
+		return nil, ErrEmptyClientName
+	}
+
+	c, err := NewClientFromConfig(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, r.Register(name, c)
+}
+
+// Lookup returns client registered with provided name.
+func (r *Registry) Lookup(name string) (*Client, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.clients[name]
+
+	return c, ok
+}
+
+// Get returns client registered with provided name, or the fallback client when no client is registered with the name.
+func (r *Registry) Get(name string) *Client {
+	if c, ok := r.Lookup(name); ok {
+		return c
+	}
+
+	if r.fallback != nil {
+		return r.fallback
+	}
+
+	return Default()
+}
+
+// Names returns sorted names of registered clients.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Range calls provided function for each registered client in name order, it is useful for collecting metrics of all clients.
+func (r *Registry) Range(fn func(name string, c *Client)) {
+	for _, name := range r.Names() {
+		if c, ok := r.Lookup(name); ok {
+			fn(name, c)
+		}
+	}
+}
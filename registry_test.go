@@ -0,0 +1,54 @@
+package retryablehttp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Registry should register, create and return clients by name with a fallback for unknown names.
+func TestRegistry(t *testing.T) {
+	fallback, err := NewClient()
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	r := NewRegistry(fallback)
+
+	if err := r.Register("", fallback); err != ErrEmptyClientName {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := r.Register("search", nil); err != ErrNilClient {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if _, err := r.Create("payments", WithMaxReqCount(0)); !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	payments, err := r.Create("payments", WithMaxReqCount(5))
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+	search, err := r.CreateFromConfig("search", Config{MaxReqCount: 2})
+	if err != nil {
+		t.Fatalf("creating client failed, %s", err.Error())
+	}
+
+	if r.Get("payments") != payments || r.Get("search") != search || r.Get("unknown") != fallback {
+		t.Error("unexpected client")
+	}
+	if _, ok := r.Lookup("unknown"); ok {
+		t.Error("unexpected client for unknown name")
+	}
+	if NewRegistry(nil).Get("unknown") != Default() {
+		t.Error("unexpected fallback client")
+	}
+
+	var names []string
+	r.Range(func(name string, c *Client) {
+		names = append(names, name)
+	})
+	if !reflect.DeepEqual(names, []string{"payments", "search"}) || !reflect.DeepEqual(r.Names(), names) {
+		t.Errorf("unexpected names, %v", names)
+	}
+}
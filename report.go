@@ -0,0 +1,215 @@
+package retryablehttp
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Report represents a report of a request sent with automatic retries.
+// TotalBackoff is the sum of backoff durations and Elapsed is the duration of the whole request excluding reading final response's body.
+// Policy describes the host or path policy applied to the request, such as "path /v1/charges/*", it is empty when no policy matches.
+type Report struct {
+	Attempts     []*AttemptReport
+	TotalBackoff time.Duration
+	Elapsed      time.Duration
+	Policy       string
+
+	pendingBackoff time.Duration
+}
+
+// AttemptReport represents a report of a single attempt.
+// RequestID is set only when request ids are enabled. StatusCode is zero when no response is received, Err is the error returned by the attempt before retrying.
+// Region is the region of the endpoint the attempt is balanced to, it is empty when the attempt is not balanced or the endpoint has no region.
+type AttemptReport struct {
+	Attempt    int
+	RequestID  string
+	StatusCode int
+	Err        error
+	Latency    time.Duration
+	Outcome    Outcome
+	Region     string
+	Timing     TimingReport
+}
+
+// TimingReport represents timing breakdown of a single attempt.
+// DNS, Connect and TLS are zero when a pooled connection is reused. TTFB is measured from the beginning of the attempt.
+// BodyRead is measured from the first response byte until response body is fully read or closed, including reads of the caller.
+// Backoff is the waiting duration before the attempt.
+type TimingReport struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	BodyRead time.Duration
+	Backoff  time.Duration
+}
+
+// DoWithReport sends http request with automatic retries like Do and returns a report of all attempts.
+func (c *Client) DoWithReport(req *http.Request) (*http.Response, *Report, error) {
+	c.init()
+
+	report := &Report{}
+	start := c.clock.Now()
+	res, err := c.do(req, report)
+	report.Elapsed = c.since(start)
+
+	return res, report, err
+}
+
+// wait records provided backoff duration for the next attempt.
+func (r *Report) wait(backoff time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.pendingBackoff += backoff
+	r.TotalBackoff += backoff
+}
+
+// begin adds and returns a new attempt report with provided attempt number, it returns nil when report is nil.
+func (r *Report) begin(attemptNumber int) *AttemptReport {
+	if r == nil {
+		return nil
+	}
+
+	attempt := &AttemptReport{
+		Attempt: attemptNumber,
+		Timing:  TimingReport{Backoff: r.pendingBackoff},
+	}
+	r.pendingBackoff = 0
+	r.Attempts = append(r.Attempts, attempt)
+
+	return attempt
+}
+
+// finish records result of call's current attempt into provided attempt report.
+func (a *AttemptReport) finish(cl *call, err error, outcome Outcome) {
+	if a == nil {
+		return
+	}
+
+	a.Err = err
+	a.Outcome = outcome
+	if cl.req != nil && len(cl.requestIDs) > 0 {
+		a.RequestID = cl.requestIDs[len(cl.requestIDs)-1]
+	}
+	a.Latency = cl.latency
+	if cl.endpoint != nil {
+		a.Region = cl.endpoint.region
+	}
+	if cl.res != nil {
+		a.StatusCode = cl.res.StatusCode
+	}
+}
+
+// timingRecorder records timing of an attempt from http trace events, trace events may be received from multiple goroutines.
+type timingRecorder struct {
+	mu      sync.Mutex
+	attempt *AttemptReport
+	clock   Clock
+
+	start          time.Time
+	dnsStart       time.Time
+	connectStart   time.Time
+	tlsStart       time.Time
+	firstByte      time.Time
+	bodyReadRecord sync.Once
+}
+
+// trace returns a shallow copy of provided request with a client trace recording timing into provided attempt report using provided clock.
+func (a *AttemptReport) trace(req *http.Request, clock Clock) (*http.Request, *timingRecorder) {
+	r := &timingRecorder{
+		attempt: a,
+		clock:   clock,
+		start:   clock.Now(),
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.mu.Lock()
+			r.dnsStart = r.clock.Now()
+			r.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.mu.Lock()
+			r.attempt.Timing.DNS = r.clock.Now().Sub(r.dnsStart)
+			r.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			r.mu.Lock()
+			r.connectStart = r.clock.Now()
+			r.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			r.mu.Lock()
+			r.attempt.Timing.Connect = r.clock.Now().Sub(r.connectStart)
+			r.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			r.mu.Lock()
+			r.tlsStart = r.clock.Now()
+			r.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.mu.Lock()
+			r.attempt.Timing.TLS = r.clock.Now().Sub(r.tlsStart)
+			r.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			r.mu.Lock()
+			r.firstByte = r.clock.Now()
+			r.attempt.Timing.TTFB = r.firstByte.Sub(r.start)
+			r.mu.Unlock()
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), r
+}
+
+// wrapBody wraps provided response's body to record body read duration when body is fully read or closed.
+func (r *timingRecorder) wrapBody(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	res.Body = &timedBody{ReadCloser: res.Body, recorder: r}
+}
+
+// recordBodyRead records body read duration once.
+func (r *timingRecorder) recordBodyRead() {
+	r.bodyReadRecord.Do(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if !r.firstByte.IsZero() {
+			r.attempt.Timing.BodyRead = r.clock.Now().Sub(r.firstByte)
+		}
+	})
+}
+
+// timedBody is a response body which records body read duration.
+type timedBody struct {
+	io.ReadCloser
+	recorder *timingRecorder
+}
+
+// Read reads from underlying body and records body read duration at the end of body.
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.recorder.recordBodyRead()
+	}
+
+	return n, err
+}
+
+// Close records body read duration and closes underlying body.
+func (b *timedBody) Close() error {
+	b.recorder.recordBodyRead()
+
+	return b.ReadCloser.Close()
+}
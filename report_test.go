@@ -0,0 +1,106 @@
+package retryablehttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// DoWithReport method should report timing of every attempt including backoff waits.
+func TestDoWithReportTiming(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	backoff := 10 * time.Millisecond
+	c, err := NewClient(
+		WithHTTPClient(s.Client()),
+		WithMaxReqCount(2),
+		WithBackoff(backoff),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, report, err := c.DoWithReport(req)
+	if err != nil {
+		t.Fatalf("doing http request failed, %s", err.Error())
+	}
+	io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if len(report.Attempts) != 2 {
+		t.Fatalf("unexpected attempt count, %d", len(report.Attempts))
+	}
+
+	first := report.Attempts[0].Timing
+	if first.Connect <= 0 || first.TLS <= 0 || first.TTFB <= 0 || first.Backoff != 0 {
+		t.Errorf("unexpected timing of first attempt, %+v", first)
+	}
+
+	second := report.Attempts[1].Timing
+	if second.TTFB <= 0 || second.BodyRead <= 0 || second.Backoff != backoff {
+		t.Errorf("unexpected timing of second attempt, %+v", second)
+	}
+}
+
+// DoWithReport method should report status, error, outcome and latency of every attempt and totals.
+func TestDoWithReportHistory(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	backoff := 10 * time.Millisecond
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(backoff),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, report, err := c.DoWithReport(req)
+	if err == nil {
+		t.Error("unexpected nil error")
+	}
+
+	if len(report.Attempts) != 3 {
+		t.Fatalf("unexpected attempt count, %d", len(report.Attempts))
+	}
+	for i, attempt := range report.Attempts {
+		expectedOutcome := OutcomeRetry
+		if i == 2 {
+			expectedOutcome = OutcomeFailure
+		}
+
+		if attempt.Attempt != i+1 || attempt.StatusCode != http.StatusServiceUnavailable || attempt.Err != ErrUnsuccessfulStatusCode || attempt.Outcome != expectedOutcome || attempt.Latency <= 0 {
+			t.Errorf("unexpected attempt report, %+v", attempt)
+		}
+	}
+
+	if report.TotalBackoff != 2*backoff || report.Elapsed < report.TotalBackoff {
+		t.Errorf("unexpected totals, %s, %s", report.TotalBackoff, report.Elapsed)
+	}
+}
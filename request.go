@@ -0,0 +1,318 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// request errors
+var (
+	ErrUnsupportedBody = errors.New("request body type is not supported")
+)
+
+// BodyFunc represents a function which produces a fresh copy of a request body for every attempt.
+type BodyFunc func() (io.ReadCloser, error)
+
+// Request represents a rewindable http request whose body is produced again for every attempt.
+// It embeds *http.Request with GetBody set, so it can be sent by Do method of a client.
+type Request struct {
+	*http.Request
+	body BodyFunc
+}
+
+// NewRequest creates and returns a new rewindable request with background context, see NewRequestWithContext for supported bodies.
+func NewRequest(method, url string, body interface{}) (*Request, error) {
+	return NewRequestWithContext(context.Background(), method, url, body)
+}
+
+// NewRequestWithContext creates and returns a new rewindable request with provided context.
+// Body can be nil, []byte, string, *bytes.Buffer, io.ReadSeeker, BodyFunc or func() (io.ReadCloser, error).
+// Unread content of a *bytes.Buffer is sent without consuming it, an io.ReadSeeker is rewound to its current position for every attempt.
+func NewRequestWithContext(ctx context.Context, method, url string, body interface{}) (*Request, error) {
+	bodyFunc, contentLength, err := newBodyFunc(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &Request{Request: httpReq}
+	if err := req.setBody(bodyFunc, contentLength); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// FromRequest converts provided request to a rewindable request sharing its fields. Requests without a body and requests with GetBody
+// are converted without reading their body, bodies of other requests are buffered in memory and closed.
+func FromRequest(httpReq *http.Request) (*Request, error) {
+	req := &Request{Request: httpReq.WithContext(httpReq.Context())}
+
+	switch {
+	case httpReq.Body == nil || httpReq.Body == http.NoBody:
+		return req, req.setBody(nil, 0)
+	case httpReq.GetBody != nil:
+		req.body = httpReq.GetBody
+		return req, nil
+	}
+
+	data, err := readAll(httpReq.Body, httpReq.ContentLength)
+	httpReq.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return req, req.setBody(bytesBody(data), int64(len(data)))
+}
+
+// WithContext returns a shallow copy of request with provided context.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	return &Request{
+		Request: r.Request.WithContext(ctx),
+		body:    r.body,
+	}
+}
+
+// BodyBytes returns a copy of request body, it returns nil when request has no body.
+func (r *Request) BodyBytes() ([]byte, error) {
+	if r.body == nil {
+		return nil, nil
+	}
+
+	body, err := r.body()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return readAll(body, r.ContentLength)
+}
+
+// DoRequest sends provided rewindable request with automatic retries, it works like Do.
+func (c *Client) DoRequest(req *Request) (*http.Response, error) {
+	return c.Do(req.Request)
+}
+
+// QueryInt sets query parameter with provided key to provided integer.
+func (r *Request) QueryInt(key string, v int64) {
+	r.setQuery(key, strconv.FormatInt(v, 10))
+}
+
+// QueryTime sets query parameter with provided key to provided time formatted with provided layout, time.RFC3339 is used when layout is empty.
+func (r *Request) QueryTime(key string, t time.Time, layout string) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	r.setQuery(key, t.Format(layout))
+}
+
+// QueryStruct sets query parameters from fields of provided struct or struct pointer, see BodyFormStruct for supported fields.
+// Existing query parameters with the same keys are replaced.
+func (r *Request) QueryStruct(v interface{}) error {
+	values, err := structValues(v)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	for key, vs := range values {
+		query[key] = vs
+	}
+	r.URL.RawQuery = query.Encode()
+
+	return nil
+}
+
+// BodyForm sets a replayable url encoded form body with provided values and its Content-Type header.
+func (r *Request) BodyForm(values url.Values) error {
+	data := []byte(values.Encode())
+	if err := r.setBody(bytesBody(data), int64(len(data))); err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", ContentTypeForm)
+
+	return nil
+}
+
+// BodyFormStruct sets a replayable url encoded form body from fields of provided struct or struct pointer and its Content-Type header.
+// Fields are named by their url tags, such as `url:"name,omitempty"`, or by their names, fields tagged with "-" and unexported fields are skipped.
+// Strings, booleans, numbers, time.Time formatted as RFC 3339, pointers to them and slices of them, encoded as repeated values, are supported.
+// Nil pointers are skipped and zero values are skipped when omitempty is set, other fields return ErrUnsupportedFormValue.
+func (r *Request) BodyFormStruct(v interface{}) error {
+	values, err := structValues(v)
+	if err != nil {
+		return err
+	}
+
+	return r.BodyForm(values)
+}
+
+// setQuery sets query parameter with provided key to provided value.
+func (r *Request) setQuery(key, value string) {
+	query := r.URL.Query()
+	query.Set(key, value)
+	r.URL.RawQuery = query.Encode()
+}
+
+// structValues returns url values of fields of provided struct or struct pointer.
+func structValues(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w, %T", ErrUnsupportedFormValue, v)
+	}
+
+	values := make(url.Values)
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("url"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fv := rv.Field(i)
+		if opts == "omitempty" && fv.IsZero() {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				if err := addValue(values, name, fv.Index(j)); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if err := addValue(values, name, fv); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// addValue adds provided field value to provided url values with provided key, nil pointers are skipped.
+func addValue(values url.Values, key string, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		values.Add(key, t.Format(time.RFC3339))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		values.Add(key, v.String())
+	case reflect.Bool:
+		values.Add(key, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(key, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(key, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(key, strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	default:
+		return fmt.Errorf("%w, %s", ErrUnsupportedFormValue, v.Type())
+	}
+
+	return nil
+}
+
+// setBody sets provided body function and content length on request, a nil function means request has no body.
+func (r *Request) setBody(bodyFunc BodyFunc, contentLength int64) error {
+	r.body = bodyFunc
+	r.ContentLength = contentLength
+	if bodyFunc == nil {
+		r.Body = http.NoBody
+		r.GetBody = func() (io.ReadCloser, error) {
+			return http.NoBody, nil
+		}
+
+		return nil
+	}
+
+	body, err := bodyFunc()
+	if err != nil {
+		return err
+	}
+	r.Body = body
+	r.GetBody = bodyFunc
+
+	return nil
+}
+
+// newBodyFunc returns a body function producing provided body and its length, -1 when length is unknown.
+func newBodyFunc(body interface{}) (BodyFunc, int64, error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, 0, nil
+	case []byte:
+		return bytesBody(body), int64(len(body)), nil
+	case string:
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(body)), nil
+		}, int64(len(body)), nil
+	case *bytes.Buffer:
+		return bytesBody(body.Bytes()), int64(body.Len()), nil
+	case BodyFunc:
+		return body, -1, nil
+	case func() (io.ReadCloser, error):
+		return body, -1, nil
+	case io.ReadSeeker:
+		start, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := body.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return func() (io.ReadCloser, error) {
+			if _, err := body.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+
+			return io.NopCloser(body), nil
+		}, end - start, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w, %T", ErrUnsupportedBody, body)
+}
+
+// bytesBody returns a body function producing readers of provided bytes.
+func bytesBody(data []byte) BodyFunc {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
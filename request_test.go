@@ -0,0 +1,171 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NewRequest function should create rewindable requests from supported bodies and reject unsupported ones.
+func TestNewRequest(t *testing.T) {
+	bodies := []interface{}{
+		[]byte("body"),
+		"body",
+		bytes.NewBufferString("body"),
+		strings.NewReader("body"),
+		BodyFunc(func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("body")), nil }),
+		func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("body")), nil },
+	}
+
+	for _, body := range bodies {
+		req, err := NewRequest(http.MethodPost, "http://example.com", body)
+		if err != nil {
+			t.Errorf("creating request with %T failed, %s", body, err.Error())
+			continue
+		}
+
+		for i := 0; i < 2; i++ {
+			data, err := req.BodyBytes()
+			if err != nil || string(data) != "body" {
+				t.Errorf("unexpected body of %T, %q, %v", body, data, err)
+			}
+		}
+		if req.GetBody == nil {
+			t.Errorf("request with %T has no GetBody", body)
+		}
+	}
+
+	req, err := NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil || req.Body != http.NoBody || req.ContentLength != 0 {
+		t.Errorf("unexpected request without body, %v", err)
+	}
+
+	if _, err := NewRequest(http.MethodPost, "http://example.com", 42); !errors.Is(err, ErrUnsupportedBody) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// DoRequest method of a client should send the same body with every attempt.
+func TestDoRequest(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	r := strings.NewReader("skipped body")
+	r.Seek(8, io.SeekStart)
+	req, err := NewRequest(http.MethodPost, s.URL, r)
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+	}
+
+	c.DoRequest(req)
+
+	if len(bodies) != 3 || bodies[0] != "body" || bodies[2] != "body" {
+		t.Errorf("unexpected bodies, %q", bodies)
+	}
+}
+
+// FromRequest function should convert requests keeping their bodies rewindable, buffering bodies without GetBody.
+func TestFromRequest(t *testing.T) {
+	withGetBody, err := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	withoutGetBody, err := http.NewRequest(http.MethodPost, "http://example.com", io.NopCloser(strings.NewReader("body")))
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	withGetBody.Header.Set("Content-Type", "text/plain")
+
+	for _, httpReq := range []*http.Request{withGetBody, withoutGetBody} {
+		req, err := FromRequest(httpReq)
+		if err != nil {
+			t.Errorf("converting request failed, %s", err.Error())
+			continue
+		}
+
+		for i := 0; i < 2; i++ {
+			data, err := req.BodyBytes()
+			if err != nil || string(data) != "body" {
+				t.Errorf("unexpected body, %q, %v", data, err)
+			}
+		}
+		if req.ContentLength != 4 || req.Header.Get("Content-Type") != httpReq.Header.Get("Content-Type") {
+			t.Errorf("unexpected request fields, %d, %v", req.ContentLength, req.Header)
+		}
+	}
+
+	withoutBody, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	req, err := FromRequest(withoutBody)
+	if err != nil || req.Body != http.NoBody || req.GetBody == nil {
+		t.Errorf("unexpected request without body, %v", err)
+	}
+}
+
+// Query and form helpers of a request should encode typed query parameters and replayable form bodies.
+func TestRequestQueryAndForm(t *testing.T) {
+	req, err := NewRequest(http.MethodPost, "http://example.com/?page=1", nil)
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+		return
+	}
+
+	type filter struct {
+		Name    string    `url:"name"`
+		Tags    []string  `url:"tag"`
+		Limit   *int      `url:"limit"`
+		Offset  int       `url:"offset,omitempty"`
+		Since   time.Time `url:"since"`
+		Skipped string    `url:"-"`
+	}
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	req.QueryInt("page", 2)
+	req.QueryTime("until", since, "2006-01-02")
+	if err := req.QueryStruct(&filter{Name: "gopher", Tags: []string{"a", "b"}, Since: since, Skipped: "x"}); err != nil {
+		t.Errorf("encoding query failed, %s", err.Error())
+	}
+	if query := req.URL.RawQuery; query != "name=gopher&page=2&since=2024-01-02T03%3A04%3A05Z&tag=a&tag=b&until=2024-01-02" {
+		t.Errorf("unexpected query, %s", query)
+	}
+
+	if err := req.BodyFormStruct(struct {
+		User  string
+		Admin bool `url:"admin"`
+	}{User: "gopher", Admin: true}); err != nil {
+		t.Errorf("encoding form failed, %s", err.Error())
+	}
+	for i := 0; i < 2; i++ {
+		body, err := req.BodyBytes()
+		if err != nil || string(body) != "User=gopher&admin=true" {
+			t.Errorf("unexpected body, %q, %v", body, err)
+		}
+	}
+	if req.Header.Get("Content-Type") != ContentTypeForm {
+		t.Errorf("unexpected content type, %s", req.Header.Get("Content-Type"))
+	}
+
+	if err := req.BodyFormStruct(struct{ Nested struct{} }{}); !errors.Is(err, ErrUnsupportedFormValue) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// endpoint resolver errors
+var (
+	ErrNilEndpointResolver = errors.New("endpoint resolver is nil")
+	ErrInvalidDNSResolver  = errors.New("dns resolver host must not be empty")
+)
+
+// Endpoint represents an endpoint base url of a logical host with its weight, priority and locality.
+// Endpoints of the lowest priority are preferred and chosen in proportion to their weights, weights of zero are treated as one.
+// Region and Zone are used to prefer local endpoints, see WithLocality.
+type Endpoint struct {
+	URL      string
+	Weight   int
+	Priority int
+	Region   string
+	Zone     string
+}
+
+// EndpointResolver represents a source of endpoints of a logical host, such as a service registry.
+type EndpointResolver interface {
+	Resolve(ctx context.Context) ([]Endpoint, error)
+}
+
+// EndpointWatcher represents an endpoint resolver which pushes endpoint changes. Watch calls provided function with the latest endpoints
+// until provided context is done or watching fails, it should return when the resolver is closed by its owner.
+type EndpointWatcher interface {
+	EndpointResolver
+	Watch(ctx context.Context, update func(endpoints []Endpoint)) error
+}
+
+// WithEndpointResolver configures client to balance requests sent to provided logical host across endpoints of provided resolver, like WithEndpoints
+// does with static endpoints. Endpoints are resolved when the first request is balanced and again every provided interval, zero means 30 seconds.
+// Endpoints of a watcher are updated as they are pushed, they are resolved periodically only while the watcher is not watching.
+// Watching stops when client is closed, see Close. Previous endpoints are kept when resolving fails and endpoints resolved again keep their health.
+func WithEndpointResolver(host string, resolver EndpointResolver, interval time.Duration) Option {
+	return named("WithEndpointResolver", func(c *Client) error {
+		if resolver == nil {
+			return ErrNilEndpointResolver
+		}
+		if host == "" || interval < 0 {
+			return ErrInvalidEndpoints
+		}
+		if interval == 0 {
+			interval = defaultDiscoveryInterval
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c.onClose(cancel)
+		c.endpoints = &endpointPool{
+			host:     strings.ToLower(host),
+			resolver: resolver,
+			interval: interval,
+			ctx:      ctx,
+		}
+
+		return nil
+	})
+}
+
+// parseEndpoints parses provided endpoints, it fails when an endpoint url is not absolute.
+func parseEndpoints(endpoints []Endpoint) ([]*endpoint, error) {
+	parsed := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%w, %s", ErrInvalidEndpoints, e.URL)
+		}
+
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		parsed = append(parsed, &endpoint{url: u, weight: weight, priority: e.Priority, region: e.Region, zone: e.Zone})
+	}
+
+	return parsed, nil
+}
+
+// StaticResolver is an endpoint resolver of a fixed set of endpoints, such as endpoints read from configuration.
+type StaticResolver []Endpoint
+
+// Resolve returns endpoints of the resolver.
+func (r StaticResolver) Resolve(ctx context.Context) ([]Endpoint, error) {
+	return r, nil
+}
+
+// DNSResolver is an endpoint resolver of the addresses of a host resolved from DNS A and AAAA records, every address is an endpoint
+// with provided scheme, http when it is empty, and port. Requests are sent to addresses, so https endpoints need certificates valid for them.
+// Resolver resolves the addresses, net.DefaultResolver is used when it is nil.
+type DNSResolver struct {
+	Host     string
+	Port     string
+	Scheme   string
+	Resolver *net.Resolver
+}
+
+// Resolve returns an endpoint for every address of the host.
+func (r DNSResolver) Resolve(ctx context.Context) ([]Endpoint, error) {
+	if r.Host == "" {
+		return nil, ErrInvalidDNSResolver
+	}
+
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	addrs, err := resolver.LookupHost(ctx, r.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		host := addr
+		if r.Port != "" {
+			host = net.JoinHostPort(addr, r.Port)
+		} else if strings.Contains(addr, ":") {
+			host = "[" + addr + "]"
+		}
+		endpoints = append(endpoints, Endpoint{URL: scheme + "://" + host})
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,144 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pushWatcher is an endpoint watcher pushing endpoints received from a channel.
+type pushWatcher struct {
+	StaticResolver
+	updates chan []Endpoint
+}
+
+// Watch pushes endpoints received from the channel until it is closed.
+func (w *pushWatcher) Watch(ctx context.Context, update func(endpoints []Endpoint)) error {
+	for endpoints := range w.updates {
+		update(endpoints)
+	}
+
+	return nil
+}
+
+// NewClient function should return ErrNilEndpointResolver when nil endpoint resolver is provided.
+func TestNilEndpointResolverOption(t *testing.T) {
+	if _, err := NewClient(WithEndpointResolver("api.internal", nil, 0)); !errors.Is(err, ErrNilEndpointResolver) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with an endpoint watcher should balance requests across endpoints pushed by the watcher.
+func TestEndpointWatcher(t *testing.T) {
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server", "first")
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server", "second")
+	}))
+	defer second.Close()
+
+	watcher := &pushWatcher{
+		StaticResolver: StaticResolver{{URL: first.URL}},
+		updates:        make(chan []Endpoint),
+	}
+	defer close(watcher.updates)
+
+	c, err := NewClient(
+		WithEndpointResolver("api.internal", watcher, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	server := func() string {
+		res, err := c.Get("http://api.internal/")
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+			return ""
+		}
+		discard(res)
+
+		return res.Header.Get("X-Server")
+	}
+
+	if s := server(); s != "first" {
+		t.Errorf("unexpected server, %s", s)
+	}
+
+	watcher.updates <- []Endpoint{{URL: second.URL}}
+	deadline := time.Now().Add(time.Second)
+	for server() != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("pushed endpoints are not used")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// blockingWatcher is an endpoint watcher which watches until its context is done.
+type blockingWatcher struct {
+	StaticResolver
+	stopped chan struct{}
+}
+
+// Watch blocks until provided context is done.
+func (w *blockingWatcher) Watch(ctx context.Context, update func(endpoints []Endpoint)) error {
+	<-ctx.Done()
+	close(w.stopped)
+
+	return ctx.Err()
+}
+
+// Close method of a client with an endpoint watcher should stop watching.
+func TestEndpointWatcherClose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	watcher := &blockingWatcher{
+		StaticResolver: StaticResolver{{URL: s.URL}},
+		stopped:        make(chan struct{}),
+	}
+
+	c, err := NewClient(
+		WithEndpointResolver("api.internal", watcher, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get("http://api.internal/")
+	if err != nil {
+		t.Fatalf("request failed, %v", err)
+	}
+	discard(res)
+
+	c.Close()
+	select {
+	case <-watcher.stopped:
+	case <-time.After(time.Second):
+		t.Error("watching is not stopped")
+	}
+
+	// closed client resolves endpoints periodically
+	res, err = c.Get("http://api.internal/")
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+}
+
+// Resolve method of DNSResolver should return an endpoint for every address of the host.
+func TestDNSResolver(t *testing.T) {
+	endpoints, err := DNSResolver{Host: "127.0.0.1", Port: "8080"}.Resolve(context.Background())
+	if err != nil {
+		t.Errorf("resolving failed, %v", err)
+	}
+	if len(endpoints) != 1 || endpoints[0].URL != "http://127.0.0.1:8080" {
+		t.Errorf("unexpected endpoints, %v", endpoints)
+	}
+}
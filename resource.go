@@ -0,0 +1,104 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// resource errors
+var (
+	ErrInvalidResourceURL = errors.New("resource url is not valid")
+)
+
+// idPlaceholder is the placeholder of resource ids in path templates.
+const idPlaceholder = "{id}"
+
+// Resource represents a typed client of a REST resource whose representations are encoded as JSON.
+// Get, Update and Delete are idempotent and retried like other requests of the client, Create is retried only on connection failures
+// so that resources are not created twice.
+type Resource[T any] struct {
+	client *Client
+	// collection is url of the collection, item is url of an item with id placeholder.
+	collection string
+	item       string
+}
+
+// NewResource creates and returns a resource client sending requests to provided base url and path template with provided client.
+// Path template is the path of an item with an {id} placeholder, such as "/v1/users/{id}", collection path is the part before the placeholder.
+// A template without placeholder is treated as the collection path, items are addressed below it.
+func NewResource[T any](c *Client, baseURL, pathTemplate string) (*Resource[T], error) {
+	if c == nil {
+		return nil, ErrNilClient
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidResourceURL, baseURL)
+	}
+
+	if !strings.Contains(pathTemplate, idPlaceholder) {
+		pathTemplate = strings.TrimSuffix(pathTemplate, "/") + "/" + idPlaceholder
+	}
+	if strings.Count(pathTemplate, idPlaceholder) != 1 || !strings.HasSuffix(pathTemplate, idPlaceholder) {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidResourceURL, pathTemplate)
+	}
+
+	prefix := strings.TrimSuffix(base.String(), "/") + "/" + strings.TrimPrefix(pathTemplate, "/")
+
+	return &Resource[T]{
+		client:     c,
+		collection: strings.TrimSuffix(strings.TrimSuffix(prefix, idPlaceholder), "/"),
+		item:       prefix,
+	}, nil
+}
+
+// Get returns the item with provided id.
+func (r *Resource[T]) Get(ctx context.Context, id string) (T, error) {
+	var out T
+	err := r.client.DoJSON(ctx, http.MethodGet, r.itemURL(id), nil, &out)
+
+	return out, err
+}
+
+// List returns items of the collection filtered by provided query parameters, which may be nil.
+func (r *Resource[T]) List(ctx context.Context, params url.Values) ([]T, error) {
+	u := r.collection
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	var out []T
+	err := r.client.DoJSON(ctx, http.MethodGet, u, nil, &out)
+
+	return out, err
+}
+
+// Create posts provided item to the collection and returns the created item.
+func (r *Resource[T]) Create(ctx context.Context, v T) (T, error) {
+	var out T
+	err := r.client.DoJSON(contextWithRetryCondition(ctx, RetryOnConnectionFailure), http.MethodPost, r.collection, v, &out)
+
+	return out, err
+}
+
+// Update puts provided item to the item with provided id and returns the updated item.
+func (r *Resource[T]) Update(ctx context.Context, id string, v T) (T, error) {
+	var out T
+	err := r.client.DoJSON(ctx, http.MethodPut, r.itemURL(id), v, &out)
+
+	return out, err
+}
+
+// Delete deletes the item with provided id.
+func (r *Resource[T]) Delete(ctx context.Context, id string) error {
+	return r.client.DoJSON(ctx, http.MethodDelete, r.itemURL(id), nil, nil)
+}
+
+// itemURL returns url of the item with provided id, id is escaped as a path segment.
+func (r *Resource[T]) itemURL(id string) string {
+	return strings.Replace(r.item, idPlaceholder, url.PathEscape(id), 1)
+}
@@ -0,0 +1,118 @@
+package retryablehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// user represents resource of resource tests.
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewResource function should return ErrInvalidResourceURL when invalid base url or path template is provided.
+func TestInvalidResource(t *testing.T) {
+	for _, tc := range [][2]string{{"example.com", "/users"}, {"http://example.com", "/users/{id}/{id}"}, {"http://example.com", "/users/{id}/posts"}} {
+		if _, err := NewResource[user](&Client{}, tc[0], tc[1]); !errors.Is(err, ErrInvalidResourceURL) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// Methods of a resource should send requests to collection and item urls and retry only idempotent requests on unsuccessful responses.
+func TestResource(t *testing.T) {
+	attempts := make(map[string]int)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.RequestURI()
+		attempts[key]++
+		if attempts[key] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch key {
+		case "GET /v1/users/a%2Fb":
+			json.NewEncoder(w).Encode(user{ID: "a/b", Name: "gopher"})
+		case "GET /v1/users?name=gopher":
+			json.NewEncoder(w).Encode([]user{{ID: "1", Name: "gopher"}})
+		case "PUT /v1/users/1":
+			var in user
+			json.NewDecoder(r.Body).Decode(&in)
+			json.NewEncoder(w).Encode(in)
+		case "DELETE /v1/users/1":
+		default:
+			t.Errorf("unexpected request, %s", key)
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	users, err := NewResource[user](c, s.URL+"/", "/v1/users/{id}")
+	if err != nil {
+		t.Errorf("creating resource failed, %s", err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	if u, err := users.Get(ctx, "a/b"); err != nil || u.Name != "gopher" {
+		t.Errorf("unexpected get result, %v, %v", u, err)
+	}
+	if list, err := users.List(ctx, url.Values{"name": {"gopher"}}); err != nil || len(list) != 1 {
+		t.Errorf("unexpected list result, %v, %v", list, err)
+	}
+	if u, err := users.Update(ctx, "1", user{ID: "1", Name: "updated"}); err != nil || u.Name != "updated" {
+		t.Errorf("unexpected update result, %v, %v", u, err)
+	}
+	if err := users.Delete(ctx, "1"); err != nil {
+		t.Errorf("unexpected delete error, %v", err)
+	}
+	if _, err := users.Create(ctx, user{Name: "new"}); err == nil || attempts["POST /v1/users"] != 1 {
+		t.Errorf("unexpected create result, %v, %d", err, attempts["POST /v1/users"])
+	}
+}
+
+// Create method of a resource should use client's current settings and statistics while retrying only connection failures.
+func TestResourceCreateSettings(t *testing.T) {
+	attempts := 0
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	users, err := NewResource[user](c, "http://example.com", "/v1/users/{id}")
+	if err != nil {
+		t.Fatalf("creating resource failed, %s", err.Error())
+	}
+
+	if err := c.SetRetryPolicy(Policy{MaxReqCount: 3, Backoff: ConstantBackoff(0)}); err != nil {
+		t.Errorf("setting retry policy failed, %s", err.Error())
+	}
+	if _, err := users.Create(context.Background(), user{Name: "new"}); err == nil {
+		t.Error("unexpected nil error")
+	}
+
+	if attempts != 3 {
+		t.Errorf("unexpected attempt count, %d", attempts)
+	}
+	if n := c.Stats().Hosts["example.com"].Attempts; n != 3 {
+		t.Errorf("create attempts are not counted in client's statistics, %d", n)
+	}
+}
@@ -0,0 +1,37 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// result errors
+var (
+	ErrNilErrorHandler = errors.New("error handler is nil")
+)
+
+// WithErrorHandler configures client's error handler, which is called exactly once when client gives up a request sent by Do.
+// It receives the last response, which may be nil, the final error and the attempt count, and its results are returned by Do,
+// so that callers can close the response and return nil, or synthesize a response. By default the last response and error are returned.
+// The response is nil when client is configured with WithDiscardFailedResponse.
+func WithErrorHandler(errorHandler func(res *http.Response, err error, attempts int) (*http.Response, error)) Option {
+	return named("WithErrorHandler", func(c *Client) error {
+		if errorHandler == nil {
+			return ErrNilErrorHandler
+		}
+
+		c.errorHandler = errorHandler
+
+		return nil
+	})
+}
+
+// WithDiscardFailedResponse configures client to drain and close the last response of a request sent by Do when client gives up,
+// returning a nil response with the error, so that callers do not leak failed responses. Failed responses are returned unread by default.
+func WithDiscardFailedResponse() Option {
+	return named("WithDiscardFailedResponse", func(c *Client) error {
+		c.discardFailedRes = true
+
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrNilErrorHandler when nil error handler is provided.
+func TestNilErrorHandlerOption(t *testing.T) {
+	_, err := NewClient(
+		WithErrorHandler(nil),
+	)
+	if !errors.Is(err, ErrNilErrorHandler) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with error handler should return results of the handler, called once when client gives up.
+func TestErrorHandler(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	errGaveUp := errors.New("gave up")
+	calls := 0
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithErrorHandler(func(res *http.Response, err error, attempts int) (*http.Response, error) {
+			calls++
+			if res == nil || res.StatusCode != http.StatusServiceUnavailable || !errors.Is(err, ErrUnsuccessfulStatusCode) || attempts != 3 {
+				t.Errorf("unexpected error handler arguments, %v, %v, %d", res, err, attempts)
+			}
+			discard(res)
+
+			return nil, errGaveUp
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if res != nil || err != errGaveUp {
+		t.Errorf("unexpected result, %v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Errorf("unexpected error handler call count, %d", calls)
+	}
+}
+
+// Do method of a client with discard failed response option should close the last response and return nil response on error.
+func TestDiscardFailedResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	var body *closeRecorder
+	c, err := NewClient(
+		WithDiscardFailedResponse(),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			res, err := http.DefaultTransport.RoundTrip(r)
+			if err == nil {
+				body = &closeRecorder{ReadCloser: res.Body}
+				res.Body = body
+			}
+
+			return res, err
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if res != nil || !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Errorf("unexpected result, %v, %v", res, err)
+	}
+	if body == nil || !body.closed {
+		t.Error("failed response is not closed")
+	}
+}
+
+// closeRecorder is a body which records whether it is closed.
+type closeRecorder struct {
+	io.ReadCloser
+	closed bool
+}
+
+// Close closes body and records it.
+func (b *closeRecorder) Close() error {
+	b.closed = true
+
+	return b.ReadCloser.Close()
+}
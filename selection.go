@@ -0,0 +1,88 @@
+package retryablehttp
+
+import (
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// endpoint selection errors
+var (
+	ErrInvalidEndpointSelection = errors.New("endpoint selection is not valid")
+)
+
+// EndpointSelection represents how balanced endpoints are chosen among healthy endpoints of the preferred locality and priority.
+type EndpointSelection int
+
+// endpoint selections
+const (
+	// SelectRoundRobin chooses endpoints in smooth weighted round robin order, it is the default.
+	SelectRoundRobin EndpointSelection = iota
+	// SelectPowerOfTwo chooses the endpoint of lower cost of two random endpoints.
+	SelectPowerOfTwo
+	// SelectLeastLoaded chooses the endpoint with the fewest attempts in flight, ties are broken by cost.
+	SelectLeastLoaded
+)
+
+// WithEndpointSelection configures how client chooses balanced endpoints. Cost of an endpoint grows with its latency, attempts in flight and
+// error rate, latencies and error rates are exponentially weighted moving averages of host statistics, so that requests and their retries migrate away
+// from slow or failing endpoints. Endpoints without statistics cost least, so that they are tried. Requests with an affinity key keep their endpoint.
+// Endpoints are chosen in round robin order by default.
+func WithEndpointSelection(selection EndpointSelection) Option {
+	return named("WithEndpointSelection", func(c *Client) error {
+		if selection != SelectRoundRobin && selection != SelectPowerOfTwo && selection != SelectLeastLoaded {
+			return ErrInvalidEndpointSelection
+		}
+
+		c.endpointSelection = selection
+
+		return nil
+	})
+}
+
+// chooseEndpoint chooses one of provided endpoints with client's endpoint selection.
+func (c *Client) chooseEndpoint(candidates []*endpoint) *endpoint {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	if c.endpointSelection == SelectPowerOfTwo {
+		i := c.rand.Intn(len(candidates))
+		j := c.rand.Intn(len(candidates) - 1)
+		if j >= i {
+			j++
+		}
+		if c.endpointCost(candidates[j]) < c.endpointCost(candidates[i]) {
+			return candidates[j]
+		}
+
+		return candidates[i]
+	}
+
+	var picked *endpoint
+	var pickedLoad int64
+	var pickedCost float64
+	for _, e := range candidates {
+		load, cost := atomic.LoadInt64(&e.inflight), c.endpointCost(e)
+		if picked == nil || load < pickedLoad || (load == pickedLoad && cost < pickedCost) {
+			picked, pickedLoad, pickedCost = e, load, cost
+		}
+	}
+
+	return picked
+}
+
+// endpointCost returns cost of provided endpoint from its host statistics and attempts in flight.
+func (c *Client) endpointCost(e *endpoint) float64 {
+	value, ok := c.stats.hosts.Load(e.url.Host)
+	if !ok {
+		return 0
+	}
+	h := value.(*hostCounters)
+
+	latency := time.Duration(atomic.LoadInt64(&h.latency))
+	errorRate := math.Float64frombits(atomic.LoadUint64(&h.errorRate))
+
+	return float64(latency+1) * float64(atomic.LoadInt64(&e.inflight)+1) / (1 - errorRate*0.99)
+}
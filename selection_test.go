@@ -0,0 +1,52 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidEndpointSelection when invalid endpoint selection is provided.
+func TestInvalidEndpointSelectionOption(t *testing.T) {
+	if _, err := NewClient(WithEndpointSelection(EndpointSelection(-1))); !errors.Is(err, ErrInvalidEndpointSelection) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with latency aware endpoint selection should send requests to the faster endpoint.
+func TestEndpointSelection(t *testing.T) {
+	for _, selection := range []EndpointSelection{SelectPowerOfTwo, SelectLeastLoaded} {
+		slowCount, fastCount := 0, 0
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slowCount++
+			time.Sleep(20 * time.Millisecond)
+		}))
+		fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fastCount++
+		}))
+
+		c, err := NewClient(
+			WithEndpoints("api.internal", slow.URL, fast.URL),
+			WithEndpointSelection(selection),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		for i := 0; i < 10; i++ {
+			res, err := c.Get("http://api.internal/")
+			if err != nil {
+				t.Errorf("request failed, %v", err)
+			}
+			discard(res)
+		}
+		slow.Close()
+		fast.Close()
+
+		if slowCount > 1 || fastCount < 9 {
+			t.Errorf("requests are not sent to the faster endpoint with selection %d, %d, %d", selection, slowCount, fastCount)
+		}
+	}
+}
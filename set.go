@@ -0,0 +1,98 @@
+package retryablehttp
+
+import (
+	"net/http"
+	"time"
+)
+
+// SetMaxReqCount changes client's maximum request count, it is safe for concurrent use with in-flight requests.
+// Requests in flight keep the maximum request count they started with.
+func (c *Client) SetMaxReqCount(maxReqCount int) error {
+	return c.set(WithMaxReqCount(maxReqCount))
+}
+
+// SetBackoff changes client's backoff duration, it is safe for concurrent use with in-flight requests.
+// Requests in flight keep the backoff policy they started with.
+func (c *Client) SetBackoff(backoff time.Duration) error {
+	return c.set(WithBackoff(backoff))
+}
+
+// SetBackoffPolicy changes client's backoff policy, it is safe for concurrent use with in-flight requests.
+// Requests in flight keep the backoff policy they started with.
+func (c *Client) SetBackoffPolicy(policy BackoffPolicy) error {
+	return c.set(WithBackoffPolicy(policy))
+}
+
+// SetRetryPolicy changes client's maximum request count, backoff policy and retry condition to those of provided policy, it is safe for concurrent
+// use with in-flight requests. Zero MaxReqCount and nil Backoff keep client's settings. Requests in flight keep the settings they started with.
+// Client's settings are not changed when any setting of the policy is invalid.
+func (c *Client) SetRetryPolicy(policy Policy) error {
+	return c.set(policy.options()...)
+}
+
+// SetResHandler changes client's response handler which decides whether responses are retried, it is safe for concurrent use with in-flight requests.
+// Changed response handler applies to following attempts of requests in flight.
+func (c *Client) SetResHandler(resHandler func(res *http.Response) error) error {
+	return c.set(WithResHandler(resHandler))
+}
+
+// set applies provided options to client exclusively, either all of them or none of them.
+func (c *Client) set(opts ...Option) error {
+	c.init()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// options are validated on a copy of client's settings, so that client is changed only when every option succeeds,
+	// and are then applied to client itself rather than swapping the copy in, since settings they do not change are read without the lock
+	scratch := &Client{settings: c.settings}
+	for _, opt := range opts {
+		if err := opt(scratch); err != nil {
+			if optionErr, ok := err.(*OptionError); ok {
+				return optionErr.Err
+			}
+
+			return err
+		}
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// clients derived for policies are derived again with changed settings
+	c.policyClients = nil
+
+	return nil
+}
+
+// retrySettings returns client's maximum request count, backoff policy and jitter.
+func (c *Client) retrySettings() (int, BackoffPolicy, float64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.maxReqCount, c.backoff, c.jitter
+}
+
+// currentHTTPClient returns client's underlying http client.
+func (c *Client) currentHTTPClient() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.httpClient
+}
+
+// retryCondition returns client's retry condition.
+func (c *Client) retryCondition() RetryCondition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.retryOn
+}
+
+// resHandlerFunc returns client's response handler.
+func (c *Client) resHandlerFunc() func(res *http.Response) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.resHandler
+}
@@ -0,0 +1,128 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Set methods of a client should validate and apply new settings while requests are in flight.
+func TestSetMethods(t *testing.T) {
+	var mu sync.Mutex
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		reqCount++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if err := c.SetMaxReqCount(0); err != ErrInvalidMaxReqCount {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := c.SetBackoff(-time.Second); err != ErrInvalidBackoff {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := c.SetBackoffPolicy(nil); err != ErrNilBackoffPolicy {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := c.SetResHandler(nil); err != ErrNilResHandler {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+			if err != nil {
+				t.Errorf("creating http request failed, %s", err.Error())
+			}
+
+			c.Do(req)
+		}()
+		go func() {
+			defer wg.Done()
+
+			c.SetMaxReqCount(2)
+			c.SetBackoffPolicy(ConstantBackoff(time.Millisecond))
+		}()
+	}
+	wg.Wait()
+
+	reqCount = 0
+	if err := c.SetResHandler(func(res *http.Response) error { return nil }); err != nil {
+		t.Errorf("setting response handler failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// SetRetryPolicy method of a client should apply maximum request count, backoff and retry condition of provided policy.
+func TestSetRetryPolicy(t *testing.T) {
+	attempts := 0
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if err := c.SetRetryPolicy(Policy{MaxReqCount: -1}); !errors.Is(err, ErrInvalidMaxReqCount) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if err := c.SetRetryPolicy(Policy{MaxReqCount: 5, RetryOn: RetryCondition(-1)}); !errors.Is(err, ErrInvalidRetryCondition) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if maxReqCount, _, _ := c.retrySettings(); maxReqCount != 1 {
+		t.Errorf("client is changed by invalid retry policy, %d", maxReqCount)
+	}
+
+	for _, tc := range []struct {
+		policy   Policy
+		attempts int
+	}{
+		{Policy{MaxReqCount: 3, Backoff: ConstantBackoff(time.Millisecond)}, 3},
+		{Policy{RetryOn: RetryOnConnectionFailure}, 1},
+	} {
+		if err := c.SetRetryPolicy(tc.policy); err != nil {
+			t.Errorf("setting retry policy failed, %s", err.Error())
+		}
+
+		attempts = 0
+		req, err := http.NewRequest(http.MethodPost, "http://example.com", http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+		res, _ := c.Do(req)
+		discard(res)
+
+		if attempts != tc.attempts {
+			t.Errorf("unexpected attempt count, %d", attempts)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// url refresh errors
+var (
+	ErrNilURLRefresh     = errors.New("url refresh function is nil")
+	ErrInvalidURLRefresh = errors.New("maximum url refresh count must not be negative")
+)
+
+// defaultMaxURLRefreshes is the maximum number of url refreshes of a request when it is not configured.
+const defaultMaxURLRefreshes = 1
+
+// URLRefreshFunc returns a fresh url replacing provided url rejected as forbidden, such as a newly presigned url of an object store.
+type URLRefreshFunc func(ctx context.Context, u *url.URL) (*url.URL, error)
+
+// WithURLRefresh configures client to replace urls of requests responded with 403 status code, such as expired presigned urls,
+// with urls returned by provided function and to send them again. Refreshed attempts do not count toward maximum request count and are not delayed.
+// A request is refreshed at most provided number of times, zero means once, later 403 responses are handled by response handler.
+// Requests with bodies which can not be replayed are not refreshed. Failures of provided function are not retried. It applies to Do and methods built on it.
+func WithURLRefresh(refresh URLRefreshFunc, maxRefreshes int) Option {
+	return named("WithURLRefresh", func(c *Client) error {
+		if refresh == nil {
+			return ErrNilURLRefresh
+		}
+		if maxRefreshes < 0 {
+			return ErrInvalidURLRefresh
+		}
+		if maxRefreshes == 0 {
+			maxRefreshes = defaultMaxURLRefreshes
+		}
+
+		c.urlRefresh = refresh
+		c.maxURLRefreshes = maxRefreshes
+
+		return nil
+	})
+}
+
+// refreshURL returns provided request with a fresh url when provided response rejects its url, it returns nil when the request is not refreshed.
+// Refreshes are counted in call state, the body of a rejected response is discarded.
+func (c *Client) refreshURL(cl *call, req *http.Request, res *http.Response) (*http.Request, error) {
+	if res.StatusCode != http.StatusForbidden || cl.refreshes >= c.maxURLRefreshes {
+		return nil, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil, nil
+	}
+
+	var u *url.URL
+	if err := c.safely(func() error {
+		var err error
+		u, err = c.urlRefresh(req.Context(), req.URL)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, nil
+	}
+
+	next := req.WithContext(req.Context())
+	next.URL = u
+	next.Host = ""
+
+	discard(res)
+	cl.refreshes++
+	cl.refreshed = true
+
+	return next, nil
+}
@@ -0,0 +1,67 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// NewClient function should return ErrNilURLRefresh and ErrInvalidURLRefresh when invalid url refresh settings are provided.
+func TestInvalidURLRefreshOption(t *testing.T) {
+	if _, err := NewClient(WithURLRefresh(nil, 1)); !errors.Is(err, ErrNilURLRefresh) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	refresh := func(ctx context.Context, u *url.URL) (*url.URL, error) {
+		return u, nil
+	}
+	if _, err := NewClient(WithURLRefresh(refresh, -1)); !errors.Is(err, ErrInvalidURLRefresh) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with url refresh should send forbidden requests again with fresh urls without consuming maximum request count.
+func TestURLRefresh(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("signature") != "fresh" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer s.Close()
+
+	refreshes := 0
+	c, err := NewClient(
+		WithURLRefresh(func(ctx context.Context, u *url.URL) (*url.URL, error) {
+			refreshes++
+			fresh := *u
+			fresh.RawQuery = "signature=fresh"
+			return &fresh, nil
+		}, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL + "?signature=expired")
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+	if refreshes != 1 {
+		t.Errorf("unexpected refresh count, %d", refreshes)
+	}
+
+	failing, err := c.With(WithURLRefresh(func(ctx context.Context, u *url.URL) (*url.URL, error) {
+		return nil, errors.New("signing failed")
+	}, 0))
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if _, err := failing.Get(s.URL); err == nil || !IsPermanent(err) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
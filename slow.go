@@ -0,0 +1,93 @@
+package retryablehttp
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// slow request errors
+var (
+	ErrInvalidSlowThreshold = errors.New("slow threshold must be greater than zero")
+)
+
+// SlowRequest represents an attempt or a whole request whose latency exceeds slow threshold.
+// Attempt is zero for the whole request, whose timing is the sum of its attempts' timings.
+type SlowRequest struct {
+	Method  string
+	URL     string
+	Attempt int
+	Latency time.Duration
+	Timing  TimingReport
+}
+
+// WithSlowThreshold configures client to call provided callback when an attempt or a whole request, including backoff durations, takes longer than provided threshold.
+// Slow requests are logged with the standard logger when callback is nil. Slow request detection is disabled by default.
+func WithSlowThreshold(threshold time.Duration, callback func(slow SlowRequest)) Option {
+	return named("WithSlowThreshold", func(c *Client) error {
+		if threshold <= 0 {
+			return ErrInvalidSlowThreshold
+		}
+
+		if callback == nil {
+			callback = logSlowRequest
+		}
+
+		c.slowThreshold = threshold
+		c.slowCallback = callback
+
+		return nil
+	})
+}
+
+// logSlowRequest logs provided slow request with the standard logger.
+func logSlowRequest(slow SlowRequest) {
+	t := slow.Timing
+	log.Printf("retryablehttp: slow request %s %s attempt %d took %s (dns %s, connect %s, tls %s, ttfb %s, backoff %s)",
+		slow.Method, slow.URL, slow.Attempt, slow.Latency, t.DNS, t.Connect, t.TLS, t.TTFB, t.Backoff)
+}
+
+// checkSlowAttempt calls slow callback when call's current attempt exceeds slow threshold.
+func (c *Client) checkSlowAttempt(cl *call) {
+	if cl.req == nil || cl.latency <= c.slowThreshold {
+		return
+	}
+
+	slow := SlowRequest{
+		Method:  cl.req.Method,
+		URL:     c.redactor.url(cl.req.URL).String(),
+		Attempt: cl.attempt,
+		Latency: cl.latency,
+		Timing:  cl.attemptReport.Timing,
+	}
+	c.hook(func() {
+		c.slowCallback(slow)
+	})
+}
+
+// checkSlowRequest calls slow callback when call's whole request, which took provided duration, exceeds slow threshold.
+func (c *Client) checkSlowRequest(cl *call, elapsed time.Duration) {
+	if cl.req == nil || elapsed <= c.slowThreshold {
+		return
+	}
+
+	var timing TimingReport
+	for _, attempt := range cl.report.Attempts {
+		timing.DNS += attempt.Timing.DNS
+		timing.Connect += attempt.Timing.Connect
+		timing.TLS += attempt.Timing.TLS
+		timing.TTFB += attempt.Timing.TTFB
+		timing.BodyRead += attempt.Timing.BodyRead
+		timing.Backoff += attempt.Timing.Backoff
+	}
+
+	slow := SlowRequest{
+		Method:  cl.req.Method,
+		URL:     c.redactor.url(cl.req.URL).String(),
+		Latency: elapsed,
+		Timing:  timing,
+	}
+	c.hook(func() {
+		c.slowCallback(slow)
+	})
+}
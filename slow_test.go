@@ -0,0 +1,66 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidSlowThreshold when non-positive slow threshold is provided.
+func TestInvalidSlowThresholdOption(t *testing.T) {
+	_, err := NewClient(
+		WithSlowThreshold(0, nil),
+	)
+	if !errors.Is(err, ErrInvalidSlowThreshold) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with slow threshold should report slow attempts and slow whole requests with timing breakdown.
+func TestSlowThreshold(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			time.Sleep(30 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	var slows []SlowRequest
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithBackoff(10*time.Millisecond),
+		WithSlowThreshold(20*time.Millisecond, func(slow SlowRequest) {
+			slows = append(slows, slow)
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL+"?api_key=secret", http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	}
+
+	if len(slows) != 2 {
+		t.Fatalf("unexpected number of slow requests, %d", len(slows))
+	}
+	if slows[0].Attempt != 1 || slows[0].Latency < 30*time.Millisecond || slows[0].Timing.TTFB < 30*time.Millisecond {
+		t.Errorf("unexpected slow attempt, %+v", slows[0])
+	}
+	if slows[1].Attempt != 0 || slows[1].Latency < 40*time.Millisecond || slows[1].Timing.Backoff != 10*time.Millisecond {
+		t.Errorf("unexpected slow request, %+v", slows[1])
+	}
+	if slows[1].Method != http.MethodGet || slows[1].URL != s.URL+"?api_key=REDACTED" {
+		t.Errorf("unexpected slow request, %s %s", slows[1].Method, slows[1].URL)
+	}
+}
@@ -0,0 +1,70 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// srv discovery errors
+var (
+	ErrInvalidSRVDiscovery = errors.New("srv name must not be empty")
+)
+
+// defaultDiscoveryInterval is the interval endpoints are resolved again at when it is not configured.
+const defaultDiscoveryInterval = 30 * time.Second
+
+// lookupSRV looks up SRV records, it is replaced in tests.
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// WithSRVDiscovery configures client to balance requests sent to provided name across endpoints resolved from DNS SRV records of provided service,
+// protocol and name, such as "http", "tcp" and "api.internal" for "_http._tcp.api.internal", like WithEndpoints does with static endpoints.
+// Endpoints are resolved by an SRVResolver every 30 seconds, since the standard resolver does not expose TTLs of records.
+func WithSRVDiscovery(service, proto, name string) Option {
+	return named("WithSRVDiscovery", func(c *Client) error {
+		if name == "" {
+			return ErrInvalidSRVDiscovery
+		}
+
+		return WithEndpointResolver(name, SRVResolver{Service: service, Proto: proto, Name: name}, defaultDiscoveryInterval)(c)
+	})
+}
+
+// SRVResolver is an endpoint resolver of DNS SRV records of a service, protocol and name. Record priorities and weights are kept,
+// endpoints use https scheme when service is "https" and http scheme otherwise.
+type SRVResolver struct {
+	Service string
+	Proto   string
+	Name    string
+}
+
+// Resolve returns an endpoint for every SRV record.
+func (r SRVResolver) Resolve(ctx context.Context) ([]Endpoint, error) {
+	if r.Name == "" {
+		return nil, ErrInvalidSRVDiscovery
+	}
+
+	_, records, err := lookupSRV(ctx, r.Service, r.Proto, r.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "http"
+	if strings.EqualFold(r.Service, "https") {
+		scheme = "https"
+	}
+
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, record := range records {
+		endpoints = append(endpoints, Endpoint{
+			URL:      scheme + "://" + net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))),
+			Weight:   int(record.Weight),
+			Priority: int(record.Priority),
+		})
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,80 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// NewClient function should return ErrInvalidSRVDiscovery when empty srv name is provided.
+func TestInvalidSRVDiscoveryOption(t *testing.T) {
+	if _, err := NewClient(WithSRVDiscovery("http", "tcp", "")); !errors.Is(err, ErrInvalidSRVDiscovery) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with srv discovery should balance requests by weight across preferred endpoints and refresh records periodically.
+func TestSRVDiscovery(t *testing.T) {
+	hits := make(map[uint16]int)
+	records := make([]*net.SRV, 3)
+	for i, weight := range []uint16{3, 1, 1} {
+		var port uint16
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[port]++
+		}))
+		defer s.Close()
+
+		u, _ := url.Parse(s.URL)
+		p, _ := strconv.Atoi(u.Port())
+		port = uint16(p)
+		records[i] = &net.SRV{Target: "127.0.0.1.", Port: port, Weight: weight}
+	}
+	records[2].Priority = 1
+
+	lookups := 0
+	current := records
+	defer func(lookup func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)) {
+		lookupSRV = lookup
+	}(lookupSRV)
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		lookups++
+		return "_http._tcp." + name, current, nil
+	}
+
+	clock := &fakeClock{}
+	c, err := NewClient(
+		WithClock(clock),
+		WithSRVDiscovery("http", "tcp", "api.internal"),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for i := 0; i < 4; i++ {
+		res, err := c.Get("http://api.internal/users")
+		if err != nil {
+			t.Errorf("request failed, %v", err)
+		}
+		discard(res)
+	}
+	if hits[records[0].Port] != 3 || hits[records[1].Port] != 1 || lookups != 1 {
+		t.Errorf("unexpected hits or lookup count, %v, %d", hits, lookups)
+	}
+
+	current = records[2:]
+	clock.Sleep(time.Minute)
+	res, err := c.Get("http://api.internal/users")
+	if err != nil {
+		t.Errorf("request failed, %v", err)
+	}
+	discard(res)
+	if hits[records[2].Port] != 1 || lookups != 2 {
+		t.Errorf("unexpected hits or lookup count, %v, %d", hits, lookups)
+	}
+}
@@ -0,0 +1,224 @@
+package retryablehttp
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ewmaWeight is the weight of the latest attempt in exponentially weighted moving averages of host statistics.
+const ewmaWeight = 0.2
+
+// Stats represents cumulative statistics of a client.
+// Shed counts attempts rejected with ErrOverloaded. Retries counts retried attempts and LimitedRetries counts retries denied by retry rate limit.
+// ConnectionFailures counts attempts failed by the transport without a response and ResponseFailures counts failed attempts with a response.
+// DroppedMirrors counts sampled requests which are not mirrored since too many mirrored requests are in flight.
+// Hosts holds latency and error rate statistics keyed by request host and Labels holds them keyed by request labels, such as "operation=create_order",
+// see ContextWithLabels.
+type Stats struct {
+	Truncations        uint64
+	DroppedEvents      uint64
+	DroppedMirrors     uint64
+	Shed               uint64
+	Retries            uint64
+	LimitedRetries     uint64
+	ConnectionFailures uint64
+	ResponseFailures   uint64
+	Hosts              map[string]HostStats
+	Labels             map[string]HostStats
+}
+
+// HostStats represents statistics of attempts sent to a host.
+// Latency and ErrorRate are exponentially weighted moving averages, recent attempts weigh more.
+// ConcurrencyLimit is the current in-flight limit of the host when adaptive concurrency is configured.
+// Region is the region of the host when it is a balanced endpoint with a region.
+type HostStats struct {
+	Attempts         uint64
+	Latency          time.Duration
+	ErrorRate        float64
+	ConcurrencyLimit int
+	Region           string
+}
+
+// stats holds client's counters and host statistics, they are updated without locks so that clients shared by many goroutines do not contend on them.
+type stats struct {
+	truncations        counter
+	droppedEvents      counter
+	droppedMirrors     counter
+	shed               counter
+	retries            counter
+	limitedRetries     counter
+	connectionFailures counter
+	responseFailures   counter
+
+	// hosts holds *hostCounters keyed by host and labels holds them keyed by label key and value joined with "=".
+	hosts  sync.Map
+	labels sync.Map
+}
+
+// hostCounters holds statistics of a host, fields are updated atomically. Latency holds nanoseconds and errorRate holds float64 bits.
+type hostCounters struct {
+	attempts  uint64
+	latency   int64
+	errorRate uint64
+	region    string
+}
+
+// counter is a counter sharded per processor. Goroutines take shards from a pool, which keeps them per processor, so that increments do not contend.
+type counter struct {
+	pool sync.Pool
+
+	mu     sync.Mutex
+	shards []*counterShard
+	next   uint32
+}
+
+// counterShard is a shard of a counter padded to a cache line.
+type counterShard struct {
+	n uint64
+	_ [56]byte
+}
+
+// add adds provided value to counter.
+func (c *counter) add(n uint64) {
+	s, ok := c.pool.Get().(*counterShard)
+	if !ok {
+		s = c.shard()
+	}
+
+	atomic.AddUint64(&s.n, n)
+	c.pool.Put(s)
+}
+
+// shard returns a new shard, or an existing one when there are already as many shards as processors.
+func (c *counter) shard() *counterShard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.shards) >= runtime.GOMAXPROCS(0) {
+		c.next++
+		return c.shards[int(c.next)%len(c.shards)]
+	}
+
+	s := &counterShard{}
+	c.shards = append(c.shards, s)
+
+	return s
+}
+
+// load returns the sum of counter's shards.
+func (c *counter) load() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sum uint64
+	for _, s := range c.shards {
+		sum += atomic.LoadUint64(&s.n)
+	}
+
+	return sum
+}
+
+// Stats returns a snapshot of client's cumulative statistics.
+func (c *Client) Stats() Stats {
+	c.init()
+
+	s := Stats{
+		Truncations:        c.stats.truncations.load(),
+		DroppedEvents:      c.stats.droppedEvents.load(),
+		DroppedMirrors:     c.stats.droppedMirrors.load(),
+		Shed:               c.stats.shed.load(),
+		Retries:            c.stats.retries.load(),
+		LimitedRetries:     c.stats.limitedRetries.load(),
+		ConnectionFailures: c.stats.connectionFailures.load(),
+		ResponseFailures:   c.stats.responseFailures.load(),
+		Hosts:              make(map[string]HostStats),
+		Labels:             make(map[string]HostStats),
+	}
+
+	var limits map[string]int
+	if c.limiter != nil {
+		limits = c.limiter.limits()
+	}
+
+	c.stats.hosts.Range(func(key, value interface{}) bool {
+		host, h := key.(string), value.(*hostCounters)
+		s.Hosts[host] = HostStats{
+			Attempts:         atomic.LoadUint64(&h.attempts),
+			Latency:          time.Duration(atomic.LoadInt64(&h.latency)),
+			ErrorRate:        math.Float64frombits(atomic.LoadUint64(&h.errorRate)),
+			ConcurrencyLimit: limits[host],
+			Region:           h.region,
+		}
+
+		return true
+	})
+	c.stats.labels.Range(func(key, value interface{}) bool {
+		h := value.(*hostCounters)
+		s.Labels[key.(string)] = HostStats{
+			Attempts:  atomic.LoadUint64(&h.attempts),
+			Latency:   time.Duration(atomic.LoadInt64(&h.latency)),
+			ErrorRate: math.Float64frombits(atomic.LoadUint64(&h.errorRate)),
+		}
+
+		return true
+	})
+
+	return s
+}
+
+// recordHost updates statistics of host of call's current attempt with its latency and error.
+func (c *Client) recordHost(cl *call, err error) {
+	if cl.req == nil {
+		return
+	}
+
+	var failure float64
+	if err != nil {
+		failure = 1
+	}
+
+	var region string
+	if cl.endpoint != nil {
+		region = cl.endpoint.region
+	}
+
+	record(&c.stats.hosts, cl.req.URL.Host, cl.latency, failure, region)
+	for k, v := range cl.labels {
+		record(&c.stats.labels, k+"="+v, cl.latency, failure, "")
+	}
+}
+
+// record updates statistics of provided key in provided counters with provided latency and failure of an attempt.
+func record(counters *sync.Map, key string, latency time.Duration, failure float64, region string) {
+	value, ok := counters.Load(key)
+	if !ok {
+		value, ok = counters.LoadOrStore(key, &hostCounters{
+			attempts:  1,
+			latency:   int64(latency),
+			errorRate: math.Float64bits(failure),
+			region:    region,
+		})
+		if !ok {
+			return
+		}
+	}
+	h := value.(*hostCounters)
+
+	atomic.AddUint64(&h.attempts, 1)
+	for {
+		old := atomic.LoadInt64(&h.latency)
+		if atomic.CompareAndSwapInt64(&h.latency, old, old+int64(ewmaWeight*float64(int64(latency)-old))) {
+			break
+		}
+	}
+	for {
+		old := atomic.LoadUint64(&h.errorRate)
+		rate := math.Float64frombits(old)
+		if atomic.CompareAndSwapUint64(&h.errorRate, old, math.Float64bits(rate+ewmaWeight*(failure-rate))) {
+			break
+		}
+	}
+}
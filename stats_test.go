@@ -0,0 +1,85 @@
+package retryablehttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// Stats method should report per-host attempt counts and weighted error rates.
+func TestHostStats(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	if _, err := c.Do(req); err != nil {
+		t.Errorf("sending request failed, %s", err.Error())
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Errorf("parsing url failed, %s", err.Error())
+	}
+
+	hostStats, ok := c.Stats().Hosts[u.Host]
+	if !ok {
+		t.Fatal("missing host stats")
+	}
+	if hostStats.Attempts != 2 || hostStats.ErrorRate != 1-ewmaWeight || hostStats.Latency <= 0 {
+		t.Errorf("unexpected host stats, %+v", hostStats)
+	}
+}
+
+// add and load methods of a counter should count concurrent increments.
+func TestCounter(t *testing.T) {
+	var c counter
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				c.add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := c.load(); n != 5000 {
+		t.Errorf("unexpected count, %d", n)
+	}
+	if len(c.shards) > runtime.GOMAXPROCS(0) {
+		t.Errorf("unexpected shard count, %d", len(c.shards))
+	}
+}
+
+// BenchmarkCounter measures increments of a counter shared by parallel goroutines.
+func BenchmarkCounter(b *testing.B) {
+	var c counter
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.add(1)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package retryablehttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// stream errors
+var (
+	ErrNilStreamHandler = errors.New("stream handler is nil")
+)
+
+// DoJSONStream sends provided request with automatic retries and decodes response body incrementally, calling provided handler with each value
+// without buffering the whole body. The body may be a JSON array, whose elements are decoded, or a sequence of JSON values such as NDJSON.
+// When the stream breaks while it is read, idempotent requests are re-sent and values already handled are skipped. Re-sent requests and their retries
+// share a single budget of client's maximum request count, so a stream makes at most that many attempts in total.
+// It returns the number of handled values and stops with the error of handler when handler fails. Requests with a body must have GetBody function.
+// Values are passed to a callback rather than returned through an iterator or a channel: range-over-func iterators require a newer Go version than
+// the module supports, and a channel would need a goroutine which leaks when the caller stops receiving and could not carry errors of the stream.
+func DoJSONStream[T any](c *Client, req *http.Request, handler func(v T) error) (int, error) {
+	if handler == nil {
+		return 0, ErrNilStreamHandler
+	}
+
+	p, _ := c.policy(req.Method, req.URL)
+	remaining, _, _ := p.retrySettings()
+
+	handled := 0
+	for {
+		attemptReq, err := cloneRequest(contextWithAttemptLimit(req.Context(), remaining), req)
+		if err != nil {
+			return handled, err
+		}
+
+		res, report, err := c.DoWithReport(attemptReq)
+		remaining -= len(report.Attempts)
+		if err != nil {
+			discard(res)
+			return handled, err
+		}
+
+		skip := handled
+		err = decodeJSONStream(res.Body, func(raw json.RawMessage) error {
+			if skip > 0 {
+				skip--
+				return nil
+			}
+
+			var v T
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return Permanent(err)
+			}
+			if err := handler(v); err != nil {
+				return Permanent(err)
+			}
+			handled++
+
+			return nil
+		})
+		discard(res)
+
+		if err == nil {
+			return handled, nil
+		}
+
+		var permanentErr *PermanentError
+		if errors.As(err, &permanentErr) {
+			return handled, permanentErr.Err
+		}
+
+		if !idempotent(req.Method) || remaining <= 0 || req.Context().Err() != nil {
+			return handled, err
+		}
+	}
+}
+
+// decodeJSONStream decodes values of a JSON array or a sequence of JSON values from provided reader and calls provided function with each raw value.
+// Syntax errors and errors of provided function are permanent, read errors are returned as they are.
+func decodeJSONStream(r io.Reader, fn func(raw json.RawMessage) error) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(br)
+	if first == '[' {
+		if _, err := d.Token(); err != nil {
+			return streamError(err)
+		}
+	}
+
+	for first != '[' || d.More() {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			if err == io.EOF && first != '[' {
+				return nil
+			}
+
+			return streamError(err)
+		}
+
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+
+	if _, err := d.Token(); err != nil {
+		return streamError(err)
+	}
+
+	return nil
+}
+
+// peekNonSpace skips JSON white space of provided reader and returns the next byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b, br.UnreadByte()
+	}
+}
+
+// streamError returns provided decoding error, syntax errors are permanent since re-sending the request does not fix them.
+func streamError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+// idempotent reports whether requests with provided method can be sent more than once without side effects.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
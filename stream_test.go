@@ -0,0 +1,167 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// item is a value of streamed test payloads.
+type item struct {
+	ID int `json:"id"`
+}
+
+// DoJSONStream function should decode JSON arrays and NDJSON payloads value by value.
+func TestDoJSONStream(t *testing.T) {
+	payloads := map[string]string{
+		"array":  `[{"id":1}, {"id":2}, {"id":3}]`,
+		"ndjson": "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+		"empty":  `[]`,
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payloads[r.URL.Query().Get("format")]))
+	}))
+	defer s.Close()
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for format, expected := range map[string][]int{"array": {1, 2, 3}, "ndjson": {1, 2, 3}, "empty": nil} {
+		req, err := http.NewRequest(http.MethodGet, s.URL+"?format="+format, http.NoBody)
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		var ids []int
+		n, err := DoJSONStream(c, req, func(v item) error {
+			ids = append(ids, v.ID)
+			return nil
+		})
+		if err != nil || n != len(expected) || !reflect.DeepEqual(ids, expected) {
+			t.Errorf("unexpected %s stream, %v, %d, %v", format, ids, n, err)
+		}
+	}
+}
+
+// DoJSONStream function should reconnect broken streams of idempotent requests, skipping values already handled.
+func TestDoJSONStreamReconnect(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		body := `[{"id":1},{"id":2},{"id":3}]`
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if reqCount == 1 {
+			body = body[:15]
+		}
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		reqCount = 0
+		req, err := http.NewRequest(method, s.URL, strings.NewReader("{}"))
+		if err != nil {
+			t.Errorf("creating http request failed, %s", err.Error())
+		}
+
+		var ids []int
+		n, err := DoJSONStream(c, req, func(v item) error {
+			ids = append(ids, v.ID)
+			return nil
+		})
+
+		if method == http.MethodGet && (err != nil || n != 3 || !reflect.DeepEqual(ids, []int{1, 2, 3}) || reqCount != 2) {
+			t.Errorf("unexpected reconnected stream, %v, %v, %d", ids, err, reqCount)
+		}
+		if method == http.MethodPost && (err == nil || n != 1 || reqCount != 1) {
+			t.Errorf("unexpected broken stream, %v, %v, %d", ids, err, reqCount)
+		}
+	}
+}
+
+// DoJSONStream function should share client's maximum request count between reconnects and retries of each reconnect.
+func TestDoJSONStreamAttemptBudget(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body := `[{"id":1},{"id":2},{"id":3}]`
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write([]byte(body[:15]))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	n, err := DoJSONStream(c, req, func(v item) error {
+		return nil
+	})
+	if err == nil || n != 1 || reqCount != 3 {
+		t.Errorf("unexpected stream, %d, %v, %d", n, err, reqCount)
+	}
+}
+
+// DoJSONStream function should stop with handler's error and should not retry syntax errors.
+func TestDoJSONStreamErrors(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Write([]byte(`[{"id":1}, {"id":2} {"id":3}]`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	errStop := errors.New("stop")
+	if n, err := DoJSONStream(c, req, func(v item) error { return errStop }); err != errStop || n != 0 {
+		t.Errorf("unexpected handler error, %v, %d", err, n)
+	}
+
+	if _, err := DoJSONStream[item](c, req, nil); err != ErrNilStreamHandler {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	reqCount = 0
+	n, err := DoJSONStream(c, req, func(v item) error { return nil })
+	if err == nil || n != 2 || reqCount != 1 {
+		t.Errorf("unexpected syntax error, %v, %d, %d", err, n, reqCount)
+	}
+}
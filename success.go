@@ -0,0 +1,46 @@
+package retryablehttp
+
+import (
+	"errors"
+)
+
+// success predicate errors
+var (
+	ErrNilSuccessPredicate = errors.New("success predicate is nil")
+)
+
+// WithSuccessPredicate configures typed helpers, such as DoJSON, DoAndDecode and Resource methods, to check values of type T decoded from
+// successful responses with provided predicate, so that responses like HTTP 200 with an error status in the body fail. Predicate errors
+// are retried unless they are wrapped with Permanent. Predicates of several types can be configured, only those of the decoded type are called.
+func WithSuccessPredicate[T any](predicate func(v T) error) Option {
+	return named("WithSuccessPredicate", func(c *Client) error {
+		if predicate == nil {
+			return ErrNilSuccessPredicate
+		}
+
+		check := func(out interface{}) error {
+			v, ok := out.(*T)
+			if !ok {
+				return nil
+			}
+
+			return predicate(*v)
+		}
+		c.successPredicates = append(c.successPredicates[:len(c.successPredicates):len(c.successPredicates)], check)
+
+		return nil
+	})
+}
+
+// checkSuccess runs client's success predicates on provided decoded output.
+func (c *Client) checkSuccess(out interface{}) error {
+	for _, check := range c.successPredicates {
+		if err := c.safely(func() error {
+			return check(out)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// successStatus is a response body reporting its status.
+type successStatus struct {
+	Status string `json:"status"`
+}
+
+// NewClient function should return ErrNilSuccessPredicate naming the option when nil success predicate is provided.
+func TestNilSuccessPredicateOption(t *testing.T) {
+	_, err := NewClient(WithSuccessPredicate[successStatus](nil))
+
+	var optionErr *OptionError
+	if !errors.Is(err, ErrNilSuccessPredicate) || !errors.As(err, &optionErr) || optionErr.Option != "WithSuccessPredicate" {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// GetJSON method of a client with a success predicate should retry decoded values failing the predicate and stop on permanent failures.
+func TestSuccessPredicate(t *testing.T) {
+	var statuses []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		statuses = statuses[1:]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"` + status + `"}`))
+	}))
+	defer s.Close()
+
+	errPending := errors.New("pending")
+	errRejected := errors.New("rejected")
+	otherCalls := 0
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(time.Millisecond),
+		WithSuccessPredicate(func(v successStatus) error {
+			switch v.Status {
+			case "pending":
+				return errPending
+			case "rejected":
+				return Permanent(errRejected)
+			}
+
+			return nil
+		}),
+		WithSuccessPredicate(func(v []successStatus) error {
+			otherCalls++
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	statuses = []string{"pending", "ok"}
+	var out successStatus
+	if err := c.GetJSON(context.Background(), s.URL, &out); err != nil || out.Status != "ok" || len(statuses) != 0 {
+		t.Errorf("unexpected result, %v, %s, %v", err, out.Status, statuses)
+	}
+
+	statuses = []string{"rejected", "ok"}
+	err = c.GetJSON(context.Background(), s.URL, &out)
+	if !errors.Is(err, errRejected) || len(statuses) != 1 {
+		t.Errorf("unexpected result, %v, %v", err, statuses)
+	}
+	if otherCalls != 0 {
+		t.Errorf("predicate of another type is called")
+	}
+}
@@ -0,0 +1,145 @@
+package retryablehttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// trace errors
+var (
+	ErrNilTracePropagator = errors.New("trace propagator is nil")
+	ErrInvalidTraceparent = errors.New("invalid traceparent")
+)
+
+// trace context headers, see W3C Trace Context.
+const (
+	TraceparentHeader = "Traceparent"
+	TracestateHeader  = "Tracestate"
+)
+
+// traceContextKey is the context key of trace contexts.
+type traceContextKey struct{}
+
+// TraceContext represents a W3C trace context.
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   string
+}
+
+// IsValid reports whether trace context has non-zero trace and span ids.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// Traceparent returns traceparent header value of trace context.
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:]), tc.Flags)
+}
+
+// ParseTraceparent parses provided traceparent header value, it returns ErrInvalidTraceparent when value is malformed.
+func ParseTraceparent(value string) (TraceContext, error) {
+	var tc TraceContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return tc, ErrInvalidTraceparent
+	}
+
+	var flags [1]byte
+	if !decodeHex(tc.TraceID[:], parts[1]) || !decodeHex(tc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) || !tc.IsValid() {
+		return TraceContext{}, ErrInvalidTraceparent
+	}
+	tc.Flags = flags[0]
+
+	return tc, nil
+}
+
+// decodeHex decodes provided lower case hex string into dst, it reports whether string has exactly len(dst) bytes.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+
+	return err == nil
+}
+
+// ContextWithTraceContext returns a copy of provided context carrying provided trace context.
+// Attempts of requests using returned context are sent as children of provided trace context.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns trace context carried by provided context.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+
+	return tc, ok && tc.IsValid()
+}
+
+// TracePropagator represents an injector which writes trace context of provided context into headers of an attempt.
+type TracePropagator interface {
+	Inject(ctx context.Context, header http.Header)
+}
+
+// W3CTracePropagator injects traceparent and tracestate headers.
+type W3CTracePropagator struct{}
+
+// Inject writes traceparent and tracestate headers of trace context carried by provided context.
+func (W3CTracePropagator) Inject(ctx context.Context, header http.Header) {
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	header.Set(TraceparentHeader, tc.Traceparent())
+	if tc.State != "" {
+		header.Set(TracestateHeader, tc.State)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+// WithTracePropagator configures client to propagate trace context onto every attempt using provided propagator, W3CTracePropagator can be used.
+// Each attempt is sent with a new span id as a child of the trace context of request's context, or of request's traceparent header.
+// When neither exists, a new sampled trace is started for the request and shared by its attempts. Trace context is not propagated by default.
+func WithTracePropagator(p TracePropagator) Option {
+	return named("WithTracePropagator", func(c *Client) error {
+		if p == nil {
+			return ErrNilTracePropagator
+		}
+
+		c.tracePropagator = p
+
+		return nil
+	})
+}
+
+// attemptTrace returns a copy of provided context carrying trace context of call's current attempt.
+func (c *Client) attemptTrace(cl *call, req *http.Request) context.Context {
+	if cl.trace == nil {
+		cl.trace = &TraceContext{}
+		if tc, ok := TraceContextFromContext(req.Context()); ok {
+			*cl.trace = tc
+		} else if tc, err := ParseTraceparent(req.Header.Get(TraceparentHeader)); err == nil {
+			tc.State = req.Header.Get(TracestateHeader)
+			*cl.trace = tc
+		} else {
+			rand.Read(cl.trace.TraceID[:])
+			cl.trace.Flags = 1
+		}
+	}
+
+	tc := *cl.trace
+	rand.Read(tc.SpanID[:])
+
+	return ContextWithTraceContext(req.Context(), tc)
+}
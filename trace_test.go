@@ -0,0 +1,129 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ParseTraceparent function should parse valid traceparent values and reject malformed ones.
+func TestParseTraceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tc, err := ParseTraceparent(value)
+	if err != nil {
+		t.Errorf("parsing traceparent failed, %s", err.Error())
+	}
+	if tc.Traceparent() != value {
+		t.Errorf("unexpected traceparent, %s", tc.Traceparent())
+	}
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(value); err != ErrInvalidTraceparent {
+			t.Errorf("unexpected error for %q, %v", value, err)
+		}
+	}
+}
+
+// NewClient function should return ErrNilTracePropagator when nil trace propagator is provided.
+func TestNilTracePropagatorOption(t *testing.T) {
+	_, err := NewClient(
+		WithTracePropagator(nil),
+	)
+	if !errors.Is(err, ErrNilTracePropagator) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with trace propagator should send every attempt as a new span of context's trace.
+func TestTracePropagation(t *testing.T) {
+	var received []TraceContext
+	var states []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc, err := ParseTraceparent(r.Header.Get(TraceparentHeader))
+		if err != nil {
+			t.Errorf("parsing traceparent failed, %s", err.Error())
+		}
+		received = append(received, tc)
+		states = append(states, r.Header.Get(TracestateHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithTracePropagator(W3CTracePropagator{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	parent, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Errorf("parsing traceparent failed, %s", err.Error())
+	}
+	parent.State = "vendor=value"
+
+	req, err := http.NewRequestWithContext(ContextWithTraceContext(context.Background(), parent), http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if len(received) != 2 {
+		t.Fatalf("unexpected number of attempts, %d", len(received))
+	}
+	for i, tc := range received {
+		if tc.TraceID != parent.TraceID || tc.SpanID == parent.SpanID || tc.Flags != parent.Flags || states[i] != parent.State {
+			t.Errorf("unexpected trace context of attempt %d, %s", i+1, tc.Traceparent())
+		}
+	}
+	if received[0].SpanID == received[1].SpanID {
+		t.Error("attempts share span id")
+	}
+	if req.Header.Get(TraceparentHeader) != "" {
+		t.Error("provided request is modified")
+	}
+}
+
+// Do method of a client with trace propagator should start a new trace shared by attempts when request has no trace context.
+func TestTracePropagationNewTrace(t *testing.T) {
+	var received []TraceContext
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc, err := ParseTraceparent(r.Header.Get(TraceparentHeader))
+		if err != nil {
+			t.Errorf("parsing traceparent failed, %s", err.Error())
+		}
+		received = append(received, tc)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithTracePropagator(W3CTracePropagator{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	c.Do(req)
+
+	if len(received) != 2 || received[0].TraceID != received[1].TraceID || received[0].SpanID == received[1].SpanID {
+		t.Errorf("unexpected trace contexts, %v", received)
+	}
+}
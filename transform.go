@@ -0,0 +1,56 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// response transformer errors
+var (
+	ErrNilResTransformer = errors.New("response transformer is nil")
+)
+
+// ResTransformer transforms a successful response, such as unwrapping an envelope or normalizing headers, it returns the transformed response.
+// A transformer replacing response's body is responsible for closing the body it replaces.
+type ResTransformer func(res *http.Response) (*http.Response, error)
+
+// WithResTransformers configures client to transform responses accepted by response handler and response validator, sent with Do and methods built on it,
+// with provided transformers in order before they are returned to the caller. Transformers of repeated options run after the ones configured earlier.
+// Transformation errors are retried unless they are wrapped with Permanent. Responses are not transformed by default.
+func WithResTransformers(transformers ...ResTransformer) Option {
+	return named("WithResTransformers", func(c *Client) error {
+		for _, t := range transformers {
+			if t == nil {
+				return ErrNilResTransformer
+			}
+		}
+
+		c.resTransformers = append(c.resTransformers[:len(c.resTransformers):len(c.resTransformers)], transformers...)
+
+		return nil
+	})
+}
+
+// transform runs client's response transformers on provided response of call's current attempt, recording the transformed response in call state.
+// When a transformer fails, the response passed to it is returned with its error.
+func (c *Client) transform(cl *call, res *http.Response) (*http.Response, error) {
+	for _, t := range c.resTransformers {
+		var next *http.Response
+		if err := c.safely(func() error {
+			var err error
+			next, err = t(res)
+
+			return err
+		}); err != nil {
+			return res, err
+		}
+		if next == nil {
+			return res, Permanent(ErrNilRes)
+		}
+
+		res = next
+		cl.res = res
+	}
+
+	return res, nil
+}
@@ -0,0 +1,110 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrNilResTransformer when nil response transformer is provided.
+func TestNilResTransformerOption(t *testing.T) {
+	if _, err := NewClient(WithResTransformers(nil)); !errors.Is(err, ErrNilResTransformer) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with response transformers should transform successful responses in order and retry transformation errors.
+func TestResTransformers(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.Write([]byte(`{"data"`))
+			return
+		}
+		w.Write([]byte(`{"data":{"id":1}}`))
+	}))
+	defer s.Close()
+
+	unwrap := func(res *http.Response) (*http.Response, error) {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, err
+		}
+		res.Body = io.NopCloser(bytes.NewReader(envelope.Data))
+		res.ContentLength = int64(len(envelope.Data))
+
+		return res, nil
+	}
+	var order []string
+	label := func(name string) ResTransformer {
+		return func(res *http.Response) (*http.Response, error) {
+			order = append(order, name)
+			res.Header.Set("X-Transformed", name)
+
+			return res, nil
+		}
+	}
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithResTransformers(unwrap, label("first")),
+		WithResTransformers(label("second")),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Fatalf("sending request failed, %s", err.Error())
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != `{"id":1}` || res.Header.Get("X-Transformed") != "second" {
+		t.Errorf("unexpected response, %s, %s", body, res.Header.Get("X-Transformed"))
+	}
+	if reqCount != 2 || len(order) != 2 || order[0] != "first" {
+		t.Errorf("unexpected request count or order, %d, %v", reqCount, order)
+	}
+}
+
+// Do method of a client with response transformers should not retry permanent transformation errors.
+func TestResTransformerPermanentError(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+	}))
+	defer s.Close()
+
+	errUnsupported := errors.New("unsupported envelope")
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithResTransformers(func(res *http.Response) (*http.Response, error) {
+			return nil, Permanent(errUnsupported)
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if !errors.Is(err, errUnsupported) || reqCount != 1 {
+		t.Errorf("unexpected error or request count, %v, %d", err, reqCount)
+	}
+	discard(res)
+}
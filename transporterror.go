@@ -0,0 +1,146 @@
+package retryablehttp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// TransportPhase represents the phase of an attempt a transport failure occurred in.
+type TransportPhase string
+
+// transport phases
+const (
+	PhaseUnknown      TransportPhase = "unknown"
+	PhaseDNS          TransportPhase = "dns"
+	PhaseConnect      TransportPhase = "connect"
+	PhaseTLSHandshake TransportPhase = "tls_handshake"
+	PhaseWrite        TransportPhase = "write"
+	PhaseReadHeaders  TransportPhase = "read_headers"
+	PhaseReadBody     TransportPhase = "read_body"
+)
+
+// phases holds transport phases in the order an attempt goes through them, indexed by phase progress recorded in call state.
+var phases = [...]TransportPhase{PhaseUnknown, PhaseDNS, PhaseConnect, PhaseTLSHandshake, PhaseWrite, PhaseReadHeaders}
+
+// phase progress of an attempt
+const (
+	progressUnknown int32 = iota
+	progressDNS
+	progressConnect
+	progressTLSHandshake
+	progressWrite
+	progressReadHeaders
+)
+
+// TransportError represents a failure of an attempt without a response, or a failure reading a response body.
+// Phase is the phase the failure occurred in and Written reports whether request headers were written, so whether the server may have seen the request.
+type TransportError struct {
+	Phase   TransportPhase
+	Written bool
+	Err     error
+}
+
+// Error returns error message including phase and underlying error.
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s failed, %s", e.Phase, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// WithTransportErrors configures client to wrap transport failures of attempts, and failures reading response bodies, in *TransportError.
+// Phases are traced with httptrace, failures of transports which do not support tracing are classified by their errors where possible.
+// Transport failures are returned as they are by default, since tracing allocates.
+func WithTransportErrors() Option {
+	return named("WithTransportErrors", func(c *Client) error {
+		c.transportErrors = true
+
+		return nil
+	})
+}
+
+// tracePhases returns a shallow copy of provided request which records its phase progress, and when its headers are written, in provided progress.
+func tracePhases(p *progress, req *http.Request) *http.Request {
+	progress := func(phase int32) {
+		atomic.StoreInt32(&p.phase, phase)
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			progress(progressDNS)
+		},
+		ConnectStart: func(string, string) {
+			progress(progressConnect)
+		},
+		TLSHandshakeStart: func() {
+			progress(progressTLSHandshake)
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			progress(progressWrite)
+		},
+		WroteHeaders: func() {
+			atomic.StoreInt32(&p.wrote, 1)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			progress(progressReadHeaders)
+		},
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// transportError returns provided transport failure of call's current attempt wrapped in *TransportError.
+func (cl *call) transportError(err error) error {
+	phase := PhaseUnknown
+	if cl.progress != nil {
+		phase = phases[atomic.LoadInt32(&cl.progress.phase)]
+	}
+	if phase == PhaseUnknown {
+		phase = failurePhase(err)
+	}
+
+	return &TransportError{Phase: phase, Written: cl.progress.written(), Err: err}
+}
+
+// failurePhase returns the phase of provided transport failure classified by its error, it returns PhaseUnknown when the error does not tell.
+func failurePhase(err error) TransportPhase {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &dnsErr):
+		return PhaseDNS
+	case errors.As(err, &recordErr), errors.As(err, &unknownAuthorityErr), errors.As(err, &invalidErr), errors.As(err, &hostnameErr):
+		return PhaseTLSHandshake
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return PhaseConnect
+	}
+
+	return PhaseUnknown
+}
+
+// phaseBody is a response body which wraps read failures in *TransportError.
+type phaseBody struct {
+	io.ReadCloser
+}
+
+// Read reads body, wrapping failures other than end of body.
+func (b *phaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = &TransportError{Phase: PhaseReadBody, Written: true, Err: err}
+	}
+
+	return n, err
+}
@@ -0,0 +1,93 @@
+package retryablehttp
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Do method of a client with transport errors should wrap failures in *TransportError with the phase they occurred in.
+func TestTransportErrors(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	hangUp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer hangUp.Close()
+
+	tests := []struct {
+		url     string
+		phase   TransportPhase
+		written bool
+	}{
+		{closed.URL, PhaseConnect, false},
+		{hangUp.URL, PhaseReadHeaders, true},
+	}
+
+	for _, test := range tests {
+		c, err := NewClient(
+			WithTransportErrors(),
+			WithHTTPClient(&http.Client{Transport: &http.Transport{}}),
+		)
+		if err != nil {
+			t.Errorf("creating client failed, %s", err.Error())
+		}
+
+		_, err = c.Get(test.url)
+		var transportErr *TransportError
+		if !errors.As(err, &transportErr) {
+			t.Fatalf("unexpected error, %v", err)
+		}
+		if transportErr.Phase != test.phase || transportErr.Written != test.written {
+			t.Errorf("unexpected transport error, %s, %t", transportErr.Phase, transportErr.Written)
+		}
+	}
+}
+
+// Body of a response received by a client with transport errors should wrap read failures in *TransportError.
+func TestTransportErrorReadBody(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, _ := w.(http.Hijacker).Hijack()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\nabc")
+		rw.Flush()
+		conn.Close()
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithTransportErrors(),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Fatalf("sending request failed, %s", err.Error())
+	}
+	defer res.Body.Close()
+
+	_, err = io.ReadAll(res.Body)
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) || transportErr.Phase != PhaseReadBody || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// failurePhase function should classify failures of transports which do not support tracing by their errors.
+func TestFailurePhase(t *testing.T) {
+	if phase := failurePhase(&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}); phase != PhaseDNS {
+		t.Errorf("unexpected phase, %s", phase)
+	}
+	if phase := failurePhase(&net.OpError{Op: "dial", Err: errors.New("connection refused")}); phase != PhaseConnect {
+		t.Errorf("unexpected phase, %s", phase)
+	}
+	if phase := failurePhase(io.EOF); phase != PhaseUnknown {
+		t.Errorf("unexpected phase, %s", phase)
+	}
+}
@@ -0,0 +1,267 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DoCodec encodes provided input with codec registered for provided content type and sends it with automatic retries.
+// Body of successful response is decoded into provided output with codec chosen by response's Content-Type header, or with codec of the request when it has none.
+// Responses whose content type is not accepted by client's WithAccept types fail with ErrUnacceptableContentType.
+// Input is not sent when it is nil and response body is not decoded when output is nil.
+// Decode failures and unsupported response content types are not retried, unless retrying syntactically invalid JSON is enabled.
+func (c *Client) DoCodec(ctx context.Context, method, url, contentType string, in, out interface{}) error {
+	if p := c.policyURL(method, url); p != c {
+		return p.DoCodec(ctx, method, url, contentType, in, out)
+	}
+
+	codecs := c.codecRegistry()
+
+	codec, ok := codecs.Lookup(contentType)
+	if !ok {
+		return fmt.Errorf("%w, %s", ErrUnsupportedContentType, contentType)
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = codec.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	accept := c.acceptHeader
+	if accept == "" {
+		accept = acceptHeader(codec.ContentType(), codecs.ContentTypes())
+	}
+
+	var reqBody io.Reader = http.NoBody
+	if in != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", codec.ContentType())
+	}
+	req.Header.Set("Accept", accept)
+
+	res, err := c.pipeline(req, nil, &exchange{consume: func(res *http.Response, err error) error {
+		if err != nil || out == nil {
+			return err
+		}
+
+		resBody, err := c.bufferBody(res)
+		if err != nil {
+			return err
+		}
+
+		return c.decode(codecs, codec, res, resBody, out)
+	}})
+	discard(res)
+
+	return err
+}
+
+// DoJSON encodes provided input as JSON, sends it with automatic retries and decodes successful response into provided output.
+func (c *Client) DoJSON(ctx context.Context, method, url string, in, out interface{}) error {
+	return c.DoCodec(ctx, method, url, ContentTypeJSON, in, out)
+}
+
+// GetJSON sends a GET request with automatic retries and decodes successful JSON response into provided output.
+func (c *Client) GetJSON(ctx context.Context, url string, out interface{}) error {
+	return c.DoCodec(ctx, http.MethodGet, url, ContentTypeJSON, nil, out)
+}
+
+// DoAndDecode sends provided request with automatic retries and decodes body of successful response into provided output
+// with codec chosen by response's Content-Type header from client's codec registry. Response body is buffered within client's body memory limits.
+// An Accept header listing registered content types, or client's WithAccept types, is set when request has none.
+// Decode failures are classified like those of DoCodec, responses without a supported content type are not retried.
+func (c *Client) DoAndDecode(req *http.Request, out interface{}) error {
+	if p, _ := c.policy(req.Method, req.URL); p != c {
+		return p.DoAndDecode(req, out)
+	}
+
+	codecs := c.codecRegistry()
+	if req.Header.Get("Accept") == "" {
+		accept := c.acceptHeader
+		if accept == "" {
+			accept = strings.Join(codecs.ContentTypes(), ", ")
+		}
+		req = req.WithContext(req.Context())
+		req.Header = req.Header.Clone()
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header.Set("Accept", accept)
+	}
+
+	res, err := c.pipeline(req, nil, &exchange{consume: func(res *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+
+		body, err := c.bufferBody(res)
+		if err != nil {
+			return err
+		}
+
+		return c.decode(codecs, nil, res, body, out)
+	}})
+	discard(res)
+
+	return err
+}
+
+// decode decodes provided body of provided response into provided output with codec chosen by response's content type, or provided request codec
+// when response has no content type, and checks decoded output with client's success predicates. Syntactically invalid JSON is retried when client
+// retries invalid JSON, other failures are permanent.
+func (c *Client) decode(codecs *CodecRegistry, reqCodec Codec, res *http.Response, body []byte, out interface{}) error {
+	codec, err := c.responseCodec(codecs, reqCodec, res.Header.Get("Content-Type"))
+	if err != nil {
+		return Permanent(err)
+	}
+
+	err = codec.Unmarshal(body, out)
+	if err == nil && c.successPredicates != nil {
+		return c.checkSuccess(out)
+	}
+	if c.retryOnInvalidJSON && isInvalidJSON(err) {
+		return err
+	}
+
+	return Permanent(err)
+}
+
+// GetBytes sends a GET request with automatic retries and returns body of successful response.
+// Body is read within the retry loop, so failures reading it are retried, and it is limited by client's body memory limits.
+func (c *Client) GetBytes(ctx context.Context, url string) ([]byte, error) {
+	if p := c.policyURL(http.MethodGet, url); p != c {
+		return p.GetBytes(ctx, url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	res, err := c.pipeline(req, nil, &exchange{consume: func(res *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+
+		body, err = c.bufferBody(res)
+
+		return err
+	}})
+	discard(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// WithRetryOnInvalidJSON configures whether syntactically invalid JSON responses of typed helpers, such as truncated bodies, are retried instead of being returned as decode errors.
+// Type mismatches are never retried. Invalid JSON responses are not retried by default.
+func WithRetryOnInvalidJSON(retry bool) Option {
+	return named("WithRetryOnInvalidJSON", func(c *Client) error {
+		c.retryOnInvalidJSON = retry
+
+		return nil
+	})
+}
+
+// WithAccept configures Accept header of typed helpers to provided media types, in provided order and with their parameters, such as
+// "application/vnd.example.v2+json" for versioned APIs. Responses of typed helpers must have one of the types, wildcards such as "application/*" match
+// any subtype, and are decoded with codec registered for the returned type. Typed helpers accept registered content types, preferring the request's, by default.
+func WithAccept(types ...string) Option {
+	return named("WithAccept", func(c *Client) error {
+		if len(types) == 0 {
+			return ErrInvalidAccept
+		}
+
+		accepted := make([]string, 0, len(types))
+		for _, t := range types {
+			mt, _, err := mime.ParseMediaType(t)
+			if err != nil || !strings.Contains(mt, "/") {
+				return fmt.Errorf("%w, %s", ErrInvalidAccept, t)
+			}
+			accepted = append(accepted, mt)
+		}
+
+		c.acceptHeader = strings.Join(types, ", ")
+		c.acceptedTypes = accepted
+
+		return nil
+	})
+}
+
+// responseCodec returns codec decoding a response with provided content type, the request's codec is used when content type is empty.
+// Content type must be accepted by client's accepted types when they are configured.
+func (c *Client) responseCodec(codecs *CodecRegistry, reqCodec Codec, contentType string) (Codec, error) {
+	if contentType == "" && reqCodec != nil {
+		return reqCodec, nil
+	}
+
+	if c.acceptedTypes != nil && !accepts(c.acceptedTypes, mediaType(contentType)) {
+		return nil, fmt.Errorf("%w, %s", ErrUnacceptableContentType, contentType)
+	}
+
+	codec, ok := codecs.Lookup(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w, %s", ErrUnsupportedContentType, contentType)
+	}
+
+	return codec, nil
+}
+
+// accepts reports whether provided media type matches one of provided accepted media types, which may be wildcards.
+func accepts(accepted []string, mt string) bool {
+	for _, a := range accepted {
+		if a == mt || a == "*/*" || (strings.HasSuffix(a, "/*") && strings.HasPrefix(mt, a[:len(a)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInvalidJSON reports whether provided decode error is caused by syntactically invalid or truncated JSON.
+func isInvalidJSON(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// codecRegistry returns client's codec registry or DefaultCodecs.
+func (c *Client) codecRegistry() *CodecRegistry {
+	if c.codecs == nil {
+		return DefaultCodecs
+	}
+
+	return c.codecs
+}
+
+// acceptHeader builds an Accept header value preferring provided content type over other registered content types.
+func acceptHeader(preferred string, contentTypes []string) string {
+	values := []string{preferred}
+	for _, contentType := range contentTypes {
+		if contentType != preferred {
+			values = append(values, contentType+";q=0.9")
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
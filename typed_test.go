@@ -0,0 +1,267 @@
+package retryablehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// DoJSON method should encode input, retry unsuccessful responses and decode successful response.
+func TestDoJSON(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		var in map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("decoding request body failed, %s", err.Error())
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(map[string]string{"greeting": "hello " + in["name"]})
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out map[string]string
+	err = c.DoJSON(context.Background(), http.MethodPost, s.URL, map[string]string{"name": "gopher"}, &out)
+	if err != nil {
+		t.Errorf("doing json request failed, %s", err.Error())
+	}
+	if out["greeting"] != "hello gopher" {
+		t.Errorf("unexpected output, %v", out)
+	}
+}
+
+// GetJSON method should not retry responses with unsupported content type.
+func TestGetJSONWithUnsupportedContentType(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte("<greeting/>"))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out map[string]string
+	err = c.GetJSON(context.Background(), s.URL, &out)
+	if !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// GetJSON method of a client configured to retry invalid json should retry truncated responses but not type mismatches.
+func TestGetJSONWithRetryOnInvalidJSON(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Header().Set("Content-Type", "application/json")
+		if reqCount == 1 {
+			w.Write([]byte(`{"name":"gop`))
+
+			return
+		}
+
+		w.Write([]byte(`{"name":1}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithRetryOnInvalidJSON(true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	err = c.GetJSON(context.Background(), s.URL, &out)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// NewClient function should return ErrInvalidAccept when no media type or an invalid media type is provided.
+func TestInvalidAcceptOption(t *testing.T) {
+	for _, opt := range []Option{WithAccept(), WithAccept("json")} {
+		if _, err := NewClient(opt); !errors.Is(err, ErrInvalidAccept) {
+			t.Errorf("unexpected error, %v", err)
+		}
+	}
+}
+
+// GetJSON method of a client with accepted types should send them in Accept header, decode accepted responses and reject other content types.
+func TestGetJSONWithAccept(t *testing.T) {
+	contentType := "application/vnd.example.v2+json"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/vnd.example.v2+json, application/problem+json;q=0.5" {
+			t.Errorf("unexpected accept header, %s", r.Header.Get("Accept"))
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(`{"name":"gopher"}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithAccept("application/vnd.example.v2+json", "application/problem+json;q=0.5"),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out struct{ Name string }
+	if err := c.GetJSON(context.Background(), s.URL, &out); err != nil || out.Name != "gopher" {
+		t.Errorf("unexpected result, %v, %s", err, out.Name)
+	}
+
+	contentType = "application/json"
+	if err := c.GetJSON(context.Background(), s.URL, &out); !errors.Is(err, ErrUnacceptableContentType) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// GetBytes method should retry unsuccessful responses and return body of successful response.
+func TestGetBytes(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("body"))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	body, err := c.GetBytes(context.Background(), s.URL)
+	if err != nil || string(body) != "body" || reqCount != 2 {
+		t.Errorf("unexpected result, %q, %v, %d", body, err, reqCount)
+	}
+}
+
+// DoAndDecode method should set Accept header, retry invalid JSON when enabled and decode response with codec of its content type.
+func TestDoAndDecode(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if r.Header.Get("Accept") != "application/json, application/x-www-form-urlencoded" {
+			t.Errorf("unexpected accept header, %s", r.Header.Get("Accept"))
+		}
+
+		if reqCount == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("name=gopher"))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithRetryOnInvalidJSON(true),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		t.Errorf("creating request failed, %s", err.Error())
+	}
+
+	var out map[string]string
+	if err := c.DoAndDecode(req, &out); err != nil || out["name"] != "gopher" || reqCount != 2 {
+		t.Errorf("unexpected result, %v, %v, %d", err, out, reqCount)
+	}
+	if req.Header.Get("Accept") != "" {
+		t.Errorf("provided request is modified")
+	}
+}
+
+// GetJSON and GetBytes methods of a client with endpoints and response transformers should send requests to endpoints and transform responses.
+func TestTypedHelpersPipeline(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":7}}`))
+	}))
+	defer s.Close()
+
+	unwrap := func(res *http.Response) (*http.Response, error) {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		body, err := ReadAll(res, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, Permanent(err)
+		}
+		res.Body = io.NopCloser(bytes.NewReader(envelope.Data))
+
+		return res, nil
+	}
+
+	c, err := NewClient(
+		WithEndpoints("api.internal", s.URL),
+		WithResTransformers(unwrap),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var out struct {
+		ID int `json:"id"`
+	}
+	if err := c.GetJSON(context.Background(), "http://api.internal/items/7", &out); err != nil || out.ID != 7 {
+		t.Errorf("unexpected output %+v or error %v", out, err)
+	}
+
+	body, err := c.GetBytes(context.Background(), "http://api.internal/items/7")
+	if err != nil || string(body) != `{"id":7}` {
+		t.Errorf("unexpected body %s or error %v", body, err)
+	}
+}
@@ -0,0 +1,36 @@
+package retryablehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// validator errors
+var (
+	ErrNilResValidator = errors.New("response validator is nil")
+)
+
+// WithResValidator configures client's response validator function which validates responses accepted by response handler.
+// Validator receives a buffered copy of response body, response body is restored so it can be read again.
+// Validation errors are retried unless they are wrapped with Permanent.
+func WithResValidator(resValidator func(res *http.Response, body []byte) error) Option {
+	return named("WithResValidator", func(c *Client) error {
+		if resValidator == nil {
+			return ErrNilResValidator
+		}
+
+		c.resValidator = resValidator
+
+		return nil
+	})
+}
+
+// validate buffers provided response's body and runs client's response validator.
+func (c *Client) validate(res *http.Response) error {
+	body, err := c.bufferBody(res)
+	if err != nil {
+		return err
+	}
+
+	return c.resValidator(res, body)
+}
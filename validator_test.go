@@ -0,0 +1,105 @@
+package retryablehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewClient function should return ErrNilResValidator when nil response validator is provided.
+func TestNilResValidatorOption(t *testing.T) {
+	_, err := NewClient(
+		WithResValidator(nil),
+	)
+	if !errors.Is(err, ErrNilResValidator) {
+		t.Errorf("unexpected error, %v", err)
+	}
+}
+
+// Do method of a client with response validator should retry invalid payloads and return a readable body.
+func TestResValidatorWithRetryableError(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount == 1 {
+			w.Write([]byte(`{"name":`))
+
+			return
+		}
+
+		w.Write([]byte(`{"name":"gopher"}`))
+	}))
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(2),
+		WithResValidator(func(res *http.Response, body []byte) error {
+			if !json.Valid(body) {
+				return errors.New("invalid json")
+			}
+
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("doing http request failed, %s", err.Error())
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("reading response body failed, %s", err.Error())
+	}
+	if string(body) != `{"name":"gopher"}` {
+		t.Errorf("unexpected body, %s", body)
+	}
+	if reqCount != 2 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
+
+// Do method of a client with response validator should not retry permanent validation errors.
+func TestResValidatorWithPermanentError(t *testing.T) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		w.Write([]byte(`{"status":"error"}`))
+	}))
+	defer s.Close()
+
+	errStatus := errors.New("status error")
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithResValidator(func(res *http.Response, body []byte) error {
+			return Permanent(errStatus)
+		}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+
+	_, err = c.Do(req)
+	if !errors.Is(err, errStatus) {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if reqCount != 1 {
+		t.Errorf("unexpected request count, %d", reqCount)
+	}
+}
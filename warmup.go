@@ -0,0 +1,81 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// warmup errors
+var (
+	ErrInvalidKeepAliveInterval = errors.New("keep-alive interval must be greater than zero")
+)
+
+// Warmup establishes connections to provided urls concurrently by sending HEAD requests with automatic retries, so that
+// DNS resolution, TCP and TLS handshakes are done and connections are parked in the pool before first real requests.
+// Any response counts as a successful warmup, failures to connect are retried. It returns the error of the first url failing to warm up.
+func (c *Client) Warmup(ctx context.Context, urls ...string) error {
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		wg.Add(1)
+		go func(i int, rawURL string) {
+			defer wg.Done()
+
+			errs[i] = c.warmup(ctx, rawURL)
+		}(i, rawURL)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warmup establishes a connection to provided url.
+func (c *Client) warmup(ctx context.Context, rawURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	p, _ := c.policy(req.Method, req.URL)
+	cl := newCall(ctx, nil)
+	attempts, err := p.retry(cl, func() error {
+		res, err := p.send(cl, req)
+		discard(res)
+
+		return err
+	})
+	if err != nil {
+		return p.giveUp(cl, attempts, err)
+	}
+
+	return nil
+}
+
+// KeepAlive pings provided urls with HEAD requests, waiting provided interval with client's sleeper before each ping, until context is done.
+// It returns context's error.
+// Pings keep pooled connections warm and replace half-open connections before real requests fail on them. Failed pings are retried
+// and are reflected in host statistics of Stats. KeepAlive blocks, so it is usually run in its own goroutine.
+func (c *Client) KeepAlive(ctx context.Context, interval time.Duration, urls ...string) error {
+	if interval <= 0 {
+		return ErrInvalidKeepAliveInterval
+	}
+
+	for {
+		c.sleep(ctx, interval)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.Warmup(ctx, urls...)
+	}
+}
@@ -0,0 +1,167 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Warmup method of a client should send HEAD requests to every url and park connections regardless of response status.
+func TestWarmup(t *testing.T) {
+	var methods []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	var dials int
+	transport := s.Client().Transport.(*http.Transport).Clone()
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		return dial(ctx, network, addr)
+	}
+
+	c, err := NewClient(
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if err := c.Warmup(context.Background(), s.URL); err != nil {
+		t.Fatalf("unexpected error, %s", err.Error())
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("unexpected warmup requests, %v", methods)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		t.Errorf("creating http request failed, %s", err.Error())
+	}
+	c.Do(req)
+
+	if dials != 1 {
+		t.Errorf("unexpected dial count, %d", dials)
+	}
+}
+
+// Warmup method of a client should retry and return an error when a url cannot be connected.
+func TestWarmupFailure(t *testing.T) {
+	attempts := 0
+	c, err := NewClient(
+		WithMaxReqCount(3),
+		WithBackoff(0),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		})}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	var giveUpErr *GiveUpError
+	if err := c.Warmup(context.Background(), "http://example.com"); !errors.As(err, &giveUpErr) || giveUpErr.Attempts != 3 {
+		t.Errorf("unexpected error, %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("unexpected attempt count, %d", attempts)
+	}
+}
+
+// KeepAlive method of a client should ping urls every interval until context is done.
+func TestKeepAlive(t *testing.T) {
+	var pings int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			atomic.AddInt32(&pings, 1)
+		}
+	}))
+	defer s.Close()
+
+	c, err := NewClient()
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if err := c.KeepAlive(context.Background(), 0, s.URL); err != ErrInvalidKeepAliveInterval {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+	if err := c.KeepAlive(ctx, 10*time.Millisecond, s.URL); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	if n := atomic.LoadInt32(&pings); n < 3 || n > 5 {
+		t.Errorf("unexpected ping count, %d", n)
+	}
+	if c.Stats().Hosts[s.Listener.Addr().String()].Attempts == 0 {
+		t.Error("pings are not recorded in host statistics")
+	}
+}
+
+// cancelingSleeper is a fake sleeper which cancels a context after provided number of sleeps.
+type cancelingSleeper struct {
+	fakeClock
+	sleeps int
+	cancel context.CancelFunc
+}
+
+// Sleep advances fake current time and cancels the context when sleep count is reached.
+func (s *cancelingSleeper) Sleep(d time.Duration) {
+	s.fakeClock.Sleep(d)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sleeps--
+	if s.sleeps == 0 {
+		s.cancel()
+	}
+}
+
+// KeepAlive method of a client should wait intervals with client's sleeper.
+func TestKeepAliveSleeper(t *testing.T) {
+	var pings int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pings, 1)
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	sleeper := &cancelingSleeper{fakeClock: fakeClock{now: start}, sleeps: 4, cancel: cancel}
+	c, err := NewClient(
+		WithClock(sleeper),
+		WithSleeper(sleeper),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	if err := c.KeepAlive(ctx, time.Hour, s.URL); err != context.Canceled {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	if n := atomic.LoadInt32(&pings); n != 3 {
+		t.Errorf("unexpected ping count, %d", n)
+	}
+	if elapsed := sleeper.Now().Sub(start); elapsed != 4*time.Hour {
+		t.Errorf("unexpected elapsed fake time, %s", elapsed)
+	}
+}
@@ -0,0 +1,334 @@
+package retryablehttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// websocket errors
+var (
+	ErrNilWebSocketDialer = errors.New("websocket dialer is nil")
+	ErrBadHandshake       = errors.New("bad websocket handshake")
+)
+
+// websocketGUID is the globally unique identifier used for computing Sec-WebSocket-Accept header, see RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// HandshakeError represents a websocket opening handshake rejected by the server.
+type HandshakeError struct {
+	StatusCode int
+}
+
+// Error returns error message including status code.
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed with status code %d", e.StatusCode)
+}
+
+// Unwrap returns ErrBadHandshake.
+func (e *HandshakeError) Unwrap() error {
+	return ErrBadHandshake
+}
+
+// WebSocketDialer represents an underlying websocket dialer which performs a single opening handshake.
+// *websocket.Dialer of github.com/gorilla/websocket satisfies this interface.
+type WebSocketDialer[C any] interface {
+	DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (C, *http.Response, error)
+}
+
+// Dial performs websocket opening handshake using provided dialer with automatic retries, using client's maximum request count and backoff duration.
+// Handshake responses with status codes 408, 429 and 5xx and network failures are retried, other failures are permanent.
+// Responses of rejected handshakes are drained and closed before the next attempt, the last one is returned to the caller.
+// Handshakes are routed to client's canary and balanced endpoints like http requests.
+// Dial returns *GiveUpError wrapping last error when all attempts fail.
+func Dial[C any](ctx context.Context, c *Client, d WebSocketDialer[C], urlStr string, requestHeader http.Header) (C, *http.Response, error) {
+	var conn C
+	var res *http.Response
+	if d == nil {
+		return conn, nil, ErrNilWebSocketDialer
+	}
+	if p := c.policyURL(http.MethodGet, urlStr); p != c {
+		return Dial(ctx, p, d, urlStr, requestHeader)
+	}
+
+	// handshakes are routed to client's canary and endpoints like http requests, through a request to http form of the url
+	var routeReq *http.Request
+	if c.canary != nil || c.endpoints != nil {
+		var err error
+		if routeReq, err = webSocketRequest(ctx, urlStr, requestHeader); err != nil {
+			return conn, nil, err
+		}
+	}
+
+	cl := newCall(ctx, nil)
+	cl.canary = routeReq != nil && c.canary != nil && c.routeCanary(routeReq)
+	attempts, err := c.retry(cl, func() error {
+		discard(res)
+
+		target := urlStr
+		if routeReq != nil {
+			routed, err := c.route(cl, routeReq)
+			if err != nil {
+				return err
+			}
+			if cl.endpoint != nil {
+				defer atomic.AddInt64(&cl.endpoint.inflight, -1)
+			}
+			target = webSocketURL(routed.URL)
+		}
+
+		var err error
+		conn, res, err = d.DialContext(ctx, target, requestHeader)
+		err = classifyHandshake(ctx, res, err)
+		if c.recordRoute(cl, err) {
+			discard(res)
+			res = nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return conn, res, c.giveUp(cl, attempts, err)
+	}
+
+	return conn, res, nil
+}
+
+// webSocketRequest returns a GET request with provided header to http form of provided websocket url.
+func webSocketRequest(ctx context.Context, urlStr string, requestHeader http.Header) (*http.Request, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if requestHeader != nil {
+		req.Header = requestHeader.Clone()
+	}
+
+	return req, nil
+}
+
+// webSocketURL returns websocket form of provided http url.
+func webSocketURL(u *url.URL) string {
+	ws := *u
+	switch ws.Scheme {
+	case "http":
+		ws.Scheme = "ws"
+	case "https":
+		ws.Scheme = "wss"
+	}
+
+	return ws.String()
+}
+
+// classifyHandshake converts result of a handshake into nil, retryable or permanent error.
+func classifyHandshake(ctx context.Context, res *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return Permanent(ctx.Err())
+	}
+
+	if res != nil && res.StatusCode != http.StatusSwitchingProtocols {
+		statusCode := res.StatusCode
+		handshakeErr := &HandshakeError{StatusCode: statusCode}
+		if statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500 {
+			return handshakeErr
+		}
+
+		return Permanent(handshakeErr)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	return Permanent(err)
+}
+
+// NetWebSocketDialer is a minimal websocket dialer which performs opening handshake over a raw network connection and returns it.
+// It does not implement websocket framing, returned connection is meant to be used by a framing implementation.
+type NetWebSocketDialer struct {
+	Dialer          *net.Dialer
+	TLSClientConfig *tls.Config
+}
+
+// DialContext performs a single websocket opening handshake, deadline of provided context applies to the handshake only.
+// It returns ErrBadHandshake with server's response when server does not switch protocols, the response holds up to 64 KiB of its body.
+func (d *NetWebSocketDialer) DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (net.Conn, *http.Response, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var useTLS bool
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+		useTLS = true
+	default:
+		return nil, nil, fmt.Errorf("unsupported websocket url scheme %q", u.Scheme)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if useTLS {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+
+	if useTLS {
+		cfg := &tls.Config{}
+		if d.TLSClientConfig != nil {
+			cfg = d.TLSClientConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+
+	conn, res, err := handshake(conn, u, requestHeader)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if hasDeadline {
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+
+	return conn, res, nil
+}
+
+// handshake writes websocket opening handshake request to provided connection and validates server's response.
+// It closes the connection when handshake fails, buffering body of a rejecting response first.
+func handshake(conn net.Conn, u *url.URL, requestHeader http.Header) (net.Conn, *http.Response, error) {
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for k, v := range requestHeader {
+		req.Header[k] = v
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(res.Header.Get("Upgrade"), "websocket") ||
+		res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		conn.Close()
+		return nil, res, ErrBadHandshake
+	}
+
+	if br.Buffered() > 0 {
+		conn = &bufferedConn{Conn: conn, r: br}
+	}
+
+	return conn, res, nil
+}
+
+// acceptKey computes expected Sec-WebSocket-Accept header value for provided key.
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// bufferedConn is a connection which reads buffered bytes before reading from underlying connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads from buffered reader.
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,219 @@
+package retryablehttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newWebSocketServer creates a test server which rejects handshakes with provided status code until given request count is reached.
+func newWebSocketServer(t *testing.T, statusCode int, rejectCount int) (*httptest.Server, *int) {
+	reqCount := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCount++
+		if reqCount <= rejectCount {
+			w.WriteHeader(statusCode)
+
+			return
+		}
+
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("hijacking is not supported")
+
+			return
+		}
+
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			t.Errorf("hijacking connection failed, %s", err.Error())
+
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+		rw.WriteString("Upgrade: websocket\r\n")
+		rw.WriteString("Connection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+	}))
+
+	return s, &reqCount
+}
+
+// Dial function should retry handshakes rejected with status code service unavailable and return connection when handshake succeeds.
+func TestDialWithRetryableStatusCode(t *testing.T) {
+	maxReqCount := 3
+	s, reqCount := newWebSocketServer(t, http.StatusServiceUnavailable, maxReqCount-1)
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(maxReqCount),
+		WithBackoff(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+	conn, res, err := Dial[net.Conn](context.Background(), c, &NetWebSocketDialer{}, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dialing failed, %s", err.Error())
+	}
+	defer conn.Close()
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("unexpected status code, %d", res.StatusCode)
+	}
+	if *reqCount != maxReqCount {
+		t.Errorf("unexpected request count, %d", *reqCount)
+	}
+}
+
+// Dial function should not retry handshakes rejected with status code forbidden and return give up error.
+func TestDialWithPermanentStatusCode(t *testing.T) {
+	s, reqCount := newWebSocketServer(t, http.StatusForbidden, 3)
+	defer s.Close()
+
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+	_, res, err := Dial[net.Conn](context.Background(), c, &NetWebSocketDialer{}, wsURL, nil)
+
+	var giveUpErr *GiveUpError
+	if !errors.As(err, &giveUpErr) {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if giveUpErr.Attempts != 1 {
+		t.Errorf("unexpected attempt count, %d", giveUpErr.Attempts)
+	}
+
+	var handshakeErr *HandshakeError
+	if !errors.As(err, &handshakeErr) || handshakeErr.StatusCode != http.StatusForbidden {
+		t.Errorf("unexpected error, %s", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("unexpected status code, %d", res.StatusCode)
+	}
+	if *reqCount != 1 {
+		t.Errorf("unexpected request count, %d", *reqCount)
+	}
+}
+
+// fakeWebSocketDialer is a websocket dialer which rejects handshakes with recorded response bodies.
+type fakeWebSocketDialer struct {
+	bodies []*closeRecorder
+}
+
+// DialContext rejects the handshake with status code service unavailable.
+func (d *fakeWebSocketDialer) DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (net.Conn, *http.Response, error) {
+	body := &closeRecorder{ReadCloser: io.NopCloser(strings.NewReader("unavailable"))}
+	d.bodies = append(d.bodies, body)
+
+	return nil, &http.Response{StatusCode: http.StatusServiceUnavailable, Body: body}, ErrBadHandshake
+}
+
+// Dial function should close responses of rejected handshakes before retrying and return the last one unread.
+func TestDialClosesRejectedResponses(t *testing.T) {
+	c, err := NewClient(
+		WithMaxReqCount(3),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	d := &fakeWebSocketDialer{}
+	_, res, err := Dial[net.Conn](context.Background(), c, d, "ws://example.com", nil)
+	if !errors.Is(err, ErrBadHandshake) {
+		t.Errorf("unexpected error, %v", err)
+	}
+
+	if len(d.bodies) != 3 || !d.bodies[0].closed || !d.bodies[1].closed || d.bodies[2].closed {
+		t.Error("rejected responses are not closed before retrying")
+	}
+	if data, _ := io.ReadAll(res.Body); string(data) != "unavailable" {
+		t.Errorf("unexpected body, %q", data)
+	}
+}
+
+// DialContext method of NetWebSocketDialer should not keep deadline of dial context on returned connection.
+func TestNetWebSocketDialerDeadline(t *testing.T) {
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijacking connection failed, %s", err.Error())
+
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+		rw.WriteString("Upgrade: websocket\r\n")
+		rw.WriteString("Connection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+		<-done
+	}))
+	defer s.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	d := &NetWebSocketDialer{}
+	conn, _, err := d.DialContext(ctx, "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dialing failed, %s", err.Error())
+	}
+	defer conn.Close()
+
+	<-ctx.Done()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := conn.Write([]byte("frame")); err != nil {
+		t.Errorf("writing after dial context deadline failed, %s", err.Error())
+	}
+}
+
+// recordingWebSocketDialer is a websocket dialer which records dialed urls and accepts every handshake.
+type recordingWebSocketDialer struct {
+	urls []string
+}
+
+// DialContext records provided url and returns a switching protocols response.
+func (d *recordingWebSocketDialer) DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (net.Conn, *http.Response, error) {
+	d.urls = append(d.urls, urlStr)
+
+	return nil, &http.Response{StatusCode: http.StatusSwitchingProtocols, Body: http.NoBody}, nil
+}
+
+// Dial function should route handshakes to balanced endpoints of client, keeping websocket schemes.
+func TestDialEndpoints(t *testing.T) {
+	c, err := NewClient(
+		WithEndpoints("chat.internal", "https://10.0.0.1:8443/v1"),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	d := &recordingWebSocketDialer{}
+	if _, _, err := Dial[net.Conn](context.Background(), c, d, "ws://chat.internal/socket?room=1", nil); err != nil {
+		t.Fatalf("dialing failed, %s", err.Error())
+	}
+
+	if len(d.urls) != 1 || d.urls[0] != "wss://10.0.0.1:8443/v1/socket?room=1" {
+		t.Errorf("unexpected dialed urls, %v", d.urls)
+	}
+}
@@ -0,0 +1,36 @@
+// Package zstd provides zstd content coding for retryablehttp's request compression and response decompression.
+package zstd
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// content encodings
+const (
+	ContentEncoding = "zstd"
+)
+
+// Compressor compresses request bodies and decompresses response bodies with zstd.
+type Compressor struct{}
+
+// ContentEncoding returns zstd.
+func (Compressor) ContentEncoding() string {
+	return ContentEncoding
+}
+
+// NewWriter returns a zstd writer compressing into provided writer with default compression level.
+func (Compressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+// NewReader returns a zstd reader decompressing provided reader.
+func (Compressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.IOReadCloser(), nil
+}
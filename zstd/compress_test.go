@@ -0,0 +1,86 @@
+package zstd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ermanimer/retryablehttp"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Do method of a client with zstd request compression should send zstd compressed bodies.
+func TestRequestCompression(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != ContentEncoding {
+			t.Errorf("unexpected content encoding, %s", r.Header.Get("Content-Encoding"))
+		}
+
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("reading zstd body failed, %s", err.Error())
+			return
+		}
+		defer zr.Close()
+
+		body, err := io.ReadAll(zr)
+		if err != nil || string(body) != "body" {
+			t.Errorf("unexpected body, %q, %v", body, err)
+		}
+	}))
+	defer s.Close()
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithRequestCompression(Compressor{}, 0),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Post(s.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+		return
+	}
+	res.Body.Close()
+}
+
+// Do method of a client with zstd response decompression should advertise zstd encoding and decompress responses.
+func TestResponseDecompression(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != ContentEncoding {
+			t.Errorf("unexpected accept encoding, %s", r.Header.Get("Accept-Encoding"))
+		}
+
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			t.Errorf("creating zstd writer failed, %s", err.Error())
+			return
+		}
+		w.Header().Set("Content-Encoding", ContentEncoding)
+		zw.Write([]byte("body"))
+		zw.Close()
+	}))
+	defer s.Close()
+
+	c, err := retryablehttp.NewClient(
+		retryablehttp.WithResponseDecompression(Compressor{}),
+	)
+	if err != nil {
+		t.Errorf("creating client failed, %s", err.Error())
+	}
+
+	res, err := c.Get(s.URL)
+	if err != nil {
+		t.Errorf("doing request failed, %s", err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil || string(body) != "body" {
+		t.Errorf("unexpected body, %q, %v", body, err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/ermanimer/retryablehttp/zstd
+
+go 1.20
+
+require (
+	github.com/ermanimer/retryablehttp v0.0.0
+	github.com/klauspost/compress v1.17.4
+)
+
+replace github.com/ermanimer/retryablehttp => ../